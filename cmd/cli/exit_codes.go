@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/urfave/cli"
+)
+
+// The exit codes of the CLI, documented in the README. Pipelines depend
+// on them, so existing codes must never change their meaning.
+const (
+	exitCodeSuccess            = 0
+	exitCodeFailure            = 1
+	exitCodeUsage              = 2
+	exitCodeNotFound           = 3
+	exitCodeConflict           = 4
+	exitCodeTimeout            = 5
+	exitCodeUnauthorized       = 6
+	exitCodeClusterUnreachable = 7
+	exitCodeCanceled           = 130
+)
+
+// exitCode returns the exit code matching the category of the error
+func exitCode(err error) int {
+	if err == nil {
+		return exitCodeSuccess
+	}
+
+	switch flink.Category(err) {
+	case flink.ErrNotFound:
+		return exitCodeNotFound
+	case flink.ErrConflict:
+		return exitCodeConflict
+	case flink.ErrTimeout:
+		return exitCodeTimeout
+	case flink.ErrUnauthorized:
+		return exitCodeUnauthorized
+	case flink.ErrClusterUnreachable:
+		return exitCodeClusterUnreachable
+	case flink.ErrCanceled:
+		return exitCodeCanceled
+	}
+	return exitCodeFailure
+}
+
+// exitError prefixes the error with the message and
+// exits with the exit code matching its category
+func exitError(message string, err error) error {
+	return cli.NewExitError(fmt.Sprintf("%v: %v", message, err), exitCode(err))
+}
@@ -1,16 +1,28 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/hashicorp/go-retryablehttp"
-	"github.com/ing-bank/flink-deployer/cmd/cli/flink"
-	"github.com/ing-bank/flink-deployer/cmd/cli/operations"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/ing-bank/flink-deployer/pkg/artifact"
+	"github.com/ing-bank/flink-deployer/pkg/deployer"
+	"github.com/ing-bank/flink-deployer/pkg/discovery"
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/ing-bank/flink-deployer/pkg/flinktest"
+	"github.com/ing-bank/flink-deployer/pkg/operations"
+	"github.com/ing-bank/flink-deployer/pkg/secret"
 	"github.com/spf13/afero"
 	"github.com/urfave/cli"
 )
@@ -18,11 +30,17 @@ import (
 var filesystem afero.Fs
 var operator operations.Operator
 
+// redactor redacts the resolved secrets from the log and the output
+var redactor = &secret.Redactor{}
+
+// ctx is canceled when the deployer receives SIGINT or SIGTERM
+var ctx = context.Background()
+
 // ListAction executes the CLI list command
 func ListAction(c *cli.Context) error {
-	jobs, err := operator.RetrieveJobs()
+	jobs, err := operator.RetrieveJobs(ctx)
 	if err != nil {
-		return cli.NewExitError(fmt.Sprintf("failed to list jobs: %v", err), -1)
+		return exitError("failed to list jobs", err)
 	}
 
 	if len(jobs) == 0 {
@@ -36,43 +54,122 @@ func ListAction(c *cli.Context) error {
 	return nil
 }
 
+// validateJarSource validates that exactly one of
+// the flags specifying the job JAR file is used
+func validateJarSource(c *cli.Context) error {
+	sources := 0
+	for _, flag := range []string{"file-name", "remote-file-name", "artifact"} {
+		if len(c.String(flag)) > 0 {
+			sources++
+		}
+	}
+	if sources == 0 {
+		return cli.NewExitError("one of the flags 'file-name', 'remote-file-name' or 'artifact' must be specified", exitCodeUsage)
+	}
+	if sources > 1 {
+		return cli.NewExitError("only one of the flags 'file-name', 'remote-file-name' or 'artifact' is allowed", exitCodeUsage)
+	}
+	err := validateSHA256(c)
+	if err != nil {
+		return err
+	}
+	return validateAPITokenPreset(c)
+}
+
+var sha256Pattern = regexp.MustCompile(`^(sha256:)?[0-9a-fA-F]{64}$`)
+
+// validateSHA256 validates the value of the 'sha256' flag
+func validateSHA256(c *cli.Context) error {
+	if len(c.String("sha256")) > 0 && !sha256Pattern.MatchString(c.String("sha256")) {
+		return cli.NewExitError("the value for 'sha256' must be a SHA-256 checksum of 64 hexadecimal characters", exitCodeUsage)
+	}
+	return nil
+}
+
+// validateAPITokenPreset validates the value of the 'api-token-preset' flag
+func validateAPITokenPreset(c *cli.Context) error {
+	_, err := artifact.Preset(c.String("api-token-preset"), "")
+	if err != nil {
+		return cli.NewExitError(strings.Replace(err.Error(), "authentication preset", "value for 'api-token-preset'", 1), exitCodeUsage)
+	}
+	return nil
+}
+
+var jobIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+
+// getRunJarOptions returns the options of the run request set by
+// the 'job-id', 'restore-mode' and 'flink-config' flags
+func getRunJarOptions(c *cli.Context) (flink.RunJarOptions, error) {
+	options := flink.RunJarOptions{
+		JobID:       strings.ToLower(c.String("job-id")),
+		RestoreMode: strings.ToUpper(c.String("restore-mode")),
+	}
+	if len(options.JobID) > 0 && !jobIDPattern.MatchString(options.JobID) {
+		return flink.RunJarOptions{}, cli.NewExitError("the value for 'job-id' must be a job ID of 32 hexadecimal characters", exitCodeUsage)
+	}
+	if options.Validate() != nil {
+		return flink.RunJarOptions{}, cli.NewExitError("unknown value for 'restore-mode', only 'CLAIM', 'NO_CLAIM' and 'LEGACY' are supported", exitCodeUsage)
+	}
+
+	for _, entry := range c.StringSlice("flink-config") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || len(strings.TrimSpace(parts[0])) == 0 {
+			return flink.RunJarOptions{}, cli.NewExitError(fmt.Sprintf("the value %q for 'flink-config' must be a key=value pair", entry), exitCodeUsage)
+		}
+		if options.FlinkConfiguration == nil {
+			options.FlinkConfiguration = map[string]string{}
+		}
+		options.FlinkConfiguration[strings.TrimSpace(parts[0])] = parts[1]
+	}
+	return options, nil
+}
+
 // DeployAction executes the CLI deploy command
 func DeployAction(c *cli.Context) error {
 	deploy := operations.Deploy{}
 
+	err := validateJarSource(c)
+	if err != nil {
+		return err
+	}
+
 	filename := c.String("file-name")
 	remoteFilename := c.String("remote-file-name")
-	if len(filename) == 0 && len(remoteFilename) == 0 {
-		return cli.NewExitError("both flags 'file-name' and 'remote-file-name' unspecified", -1)
-	}
-	if len(filename) > 0 && len(remoteFilename) > 0 {
-		return cli.NewExitError("both flags 'file-name' and 'remote-file-name' specified, only one allowed", -1)
-	}
 
 	if len(filename) > 0 {
 		deploy.LocalFilename = filename
-	} else {
+	} else if len(remoteFilename) > 0 {
 		deploy.RemoteFilename = remoteFilename
 
 		apiToken := c.String("api-token")
 		if len(apiToken) > 0 {
 			deploy.APIToken = apiToken
 		}
+		deploy.APITokenPreset = c.String("api-token-preset")
 	}
+	deploy.Artifact = c.String("artifact")
+	deploy.SHA256 = c.String("sha256")
+	deploy.Signature = c.String("signature")
 
 	entryClass := c.String("entry-class")
 	if len(entryClass) > 0 {
 		deploy.EntryClass = entryClass
 	}
 
-	parallelism := c.Int("parallelism")
-	if parallelism != 0 {
-		deploy.Parallelism = parallelism
-	} else {
-		deploy.Parallelism = 1
+	parallelism, autoParallelism, err := parseParallelism(c.String("parallelism"))
+	if err != nil {
+		return cli.NewExitError(err.Error(), exitCodeUsage)
 	}
+	deploy.Parallelism = parallelism
+	deploy.AutoParallelism = autoParallelism
+	deploy.ParallelismLimit = c.Int("parallelism-limit")
+	deploy.CheckCapacity = c.Bool("check-capacity")
+	deploy.CapacityTimeout = c.Int("capacity-timeout")
 
-	programArgs := c.StringSlice("program-args")
+	programArgs, err := getProgramArgs(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), exitCodeUsage)
+	}
 	if len(programArgs) > 0 {
 		deploy.ProgramArgs = programArgs
 	}
@@ -80,7 +177,7 @@ func DeployAction(c *cli.Context) error {
 	savepointDir := c.String("savepoint-dir")
 	savepointPath := c.String("savepoint-path")
 	if len(savepointDir) > 0 && len(savepointPath) > 0 {
-		return cli.NewExitError("both flags 'savepoint-dir' and 'savepoint-path' specified, only one allowed", -1)
+		return cli.NewExitError("both flags 'savepoint-dir' and 'savepoint-path' specified, only one allowed", exitCodeUsage)
 	}
 	if len(savepointDir) > 0 {
 		deploy.SavepointDir = savepointDir
@@ -91,12 +188,20 @@ func DeployAction(c *cli.Context) error {
 
 	deploy.AllowNonRestoredState = c.Bool("allow-non-restored-state")
 
-	err := operator.Deploy(deploy)
+	options, err := getRunJarOptions(c)
+	if err != nil {
+		return err
+	}
+	deploy.JobID = options.JobID
+	deploy.RestoreMode = options.RestoreMode
+	deploy.FlinkConfiguration = options.FlinkConfiguration
+
+	result, err := operator.Deploy(ctx, deploy)
 	if err != nil {
-		return cli.NewExitError(fmt.Sprintf("an error occurred: %v", err), -1)
+		return exitError("an error occurred", err)
 	}
 
-	log.Println("Job started successfully")
+	log.Printf("Job %v started successfully", result.JobID)
 
 	return nil
 }
@@ -109,41 +214,50 @@ func UpdateAction(c *cli.Context) error {
 	if len(jobNameBase) != 0 {
 		update.JobNameBase = jobNameBase
 	} else {
-		return cli.NewExitError("unspecified flag 'job-name-base'", -1)
+		return cli.NewExitError("unspecified flag 'job-name-base'", exitCodeUsage)
+	}
+
+	err := validateJarSource(c)
+	if err != nil {
+		return err
 	}
 
 	filename := c.String("file-name")
 	remoteFilename := c.String("remote-file-name")
-	if len(filename) == 0 && len(remoteFilename) == 0 {
-		return cli.NewExitError("both flags 'file-name' and 'remote-file-name' unspecified", -1)
-	}
-	if len(filename) > 0 && len(remoteFilename) > 0 {
-		return cli.NewExitError("both flags 'file-name' and 'remote-file-name' specified, only one allowed", -1)
-	}
 	if len(filename) > 0 {
 		update.LocalFilename = filename
-	} else {
+	} else if len(remoteFilename) > 0 {
 		update.RemoteFilename = remoteFilename
 
 		apiToken := c.String("api-token")
 		if len(apiToken) > 0 {
 			update.APIToken = apiToken
 		}
+		update.APITokenPreset = c.String("api-token-preset")
 	}
+	update.Artifact = c.String("artifact")
+	update.SHA256 = c.String("sha256")
+	update.Signature = c.String("signature")
 
 	entryClass := c.String("entry-class")
 	if len(entryClass) > 0 {
 		update.EntryClass = entryClass
 	}
 
-	parallelism := c.Int("parallelism")
-	if parallelism != 0 {
-		update.Parallelism = parallelism
-	} else {
-		update.Parallelism = 1
+	parallelism, autoParallelism, err := parseParallelism(c.String("parallelism"))
+	if err != nil {
+		return cli.NewExitError(err.Error(), exitCodeUsage)
 	}
+	update.Parallelism = parallelism
+	update.AutoParallelism = autoParallelism
+	update.ParallelismLimit = c.Int("parallelism-limit")
+	update.CheckCapacity = c.Bool("check-capacity")
+	update.CapacityTimeout = c.Int("capacity-timeout")
 
-	programArgs := c.StringSlice("program-args")
+	programArgs, err := getProgramArgs(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), exitCodeUsage)
+	}
 	if len(programArgs) > 0 {
 		update.ProgramArgs = programArgs
 	}
@@ -152,20 +266,28 @@ func UpdateAction(c *cli.Context) error {
 	if len(savepointDir) != 0 {
 		update.SavepointDir = savepointDir
 	} else {
-		return cli.NewExitError("unspecified flag 'savepoint-dir'", -1)
+		return cli.NewExitError("unspecified flag 'savepoint-dir'", exitCodeUsage)
 	}
 
 	update.AllowNonRestoredState = c.Bool("allow-non-restored-state")
 
 	update.FallbackToDeploy = c.Bool("fallback-to-deploy")
 
-	err := operator.Update(update)
+	options, err := getRunJarOptions(c)
+	if err != nil {
+		return err
+	}
+	update.JobID = options.JobID
+	update.RestoreMode = options.RestoreMode
+	update.FlinkConfiguration = options.FlinkConfiguration
+
+	result, err := operator.Update(ctx, update)
 
 	if err != nil {
-		return cli.NewExitError(fmt.Sprintf("an error occurred: %v", err), -1)
+		return exitError("an error occurred", err)
 	}
 
-	log.Println("Job successfully updated")
+	log.Printf("Job successfully updated, running as job %v", result.JobID)
 
 	return nil
 }
@@ -176,19 +298,20 @@ func TerminateAction(c *cli.Context) error {
 
 	jobNameBase := c.String("job-name-base")
 	if len(jobNameBase) == 0 {
-		return cli.NewExitError("unspecified flag 'job-name-base'", -1)
+		return cli.NewExitError("unspecified flag 'job-name-base'", exitCodeUsage)
 	}
 	terminate.JobNameBase = jobNameBase
 
 	mode := c.String("mode")
 	if len(mode) > 0 && mode != "cancel" && mode != "stop" {
-		return cli.NewExitError("unknown value for 'mode', only 'cancel' and 'stop' are supported", -1)
+		return cli.NewExitError("unknown value for 'mode', only 'cancel' and 'stop' are supported", exitCodeUsage)
 	}
 	terminate.Mode = mode
+	terminate.SavepointDir = c.String("savepoint-dir")
 
-	err := operator.Terminate(terminate)
+	err := operator.Terminate(ctx, terminate)
 	if err != nil {
-		return cli.NewExitError(fmt.Sprintf("an error occurred: %v", err), -1)
+		return exitError("an error occurred", err)
 	}
 
 	log.Println("Job successfully terminated")
@@ -196,6 +319,341 @@ func TerminateAction(c *cli.Context) error {
 	return nil
 }
 
+// RescaleAction executes the CLI rescale command
+func RescaleAction(c *cli.Context) error {
+	rescale := operations.Rescale{}
+
+	jobNameBase := c.String("job-name-base")
+	if len(jobNameBase) == 0 {
+		return cli.NewExitError("unspecified flag 'job-name-base'", exitCodeUsage)
+	}
+	rescale.JobNameBase = jobNameBase
+
+	parallelism := c.Int("parallelism")
+	if parallelism <= 0 {
+		return cli.NewExitError("unspecified flag 'parallelism'", exitCodeUsage)
+	}
+	rescale.Parallelism = parallelism
+
+	rescale.JarID = c.String("jar-id")
+	rescale.EntryClass = c.String("entry-class")
+	rescale.SavepointDir = c.String("savepoint-dir")
+	rescale.AllowNonRestoredState = c.Bool("allow-non-restored-state")
+
+	programArgs, err := getProgramArgs(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), exitCodeUsage)
+	}
+	if len(programArgs) > 0 {
+		rescale.ProgramArgs = programArgs
+	}
+
+	err = operator.Rescale(ctx, rescale)
+	if err != nil {
+		return exitError("an error occurred", err)
+	}
+
+	log.Println("Job successfully rescaled")
+
+	return nil
+}
+
+// output returns the writer of the output of the commands,
+// which redacts the secrets when the app is configured by main
+func output(c *cli.Context) io.Writer {
+	if c.App == nil || c.App.Writer == nil {
+		return os.Stdout
+	}
+	return c.App.Writer
+}
+
+// ExceptionsAction executes the CLI exceptions command
+func ExceptionsAction(c *cli.Context) error {
+	exceptions := operations.Exceptions{
+		JobID:       c.String("job-id"),
+		JobNameBase: c.String("job-name-base"),
+	}
+
+	if len(exceptions.JobID) == 0 && len(exceptions.JobNameBase) == 0 {
+		return cli.NewExitError("both flags 'job-id' and 'job-name-base' unspecified", exitCodeUsage)
+	}
+	if len(exceptions.JobID) > 0 && len(exceptions.JobNameBase) > 0 {
+		return cli.NewExitError("both flags 'job-id' and 'job-name-base' specified, only one allowed", exitCodeUsage)
+	}
+
+	jobExceptions, err := operator.Exceptions(ctx, exceptions)
+	if err != nil {
+		return exitError("an error occurred", err)
+	}
+
+	if len(jobExceptions.RootException) == 0 {
+		log.Println("No exceptions found")
+		return nil
+	}
+
+	fmt.Fprintf(output(c), "Root exception:\n%v\n", jobExceptions.RootException)
+	for _, exception := range jobExceptions.AllExceptions {
+		fmt.Fprintf(output(c), "\nTask %v on %v:\n%v\n", exception.Task, exception.Location, exception.Exception)
+	}
+	if jobExceptions.Truncated {
+		fmt.Fprintln(output(c), "\nThe list of exceptions was truncated by Flink")
+	}
+
+	return nil
+}
+
+// LogsAction executes the CLI logs command
+func LogsAction(c *cli.Context) error {
+	logs := operations.Logs{
+		JobManager:      c.Bool("job-manager"),
+		TaskManagerID:   c.String("task-manager-id"),
+		AllTaskManagers: c.Bool("all-task-managers"),
+		TailLines:       c.Int("tail"),
+	}
+
+	if logs.TailLines < 0 {
+		return cli.NewExitError("the value for 'tail' must be a positive number", exitCodeUsage)
+	}
+
+	logFiles, err := operator.Logs(ctx, logs)
+	if err != nil {
+		return exitError("an error occurred", err)
+	}
+
+	for _, logFile := range logFiles {
+		fmt.Fprintf(output(c), "==> %v <==\n%v\n", logFile.Source, logFile.Content)
+	}
+
+	return nil
+}
+
+// DoctorAction executes the CLI doctor command
+func DoctorAction(c *cli.Context) error {
+	doctor := operations.Doctor{
+		JobNameBase:           c.String("job-name-base"),
+		SavepointDir:          c.String("savepoint-dir"),
+		SlowCheckpointSeconds: c.Int("slow-checkpoint-seconds"),
+	}
+
+	results := operator.Doctor(ctx, doctor)
+	for _, result := range results {
+		fmt.Fprintf(output(c), "[%v] %v: %v\n", result.Status, result.Name, result.Message)
+	}
+
+	if operations.Failed(results) {
+		return cli.NewExitError("one or more checks failed", exitCodeFailure)
+	}
+
+	return nil
+}
+
+// PlanAction executes the CLI plan command
+func PlanAction(c *cli.Context) error {
+	plan := operations.Plan{
+		JobID:          c.String("job-id"),
+		JobNameBase:    c.String("job-name-base"),
+		JarID:          c.String("jar-id"),
+		LocalFilename:  c.String("file-name"),
+		RemoteFilename: c.String("remote-file-name"),
+		APIToken:       c.String("api-token"),
+		APITokenPreset: c.String("api-token-preset"),
+		Artifact:       c.String("artifact"),
+		SHA256:         c.String("sha256"),
+		Signature:      c.String("signature"),
+		EntryClass:     c.String("entry-class"),
+		Parallelism:    c.Int("parallelism"),
+	}
+
+	sources := 0
+	for _, source := range []string{plan.JobID, plan.JobNameBase, plan.JarID, plan.LocalFilename, plan.RemoteFilename, plan.Artifact} {
+		if len(source) > 0 {
+			sources++
+		}
+	}
+	if sources == 0 {
+		return cli.NewExitError("one of the flags 'job-id', 'job-name-base', 'jar-id', 'file-name', 'remote-file-name' or 'artifact' must be specified", exitCodeUsage)
+	}
+	if sources > 1 {
+		return cli.NewExitError("only one of the flags 'job-id', 'job-name-base', 'jar-id', 'file-name', 'remote-file-name' or 'artifact' is allowed", exitCodeUsage)
+	}
+	err := validateSHA256(c)
+	if err != nil {
+		return err
+	}
+	programArgs, err := getProgramArgs(c)
+	if err != nil {
+		return cli.NewExitError(err.Error(), exitCodeUsage)
+	}
+	if len(programArgs) > 0 {
+		plan.ProgramArgs = programArgs
+	}
+
+	format := c.String("format")
+	if len(format) > 0 && format != operations.PlanFormatASCII && format != operations.PlanFormatDot && format != operations.PlanFormatMermaid {
+		return cli.NewExitError("unknown value for 'format', only 'ascii', 'dot' and 'mermaid' are supported", exitCodeUsage)
+	}
+
+	jobPlan, err := operator.Plan(ctx, plan)
+	if err != nil {
+		return exitError("an error occurred", err)
+	}
+
+	rendered, err := operations.RenderPlan(jobPlan, format)
+	if err != nil {
+		return exitError("an error occurred", err)
+	}
+
+	outputFile := c.String("output-file")
+	if len(outputFile) == 0 {
+		fmt.Fprint(output(c), rendered)
+		return nil
+	}
+
+	err = afero.WriteFile(filesystem, outputFile, []byte(rendered), 0644)
+	if err != nil {
+		return exitError(fmt.Sprintf("failed to write plan to '%v'", outputFile), err)
+	}
+
+	log.Printf("Plan written to %v", outputFile)
+
+	return nil
+}
+
+// FakeClusterAction executes the CLI fake-cluster command
+func FakeClusterAction(c *cli.Context) error {
+	cluster := flinktest.NewCluster()
+	cluster.Version = c.String("flink-version")
+	cluster.TaskManagers = c.Int("task-managers")
+	cluster.SlotsPerTaskManager = c.Int("slots")
+	cluster.SavepointDuration = time.Duration(c.Int("savepoint-seconds")) * time.Second
+	cluster.CheckpointInterval = time.Duration(c.Int("checkpoint-interval-seconds")) * time.Second
+	cluster.DefaultSavepointDir = c.String("savepoint-dir")
+	cluster.Filesystem = filesystem
+	cluster.Logger = log.New(os.Stderr, "", log.LstdFlags)
+
+	if cluster.TaskManagers <= 0 || cluster.SlotsPerTaskManager <= 0 {
+		return cli.NewExitError("the values for 'task-managers' and 'slots' must be positive numbers", exitCodeUsage)
+	}
+
+	server := &http.Server{Addr: c.String("address"), Handler: cluster}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	log.Printf("Fake Flink %v cluster listening on http://%v", cluster.Version, server.Addr)
+	err := server.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		return exitError("the fake cluster failed", err)
+	}
+
+	return nil
+}
+
+// InspectJarAction executes the CLI inspect-jar command
+func InspectJarAction(c *cli.Context) error {
+	filename := c.String("file-name")
+	if len(filename) == 0 {
+		return cli.NewExitError("unspecified flag 'file-name'", exitCodeUsage)
+	}
+
+	jar, err := artifact.InspectJar(filename)
+	if err != nil {
+		return exitError("failed to inspect the JAR file", err)
+	}
+
+	fmt.Fprintf(output(c), "Main-Class: %v\n", jar.Manifest.MainClass)
+	fmt.Fprintf(output(c), "Program-Class: %v\n", jar.Manifest.ProgramClass)
+	fmt.Fprintf(output(c), "Implementation-Title: %v\n", jar.Manifest.ImplementationTitle)
+	fmt.Fprintf(output(c), "Implementation-Version: %v\n", jar.Manifest.ImplementationVersion)
+
+	entryClass, err := jar.ValidateEntryClass(c.String("entry-class"))
+	if err != nil {
+		return exitError("the JAR file can't be run", err)
+	}
+	fmt.Fprintf(output(c), "Entry class: %v\n", entryClass)
+
+	return nil
+}
+
+// CacheListAction executes the CLI cache list command
+func CacheListAction(c *cli.Context) error {
+	cache, err := getCache()
+	if err != nil {
+		return cli.NewExitError(err.Error(), exitCodeUsage)
+	}
+	if cache == nil {
+		return cli.NewExitError("`FLINK_ARTIFACT_CACHE_DIR` environment variable not found", exitCodeUsage)
+	}
+
+	entries, err := cache.List()
+	if err != nil {
+		return exitError("failed to list the artifact cache", err)
+	}
+
+	if len(entries) == 0 {
+		log.Println("The artifact cache is empty")
+		return nil
+	}
+	for _, entry := range entries {
+		fmt.Fprintf(output(c), "%v %10v %v %v\n", entry.Digest[:19], entry.Size, entry.LastUsed.Format(time.RFC3339), entry.Reference)
+	}
+
+	return nil
+}
+
+// CachePruneAction executes the CLI cache prune command
+func CachePruneAction(c *cli.Context) error {
+	cache, err := getCache()
+	if err != nil {
+		return cli.NewExitError(err.Error(), exitCodeUsage)
+	}
+	if cache == nil {
+		return cli.NewExitError("`FLINK_ARTIFACT_CACHE_DIR` environment variable not found", exitCodeUsage)
+	}
+
+	var maxSize int64
+	if len(c.String("max-size")) > 0 {
+		maxSize, err = artifact.ParseSize(c.String("max-size"))
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("the value for 'max-size' is invalid: %v", err), exitCodeUsage)
+		}
+	}
+
+	evicted, err := cache.Prune(maxSize)
+	if err != nil {
+		return exitError("failed to prune the artifact cache", err)
+	}
+
+	for _, entry := range evicted {
+		log.Printf("Evicted %v", entry.Reference)
+	}
+	log.Printf("Evicted %v artifacts from the cache", len(evicted))
+
+	return nil
+}
+
+// parseParallelism parses the parallelism flag which is either
+// a number or 'auto' to use the available task slots
+func parseParallelism(value string) (int, bool, error) {
+	if value == "auto" {
+		return 0, true, nil
+	}
+	if len(value) == 0 {
+		return 1, false, nil
+	}
+
+	parallelism, err := strconv.Atoi(value)
+	if err != nil || parallelism < 0 {
+		return 0, false, errors.New("unknown value for 'parallelism', only positive numbers and 'auto' are supported")
+	}
+	if parallelism == 0 {
+		return 1, false, nil
+	}
+
+	return parallelism, false, nil
+}
+
 func getAPITimeoutSeconds() (int64, error) {
 	if len(os.Getenv("FLINK_API_TIMEOUT_SECONDS")) > 0 {
 		return strconv.ParseInt(os.Getenv("FLINK_API_TIMEOUT_SECONDS"), 10, 64)
@@ -203,56 +661,596 @@ func getAPITimeoutSeconds() (int64, error) {
 	return int64(10), nil
 }
 
-func main() {
-	flinkBaseURL := os.Getenv("FLINK_BASE_URL")
-	if len(flinkBaseURL) == 0 {
-		log.Fatal("`FLINK_BASE_URL` environment variable not found")
-		os.Exit(1)
+func getTLSOptions() (flink.TLSOptions, error) {
+	options := flink.TLSOptions{
+		CACertFile:     os.Getenv("FLINK_TLS_CA_CERT"),
+		ClientCertFile: os.Getenv("FLINK_TLS_CLIENT_CERT"),
+		ClientKeyFile:  os.Getenv("FLINK_TLS_CLIENT_KEY"),
+		ServerName:     os.Getenv("FLINK_TLS_SERVER_NAME"),
 	}
 
-	flinkBasicAuthUsername := os.Getenv("FLINK_BASIC_AUTH_USERNAME")
-	flinkBasicAuthPassword := os.Getenv("FLINK_BASIC_AUTH_PASSWORD")
+	if len(os.Getenv("FLINK_TLS_INSECURE_SKIP_VERIFY")) > 0 {
+		insecureSkipVerify, err := strconv.ParseBool(os.Getenv("FLINK_TLS_INSECURE_SKIP_VERIFY"))
+		if err != nil {
+			return flink.TLSOptions{}, err
+		}
+		options.InsecureSkipVerify = insecureSkipVerify
+	}
 
-	flinkAPITimeoutSeconds, err := getAPITimeoutSeconds()
+	return options, nil
+}
+
+// parseHeaders parses a comma separated list of 'Name=value' headers
+func parseHeaders(value string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, header := range strings.Split(value, ",") {
+		if len(strings.TrimSpace(header)) == 0 {
+			continue
+		}
+
+		parts := strings.SplitN(header, "=", 2)
+		if len(parts) != 2 || len(strings.TrimSpace(parts[0])) == 0 {
+			return nil, fmt.Errorf("invalid header \"%v\", expected 'Name=value'", header)
+		}
+		headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return headers, nil
+}
+
+// getAuthenticator creates the authenticator configured through the environment
+// variables, or nil when only basic authentication or none is used
+func getAuthenticator(client *http.Client) (flink.Authenticator, error) {
+	authenticators := flink.Authenticators{}
+
+	bearerToken := os.Getenv("FLINK_BEARER_TOKEN")
+	tokenURL := os.Getenv("FLINK_OAUTH2_TOKEN_URL")
+	if len(bearerToken) > 0 && len(tokenURL) > 0 {
+		return nil, errors.New("both `FLINK_BEARER_TOKEN` and `FLINK_OAUTH2_TOKEN_URL` are set, only one allowed")
+	}
+	if len(bearerToken) > 0 {
+		authenticators = append(authenticators, flink.BearerTokenAuthenticator{Token: bearerToken})
+	}
+	if len(tokenURL) > 0 {
+		scopes := []string{}
+		for _, scope := range strings.Split(os.Getenv("FLINK_OAUTH2_SCOPES"), ",") {
+			if len(strings.TrimSpace(scope)) > 0 {
+				scopes = append(scopes, strings.TrimSpace(scope))
+			}
+		}
+		authenticators = append(authenticators, flink.NewClientCredentialsAuthenticator(
+			tokenURL,
+			os.Getenv("FLINK_OAUTH2_CLIENT_ID"),
+			os.Getenv("FLINK_OAUTH2_CLIENT_SECRET"),
+			scopes,
+			client,
+		))
+	}
+
+	headers, err := parseHeaders(os.Getenv("FLINK_EXTRA_HEADERS"))
 	if err != nil {
-		log.Fatalf("`FLINK_API_TIMEOUT_SECONDS=%v` environment variable could not be parsed to an integer", os.Getenv("FLINK_API_TIMEOUT_SECONDS"))
-		os.Exit(1)
+		return nil, err
+	}
+	if len(headers) > 0 {
+		authenticators = append(authenticators, flink.HeaderAuthenticator{Headers: headers})
 	}
 
-	client := retryablehttp.NewClient()
-	client.HTTPClient = &http.Client{
-		Timeout: time.Second * time.Duration(flinkAPITimeoutSeconds),
+	if len(authenticators) == 0 {
+		return nil, nil
 	}
+	return authenticators, nil
+}
 
-	operator = operations.RealOperator{
-		Filesystem: afero.NewOsFs(),
-		FlinkRestAPI: flink.FlinkRestClient{
-			BaseURL:           flinkBaseURL,
-			BasicAuthUsername: flinkBasicAuthUsername,
-			BasicAuthPassword: flinkBasicAuthPassword,
-			Client:            client,
-		},
+// getDiscoverer creates the discoverer of the Flink REST endpoint
+// configured through the environment variables, or nil when unset
+func getDiscoverer(client *http.Client) (discovery.Discoverer, error) {
+	switch os.Getenv("FLINK_DISCOVERY") {
+	case "":
+		return nil, nil
+	case "yarn":
+		return discovery.YARN{
+			ResourceManagerURL: os.Getenv("YARN_RESOURCE_MANAGER_URL"),
+			ApplicationID:      os.Getenv("FLINK_YARN_APPLICATION_ID"),
+			ApplicationName:    os.Getenv("FLINK_YARN_APPLICATION_NAME"),
+			Client:             client,
+		}, nil
+	case "kubernetes":
+		inCluster := false
+		if len(os.Getenv("FLINK_KUBERNETES_IN_CLUSTER")) > 0 {
+			var err error
+			inCluster, err = strconv.ParseBool(os.Getenv("FLINK_KUBERNETES_IN_CLUSTER"))
+			if err != nil {
+				return nil, fmt.Errorf("`FLINK_KUBERNETES_IN_CLUSTER=%v` environment variable could not be parsed to a boolean", os.Getenv("FLINK_KUBERNETES_IN_CLUSTER"))
+			}
+		}
+
+		return discovery.Kubernetes{
+			Kubeconfig: os.Getenv("FLINK_KUBERNETES_KUBECONFIG"),
+			Context:    os.Getenv("FLINK_KUBERNETES_CONTEXT"),
+			InCluster:  inCluster,
+			Namespace:  os.Getenv("FLINK_KUBERNETES_NAMESPACE"),
+			Service:    os.Getenv("FLINK_KUBERNETES_SERVICE"),
+			Port:       os.Getenv("FLINK_KUBERNETES_PORT"),
+		}, nil
 	}
+	return nil, errors.New("unknown value for `FLINK_DISCOVERY`, only 'yarn' and 'kubernetes' are supported")
+}
 
-	app := cli.NewApp()
-	app.Name = "Flink Deployer"
-	app.Description = "A Go command-line utility to facilitate deployments to Apache Flink"
-	app.Version = "1.4.0"
+// getPolicies returns the default policies for the request timeout, overridden
+// by the policy configuration file when `FLINK_POLICY_FILE` is set
+func getPolicies(requestTimeout time.Duration) (flink.Policies, *flink.CircuitBreaker, error) {
+	policies := flink.DefaultPolicies(requestTimeout)
 
-	app.Commands = []cli.Command{
-		{
-			Name:    "list",
-			Aliases: []string{"l"},
-			Usage:   "list the jobs running on the job manager",
-			Action:  ListAction,
-		},
-		{
-			Name:    "deploy",
-			Aliases: []string{"d"},
-			Usage:   "deploy the JAR to the job manager",
-			Flags: []cli.Flag{
-				cli.StringFlag{
-					Name:  "file-name, fn",
+	path := os.Getenv("FLINK_POLICY_FILE")
+	if len(path) == 0 {
+		return policies, nil, nil
+	}
+
+	content, err := afero.ReadFile(filesystem, path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read the policy configuration: %v", err)
+	}
+	return flink.ParsePolicyConfig(content, policies)
+}
+
+// getMavenRepositories returns the Maven repositories listed in
+// `FLINK_MAVEN_REPOSITORIES`, or Maven Central when it isn't set. The
+// credentials in the URL of a repository take precedence over the
+// credentials in `FLINK_MAVEN_USERNAME` and `FLINK_MAVEN_PASSWORD`.
+func getMavenRepositories() ([]artifact.MavenRepository, error) {
+	urls := os.Getenv("FLINK_MAVEN_REPOSITORIES")
+	if len(urls) == 0 {
+		urls = artifact.MavenCentral
+	}
+
+	repositories := []artifact.MavenRepository{}
+	for _, rawURL := range strings.Split(urls, ",") {
+		repositoryURL, err := url.Parse(strings.TrimSpace(rawURL))
+		if err != nil || len(repositoryURL.Host) == 0 {
+			return nil, fmt.Errorf("`FLINK_MAVEN_REPOSITORIES` contains an invalid URL \"%v\"", rawURL)
+		}
+
+		repository := artifact.MavenRepository{
+			Username: os.Getenv("FLINK_MAVEN_USERNAME"),
+			Password: os.Getenv("FLINK_MAVEN_PASSWORD"),
+		}
+		if repositoryURL.User != nil {
+			repository.Username = repositoryURL.User.Username()
+			repository.Password, _ = repositoryURL.User.Password()
+			repositoryURL.User = nil
+		}
+		repository.URL = repositoryURL.String()
+		repositories = append(repositories, repository)
+	}
+	return repositories, nil
+}
+
+// getS3 returns the configuration of the S3-compatible object storage. The
+// static credentials in `FLINK_S3_ACCESS_KEY_ID` and `FLINK_S3_SECRET_ACCESS_KEY`
+// take precedence over the web identity and environment credentials of AWS.
+func getS3(client *http.Client) (artifact.S3, error) {
+	s3 := artifact.S3{
+		Endpoint: os.Getenv("FLINK_S3_ENDPOINT"),
+		Region:   os.Getenv("FLINK_S3_REGION"),
+		Client:   client,
+	}
+	if len(s3.Region) == 0 {
+		s3.Region = os.Getenv("AWS_REGION")
+	}
+
+	if len(os.Getenv("FLINK_S3_PATH_STYLE")) > 0 {
+		pathStyle, err := strconv.ParseBool(os.Getenv("FLINK_S3_PATH_STYLE"))
+		if err != nil {
+			return artifact.S3{}, fmt.Errorf("`FLINK_S3_PATH_STYLE=%v` environment variable could not be parsed to a boolean", os.Getenv("FLINK_S3_PATH_STYLE"))
+		}
+		s3.PathStyle = pathStyle
+	}
+
+	accessKeyID := os.Getenv("FLINK_S3_ACCESS_KEY_ID")
+	secretAccessKey := os.Getenv("FLINK_S3_SECRET_ACCESS_KEY")
+	if len(accessKeyID) > 0 || len(secretAccessKey) > 0 {
+		if len(accessKeyID) == 0 || len(secretAccessKey) == 0 {
+			return artifact.S3{}, errors.New("both `FLINK_S3_ACCESS_KEY_ID` and `FLINK_S3_SECRET_ACCESS_KEY` are required for static S3 credentials")
+		}
+		s3.Credentials = artifact.S3Credentials{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey}
+		return s3, nil
+	}
+
+	if credentials := artifact.S3CredentialsFromEnvironment(client); credentials != nil {
+		s3.Credentials = credentials
+	}
+	return s3, nil
+}
+
+// getOCI returns the configuration of the OCI registries, authenticated
+// with the credentials of `FLINK_OCI_DOCKER_CONFIG` or the Docker config
+// file in `DOCKER_CONFIG` or ~/.docker
+func getOCI(client *http.Client) (artifact.OCI, error) {
+	oci := artifact.OCI{
+		DockerConfig: os.Getenv("FLINK_OCI_DOCKER_CONFIG"),
+		Client:       client,
+	}
+	if len(oci.DockerConfig) == 0 && len(os.Getenv("DOCKER_CONFIG")) > 0 {
+		oci.DockerConfig = filepath.Join(os.Getenv("DOCKER_CONFIG"), "config.json")
+	}
+	if len(oci.DockerConfig) == 0 && len(os.Getenv("HOME")) > 0 {
+		oci.DockerConfig = filepath.Join(os.Getenv("HOME"), ".docker", "config.json")
+	}
+
+	if len(os.Getenv("FLINK_OCI_PLAIN_HTTP")) > 0 {
+		plainHTTP, err := strconv.ParseBool(os.Getenv("FLINK_OCI_PLAIN_HTTP"))
+		if err != nil {
+			return artifact.OCI{}, fmt.Errorf("`FLINK_OCI_PLAIN_HTTP=%v` environment variable could not be parsed to a boolean", os.Getenv("FLINK_OCI_PLAIN_HTTP"))
+		}
+		oci.PlainHTTP = plainHTTP
+	}
+
+	for _, mediaType := range strings.Split(os.Getenv("FLINK_OCI_MEDIA_TYPES"), ",") {
+		if mediaType = strings.TrimSpace(mediaType); len(mediaType) > 0 {
+			oci.JarMediaTypes = append(oci.JarMediaTypes, mediaType)
+		}
+	}
+	return oci, nil
+}
+
+// getCache returns the artifact cache in `FLINK_ARTIFACT_CACHE_DIR`,
+// or nil when the downloaded artifacts aren't cached
+func getCache() (*artifact.Cache, error) {
+	cache := artifact.Cache{Dir: os.Getenv("FLINK_ARTIFACT_CACHE_DIR")}
+
+	if len(os.Getenv("FLINK_ARTIFACT_CACHE_MAX_SIZE")) > 0 {
+		maxSize, err := artifact.ParseSize(os.Getenv("FLINK_ARTIFACT_CACHE_MAX_SIZE"))
+		if err != nil {
+			return nil, fmt.Errorf("`FLINK_ARTIFACT_CACHE_MAX_SIZE` contains an %v", err)
+		}
+		cache.MaxSize = maxSize
+	}
+
+	if len(os.Getenv("FLINK_ARTIFACT_CACHE_MAX_AGE")) > 0 {
+		maxAge, err := time.ParseDuration(os.Getenv("FLINK_ARTIFACT_CACHE_MAX_AGE"))
+		if err != nil || maxAge < 0 {
+			return nil, fmt.Errorf("`FLINK_ARTIFACT_CACHE_MAX_AGE=%v` environment variable could not be parsed to a duration", os.Getenv("FLINK_ARTIFACT_CACHE_MAX_AGE"))
+		}
+		cache.MaxAge = maxAge
+	}
+
+	if len(os.Getenv("FLINK_ARTIFACT_OFFLINE")) > 0 {
+		offline, err := strconv.ParseBool(os.Getenv("FLINK_ARTIFACT_OFFLINE"))
+		if err != nil {
+			return nil, fmt.Errorf("`FLINK_ARTIFACT_OFFLINE=%v` environment variable could not be parsed to a boolean", os.Getenv("FLINK_ARTIFACT_OFFLINE"))
+		}
+		cache.Offline = offline
+	}
+
+	if len(cache.Dir) == 0 {
+		if cache.Offline {
+			return nil, errors.New("`FLINK_ARTIFACT_OFFLINE` requires the artifact cache in `FLINK_ARTIFACT_CACHE_DIR`")
+		}
+		return nil, nil
+	}
+	return &cache, nil
+}
+
+// getVerification returns the verification policy of the context in
+// `FLINK_CONTEXT`, configured by the YAML file in `FLINK_VERIFICATION_FILE`
+func getVerification() (artifact.VerificationPolicy, error) {
+	path := os.Getenv("FLINK_VERIFICATION_FILE")
+	if len(path) == 0 {
+		return artifact.VerificationPolicy{}, nil
+	}
+
+	content, err := afero.ReadFile(filesystem, path)
+	if err != nil {
+		return artifact.VerificationPolicy{}, fmt.Errorf("unable to read the verification configuration: %v", err)
+	}
+	config, err := artifact.ParseVerificationConfig(content)
+	if err != nil {
+		return artifact.VerificationPolicy{}, err
+	}
+
+	keyFiles, requireSignature := config.ForContext(os.Getenv("FLINK_CONTEXT"))
+	policy := artifact.VerificationPolicy{RequireSignature: requireSignature}
+	for _, keyFile := range keyFiles {
+		content, err := afero.ReadFile(filesystem, keyFile)
+		if err != nil {
+			return artifact.VerificationPolicy{}, fmt.Errorf("unable to read the trusted key: %v", err)
+		}
+		keys, err := artifact.ParseTrustedKeys(content)
+		if err != nil {
+			return artifact.VerificationPolicy{}, fmt.Errorf("unable to parse the trusted key %v: %v", keyFile, err)
+		}
+		policy.Keys = append(policy.Keys, keys...)
+	}
+	return policy, nil
+}
+
+// getSecrets returns the resolver of the secret references in program
+// arguments, which reads secrets from the Vault at `VAULT_ADDR` with
+// `VAULT_TOKEN`, or the token `vault login` stores in ~/.vault-token
+func getSecrets() secret.Resolver {
+	vault := secret.Vault{
+		Address:   os.Getenv("VAULT_ADDR"),
+		Token:     os.Getenv("VAULT_TOKEN"),
+		Namespace: os.Getenv("VAULT_NAMESPACE"),
+		Client:    cleanhttp.DefaultPooledClient(),
+	}
+	vault.Client.Timeout = secret.DefaultTimeout
+	if len(vault.Token) == 0 && len(os.Getenv("HOME")) > 0 {
+		content, err := afero.ReadFile(filesystem, filepath.Join(os.Getenv("HOME"), ".vault-token"))
+		if err == nil {
+			vault.Token = strings.TrimSpace(string(content))
+		}
+	}
+	redactor.Add(vault.Token)
+
+	return secret.Resolver{Vault: vault, Redactor: redactor}
+}
+
+// getHTTP returns the configuration of the downloads of remote JAR files
+// and artifact URLs, sent through the configured proxy. The servers are
+// verified with the system roots and, when FLINK_ARTIFACT_TRUST_CLUSTER_CA
+// is set, the CA bundle of the cluster.
+func getHTTP(tlsOptions flink.TLSOptions) (artifact.HTTP, error) {
+	source := artifact.HTTP{
+		Retries:   3,
+		RetryWait: time.Second,
+		Timeout:   artifact.DefaultTimeout,
+		Netrc:     os.Getenv("FLINK_ARTIFACT_NETRC"),
+	}
+	if len(source.Netrc) == 0 && len(os.Getenv("HOME")) > 0 {
+		path := filepath.Join(os.Getenv("HOME"), ".netrc")
+		if _, err := os.Stat(path); err == nil {
+			source.Netrc = path
+		}
+	}
+
+	if len(os.Getenv("FLINK_ARTIFACT_RETRIES")) > 0 {
+		retries, err := strconv.Atoi(os.Getenv("FLINK_ARTIFACT_RETRIES"))
+		if err != nil || retries < 0 {
+			return artifact.HTTP{}, fmt.Errorf("`FLINK_ARTIFACT_RETRIES=%v` environment variable could not be parsed to a positive integer", os.Getenv("FLINK_ARTIFACT_RETRIES"))
+		}
+		source.Retries = retries
+	}
+	if len(os.Getenv("FLINK_ARTIFACT_TIMEOUT")) > 0 {
+		timeout, err := time.ParseDuration(os.Getenv("FLINK_ARTIFACT_TIMEOUT"))
+		if err != nil || timeout < 0 {
+			return artifact.HTTP{}, fmt.Errorf("`FLINK_ARTIFACT_TIMEOUT=%v` environment variable could not be parsed to a duration", os.Getenv("FLINK_ARTIFACT_TIMEOUT"))
+		}
+		source.Timeout = timeout
+	}
+
+	authenticators := flink.Authenticators{}
+	username := os.Getenv("FLINK_ARTIFACT_USERNAME")
+	password := os.Getenv("FLINK_ARTIFACT_PASSWORD")
+	if len(username) > 0 || len(password) > 0 {
+		authenticators = append(authenticators, flink.BasicAuthenticator{Username: username, Password: password})
+	}
+	if len(os.Getenv("FLINK_ARTIFACT_BEARER_TOKEN")) > 0 {
+		authenticators = append(authenticators, flink.BearerTokenAuthenticator{Token: os.Getenv("FLINK_ARTIFACT_BEARER_TOKEN")})
+	}
+	headers, err := parseHeaders(os.Getenv("FLINK_ARTIFACT_HEADERS"))
+	if err != nil {
+		return artifact.HTTP{}, fmt.Errorf("`FLINK_ARTIFACT_HEADERS` contains an %v", err)
+	}
+	if len(headers) > 0 {
+		authenticators = append(authenticators, flink.HeaderAuthenticator{Headers: headers})
+	}
+	if len(authenticators) > 0 {
+		source.Authenticator = authenticators
+	}
+
+	caCertFile := ""
+	if len(os.Getenv("FLINK_ARTIFACT_TRUST_CLUSTER_CA")) > 0 {
+		trustClusterCA, err := strconv.ParseBool(os.Getenv("FLINK_ARTIFACT_TRUST_CLUSTER_CA"))
+		if err != nil {
+			return artifact.HTTP{}, fmt.Errorf("`FLINK_ARTIFACT_TRUST_CLUSTER_CA=%v` environment variable could not be parsed to a boolean", os.Getenv("FLINK_ARTIFACT_TRUST_CLUSTER_CA"))
+		}
+		if trustClusterCA {
+			caCertFile = tlsOptions.CACertFile
+		}
+	}
+	transport, err := artifact.NewTransport(caCertFile)
+	if err != nil {
+		return artifact.HTTP{}, err
+	}
+	if len(os.Getenv("FLINK_ARTIFACT_PROXY")) > 0 {
+		proxyURL, err := url.Parse(os.Getenv("FLINK_ARTIFACT_PROXY"))
+		if err != nil || len(proxyURL.Host) == 0 {
+			return artifact.HTTP{}, fmt.Errorf("`FLINK_ARTIFACT_PROXY=%v` environment variable is not a valid URL", os.Getenv("FLINK_ARTIFACT_PROXY"))
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	source.Client = &http.Client{Transport: transport}
+	return source, nil
+}
+
+// clusterlessCommands are the commands which don't connect to a Flink cluster
+var clusterlessCommands = map[string]bool{
+	"":             true,
+	"help":         true,
+	"h":            true,
+	"fake-cluster": true,
+	"cache":        true,
+	"inspect-jar":  true,
+}
+
+// parseBaseURLs parses the comma separated list of base URLs of the
+// JobManagers, ignoring the whitespace around them
+func parseBaseURLs(value string) ([]string, error) {
+	baseURLs := []string{}
+	for _, baseURL := range strings.Split(value, ",") {
+		baseURL = strings.TrimSpace(baseURL)
+		if len(baseURL) == 0 {
+			return nil, errors.New("`FLINK_BASE_URL` environment variable contains an empty URL")
+		}
+		baseURLs = append(baseURLs, baseURL)
+	}
+	return baseURLs, nil
+}
+
+// configureOperator configures the operator connecting to the
+// Flink cluster from the environment variables
+func configureOperator() {
+	flinkBaseURL := os.Getenv("FLINK_BASE_URL")
+	if len(flinkBaseURL) == 0 && len(os.Getenv("FLINK_DISCOVERY")) == 0 {
+		log.Fatal("`FLINK_BASE_URL` environment variable not found")
+		os.Exit(1)
+	}
+	if len(flinkBaseURL) > 0 && len(os.Getenv("FLINK_DISCOVERY")) > 0 {
+		log.Fatal("both `FLINK_BASE_URL` and `FLINK_DISCOVERY` environment variables are set, only one allowed")
+		os.Exit(1)
+	}
+
+	flinkBasicAuthUsername := os.Getenv("FLINK_BASIC_AUTH_USERNAME")
+	flinkBasicAuthPassword := os.Getenv("FLINK_BASIC_AUTH_PASSWORD")
+
+	flinkAPITimeoutSeconds, err := getAPITimeoutSeconds()
+	if err != nil {
+		log.Fatalf("`FLINK_API_TIMEOUT_SECONDS=%v` environment variable could not be parsed to an integer", os.Getenv("FLINK_API_TIMEOUT_SECONDS"))
+		os.Exit(1)
+	}
+
+	tlsOptions, err := getTLSOptions()
+	if err != nil {
+		log.Fatalf("`FLINK_TLS_INSECURE_SKIP_VERIFY=%v` environment variable could not be parsed to a boolean", os.Getenv("FLINK_TLS_INSECURE_SKIP_VERIFY"))
+		os.Exit(1)
+	}
+	transport, err := deployer.NewTransport(tlsOptions)
+	if err != nil {
+		log.Fatalf("unable to configure TLS: %v", err)
+		os.Exit(1)
+	}
+	timeout := time.Second * time.Duration(flinkAPITimeoutSeconds)
+
+	authenticator, err := getAuthenticator(&http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	})
+	if err != nil {
+		log.Fatalf("unable to configure authentication: %v", err)
+		os.Exit(1)
+	}
+
+	discoverer, err := getDiscoverer(&http.Client{Transport: transport})
+	if err != nil {
+		log.Fatal(err)
+		os.Exit(1)
+	}
+
+	policies, circuitBreaker, err := getPolicies(timeout)
+	if err != nil {
+		log.Fatal(err)
+		os.Exit(1)
+	}
+
+	mavenRepositories, err := getMavenRepositories()
+	if err != nil {
+		log.Fatal(err)
+		os.Exit(1)
+	}
+
+	s3, err := getS3(cleanhttp.DefaultPooledClient())
+	if err != nil {
+		log.Fatal(err)
+		os.Exit(1)
+	}
+
+	oci, err := getOCI(cleanhttp.DefaultPooledClient())
+	if err != nil {
+		log.Fatal(err)
+		os.Exit(1)
+	}
+
+	httpSource, err := getHTTP(tlsOptions)
+	if err != nil {
+		log.Fatal(err)
+		os.Exit(1)
+	}
+
+	cache, err := getCache()
+	if err != nil {
+		log.Fatal(err)
+		os.Exit(1)
+	}
+
+	verification, err := getVerification()
+	if err != nil {
+		log.Fatal(err)
+		os.Exit(1)
+	}
+
+	options := []deployer.Option{
+		deployer.WithTLS(tlsOptions),
+		deployer.WithTransport(transport),
+		deployer.WithTimeout(timeout),
+		deployer.WithBasicAuth(flinkBasicAuthUsername, flinkBasicAuthPassword),
+		deployer.WithPolicies(policies),
+		deployer.WithCircuitBreaker(circuitBreaker),
+		deployer.WithFilesystem(filesystem),
+		deployer.WithMavenRepositories(mavenRepositories...),
+		deployer.WithS3(s3),
+		deployer.WithOCI(oci),
+		deployer.WithHTTP(httpSource),
+		deployer.WithVerification(verification),
+		deployer.WithLogger(log.New(redactor.Writer(os.Stderr), "", log.LstdFlags)),
+		deployer.WithSecrets(getSecrets()),
+	}
+	if cache != nil {
+		options = append(options, deployer.WithCache(*cache))
+	}
+	if discoverer != nil {
+		options = append(options, deployer.WithDiscoverer(discoverer))
+	} else {
+		baseURLs, err := parseBaseURLs(flinkBaseURL)
+		if err != nil {
+			log.Fatal(err)
+			os.Exit(1)
+		}
+		options = append(options, deployer.WithBaseURL(baseURLs...))
+	}
+	if authenticator != nil {
+		options = append(options, deployer.WithAuthenticator(authenticator))
+	}
+
+	operator, err = deployer.New(ctx, options...)
+	if err != nil {
+		log.Fatal(err)
+		os.Exit(1)
+	}
+}
+
+func main() {
+	ctx = cancelOnSignal(notifySignals(), os.Exit)
+	filesystem = afero.NewOsFs()
+
+	log.SetOutput(redactor.Writer(os.Stderr))
+	cli.ErrWriter = redactor.Writer(os.Stderr)
+
+	app := cli.NewApp()
+	app.Writer = redactor.Writer(os.Stdout)
+	app.Name = "Flink Deployer"
+	app.Description = "A Go command-line utility to facilitate deployments to Apache Flink"
+	app.Version = "1.4.0"
+	app.Before = func(c *cli.Context) error {
+		if !clusterlessCommands[c.Args().First()] {
+			configureOperator()
+		}
+		return nil
+	}
+
+	app.Commands = []cli.Command{
+		{
+			Name:    "list",
+			Aliases: []string{"l"},
+			Usage:   "list the jobs running on the job manager",
+			Action:  ListAction,
+		},
+		{
+			Name:    "deploy",
+			Aliases: []string{"d"},
+			Usage:   "deploy the JAR to the job manager",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "file-name, fn",
 					Usage: "The complete name of the job JAR file",
 				},
 				cli.StringFlag{
@@ -261,7 +1259,24 @@ func main() {
 				},
 				cli.StringFlag{
 					Name:  "api-token, at",
-					Usage: "The GitLab API token for the remote address of the a remote file",
+					Usage: "The API token for the remote address of the a remote file",
+				},
+				cli.StringFlag{
+					Name:  "api-token-preset",
+					Value: "gitlab",
+					Usage: "How the API token is sent: 'gitlab', 'github', 'artifactory' or 'bearer'",
+				},
+				cli.StringFlag{
+					Name:  "sha256",
+					Usage: "The SHA-256 checksum the JAR file must have",
+				},
+				cli.StringFlag{
+					Name:  "signature",
+					Usage: "The detached GPG or cosign signature of the JAR file, a local file or an artifact reference",
+				},
+				cli.StringFlag{
+					Name:  "artifact, a",
+					Usage: "The reference of the job JAR file to be downloaded, e.g. maven:groupId:artifactId:version[:classifier], s3://bucket/key, oci://registry/repository:tag, oci://registry/repository@sha256:digest or an HTTP(S) URL",
 				},
 				cli.StringFlag{
 					Name:  "entry-class, ec",
@@ -269,12 +1284,28 @@ func main() {
 				},
 				cli.StringFlag{
 					Name:  "parallelism, p",
-					Usage: "The parallelism count",
+					Usage: "The parallelism count or 'auto' to use the available task slots",
+				},
+				cli.IntFlag{
+					Name:  "parallelism-limit, pl",
+					Usage: "The maximum parallelism when the parallelism is set to 'auto'",
+				},
+				cli.BoolFlag{
+					Name:  "check-capacity, cc",
+					Usage: "Verify that the cluster has enough available task slots before submitting the job. Skipped when the cluster has no task managers, as they are started on demand",
+				},
+				cli.IntFlag{
+					Name:  "capacity-timeout, ct",
+					Usage: "The number of seconds to wait for enough available task slots with 'check-capacity', fails immediately when unset",
 				},
 				cli.StringSliceFlag{
 					Name:  "program-args, pa",
 					Usage: "The arguments to pass to the program execution. This flag may be repeated to provide multiple arguments",
 				},
+				cli.StringFlag{
+					Name:  "args-file, af",
+					Usage: "A YAML or JSON file with program arguments, passed as '--key value' before the 'program-args'",
+				},
 				cli.StringFlag{
 					Name:  "savepoint-dir, sd",
 					Usage: "The path to the directory that contains the savepoints",
@@ -287,6 +1318,18 @@ func main() {
 					Name:  "allow-non-restored-state, anrs",
 					Usage: "Allow the job to run if the state cannot be restored",
 				},
+				cli.StringFlag{
+					Name:  "job-id",
+					Usage: "The ID of the new job, 32 hexadecimal characters. Flink 1.9 and newer",
+				},
+				cli.StringFlag{
+					Name:  "restore-mode",
+					Usage: "How the job restores from the savepoint: 'CLAIM', 'NO_CLAIM' or 'LEGACY'. Flink 1.15 and newer",
+				},
+				cli.StringSliceFlag{
+					Name:  "flink-config",
+					Usage: "A key=value pair of Flink configuration for the job. This flag may be repeated. Flink 1.17 and newer",
+				},
 			},
 			Action: DeployAction,
 		},
@@ -309,7 +1352,24 @@ func main() {
 				},
 				cli.StringFlag{
 					Name:  "api-token, at",
-					Usage: "The GitLab API token for the remote address of the a remote file",
+					Usage: "The API token for the remote address of the a remote file",
+				},
+				cli.StringFlag{
+					Name:  "api-token-preset",
+					Value: "gitlab",
+					Usage: "How the API token is sent: 'gitlab', 'github', 'artifactory' or 'bearer'",
+				},
+				cli.StringFlag{
+					Name:  "sha256",
+					Usage: "The SHA-256 checksum the JAR file must have",
+				},
+				cli.StringFlag{
+					Name:  "signature",
+					Usage: "The detached GPG or cosign signature of the JAR file, a local file or an artifact reference",
+				},
+				cli.StringFlag{
+					Name:  "artifact, a",
+					Usage: "The reference of the job JAR file to be downloaded, e.g. maven:groupId:artifactId:version[:classifier], s3://bucket/key, oci://registry/repository:tag, oci://registry/repository@sha256:digest or an HTTP(S) URL",
 				},
 				cli.StringFlag{
 					Name:  "entry-class, ec",
@@ -317,12 +1377,28 @@ func main() {
 				},
 				cli.StringFlag{
 					Name:  "parallelism, p",
-					Usage: "The parallelism count",
+					Usage: "The parallelism count or 'auto' to use the available task slots",
+				},
+				cli.IntFlag{
+					Name:  "parallelism-limit, pl",
+					Usage: "The maximum parallelism when the parallelism is set to 'auto'",
+				},
+				cli.BoolFlag{
+					Name:  "check-capacity, cc",
+					Usage: "Verify that the cluster has enough available task slots before submitting the job. Skipped when the cluster has no task managers, as they are started on demand",
+				},
+				cli.IntFlag{
+					Name:  "capacity-timeout, ct",
+					Usage: "The number of seconds to wait for enough available task slots with 'check-capacity', fails immediately when unset",
 				},
 				cli.StringSliceFlag{
 					Name:  "program-args, pa",
 					Usage: "The arguments to pass to the program execution",
 				},
+				cli.StringFlag{
+					Name:  "args-file, af",
+					Usage: "A YAML or JSON file with program arguments, passed as '--key value' before the 'program-args'",
+				},
 				cli.StringFlag{
 					Name:  "savepoint-dir, sd",
 					Usage: "The path to the directory that contains the savepoints",
@@ -335,6 +1411,18 @@ func main() {
 					Name:  "fallback-to-deploy, fbd",
 					Usage: "Continue to deploy the job if no running instance of the job is found",
 				},
+				cli.StringFlag{
+					Name:  "job-id",
+					Usage: "The ID of the new job, 32 hexadecimal characters. Flink 1.9 and newer",
+				},
+				cli.StringFlag{
+					Name:  "restore-mode",
+					Usage: "How the job restores from the savepoint: 'CLAIM', 'NO_CLAIM' or 'LEGACY'. Flink 1.15 and newer",
+				},
+				cli.StringSliceFlag{
+					Name:  "flink-config",
+					Usage: "A key=value pair of Flink configuration for the job. This flag may be repeated. Flink 1.17 and newer",
+				},
 			},
 			Action: UpdateAction,
 		},
@@ -351,9 +1439,260 @@ func main() {
 					Name:  "mode, m",
 					Usage: "The mode to terminate a running job, cancel and stop supported",
 				},
+				cli.StringFlag{
+					Name:  "savepoint-dir, sd",
+					Usage: "The directory of the savepoint taken when stopping the job, defaults to the default savepoint directory of the cluster. Flink 1.9 and newer",
+				},
 			},
 			Action: TerminateAction,
 		},
+		{
+			Name:    "rescale",
+			Aliases: []string{"r"},
+			Usage:   "Change the parallelism of a running job without redeploying it",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "job-name-base, jnb",
+					Usage: "The base name of the job to rescale",
+				},
+				cli.IntFlag{
+					Name:  "parallelism, p",
+					Usage: "The new parallelism count",
+				},
+				cli.StringFlag{
+					Name:  "jar-id, jar",
+					Usage: "The ID of the uploaded JAR file of the job, used to restart the job when rescaling is not supported",
+				},
+				cli.StringFlag{
+					Name:  "entry-class, ec",
+					Usage: "The entry class name that contains the main method, used to restart the job",
+				},
+				cli.StringSliceFlag{
+					Name:  "program-args, pa",
+					Usage: "The arguments to pass to the program execution, used to restart the job",
+				},
+				cli.StringFlag{
+					Name:  "args-file, af",
+					Usage: "A YAML or JSON file with program arguments, passed as '--key value' before the 'program-args'",
+				},
+				cli.StringFlag{
+					Name:  "savepoint-dir, sd",
+					Usage: "The path to the directory that contains the savepoints, used to restart the job",
+				},
+				cli.BoolFlag{
+					Name:  "allow-non-restored-state, anrs",
+					Usage: "Allow the job to run if the state cannot be restored",
+				},
+			},
+			Action: RescaleAction,
+		},
+		{
+			Name:  "exceptions",
+			Usage: "Print the exceptions of a job",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "job-id, jid",
+					Usage: "The ID of the job",
+				},
+				cli.StringFlag{
+					Name:  "job-name-base, jnb",
+					Usage: "The base name of the job, the most recently started job matching it is used",
+				},
+			},
+			Action: ExceptionsAction,
+		},
+		{
+			Name:  "logs",
+			Usage: "Print the logs of the job manager and task managers",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "job-manager, jm",
+					Usage: "Print the job manager log, the default when no task manager is specified",
+				},
+				cli.StringFlag{
+					Name:  "task-manager-id, tm",
+					Usage: "The ID of the task manager to print the log of",
+				},
+				cli.BoolFlag{
+					Name:  "all-task-managers, atm",
+					Usage: "Print the logs of all task managers",
+				},
+				cli.IntFlag{
+					Name:  "tail, n",
+					Usage: "The number of lines to print from the end of every log, prints the complete log when unset",
+				},
+			},
+			Action: LogsAction,
+		},
+		{
+			Name:  "doctor",
+			Usage: "Check the health of the Flink cluster and its running jobs",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "job-name-base, jnb",
+					Usage: "Only check the jobs matching the base name",
+				},
+				cli.StringFlag{
+					Name:  "savepoint-dir, sd",
+					Usage: "The path to the directory that contains the savepoints, checked for readability",
+				},
+				cli.IntFlag{
+					Name:  "slow-checkpoint-seconds, scs",
+					Usage: "The average checkpoint duration in seconds above which checkpoints are reported as slow (default 60)",
+				},
+			},
+			Action: DoctorAction,
+		},
+		{
+			Name:  "plan",
+			Usage: "Render the job graph of a running job or a JAR file",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "job-id, jid",
+					Usage: "The ID of the running job to render",
+				},
+				cli.StringFlag{
+					Name:  "job-name-base, jnb",
+					Usage: "The base name of the running job to render",
+				},
+				cli.StringFlag{
+					Name:  "jar-id, jar",
+					Usage: "The ID of a JAR file already uploaded to the job manager",
+				},
+				cli.StringFlag{
+					Name:  "file-name, fn",
+					Usage: "The complete name of the job JAR file",
+				},
+				cli.StringFlag{
+					Name:  "remote-file-name, rfn",
+					Usage: "The location of a GitLab job JAR file to be downloaded",
+				},
+				cli.StringFlag{
+					Name:  "api-token, at",
+					Usage: "The API token for the remote address of the a remote file",
+				},
+				cli.StringFlag{
+					Name:  "api-token-preset",
+					Value: "gitlab",
+					Usage: "How the API token is sent: 'gitlab', 'github', 'artifactory' or 'bearer'",
+				},
+				cli.StringFlag{
+					Name:  "sha256",
+					Usage: "The SHA-256 checksum the JAR file must have",
+				},
+				cli.StringFlag{
+					Name:  "signature",
+					Usage: "The detached GPG or cosign signature of the JAR file, a local file or an artifact reference",
+				},
+				cli.StringFlag{
+					Name:  "artifact, a",
+					Usage: "The reference of the job JAR file to be downloaded, e.g. maven:groupId:artifactId:version[:classifier], s3://bucket/key, oci://registry/repository:tag, oci://registry/repository@sha256:digest or an HTTP(S) URL",
+				},
+				cli.StringFlag{
+					Name:  "entry-class, ec",
+					Usage: "The entry class name that contains the main method",
+				},
+				cli.StringFlag{
+					Name:  "parallelism, p",
+					Usage: "The parallelism count",
+				},
+				cli.StringSliceFlag{
+					Name:  "program-args, pa",
+					Usage: "The arguments to pass to the program execution. This flag may be repeated to provide multiple arguments",
+				},
+				cli.StringFlag{
+					Name:  "args-file, af",
+					Usage: "A YAML or JSON file with program arguments, passed as '--key value' before the 'program-args'",
+				},
+				cli.StringFlag{
+					Name:  "format, f",
+					Usage: "The output format of the job graph, ascii (default), dot and mermaid supported",
+				},
+				cli.StringFlag{
+					Name:  "output-file, o",
+					Usage: "The file to write the job graph to instead of standard output",
+				},
+			},
+			Action: PlanAction,
+		},
+		{
+			Name:  "fake-cluster",
+			Usage: "Run an in-memory Flink cluster for local demos, which keeps its jobs until it is stopped",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "address, a",
+					Value: "localhost:8081",
+					Usage: "The address the Flink REST API listens on",
+				},
+				cli.StringFlag{
+					Name:  "flink-version, fv",
+					Value: "1.9.0",
+					Usage: "The Flink version reported by the cluster",
+				},
+				cli.IntFlag{
+					Name:  "task-managers, tm",
+					Value: 1,
+					Usage: "The number of task managers",
+				},
+				cli.IntFlag{
+					Name:  "slots, s",
+					Value: 4,
+					Usage: "The number of task slots per task manager",
+				},
+				cli.IntFlag{
+					Name:  "savepoint-seconds, ss",
+					Value: 2,
+					Usage: "The number of seconds a savepoint takes to complete",
+				},
+				cli.IntFlag{
+					Name:  "checkpoint-interval-seconds, cis",
+					Value: 10,
+					Usage: "The number of seconds between the checkpoints of a running job",
+				},
+				cli.StringFlag{
+					Name:  "savepoint-dir, sd",
+					Usage: "The savepoint directory used when a job is stopped without a target directory",
+				},
+			},
+			Action: FakeClusterAction,
+		},
+		{
+			Name:  "inspect-jar",
+			Usage: "Print the manifest of a local JAR file and check that its entry class exists",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "file-name, fn",
+					Usage: "The path to the JAR file",
+				},
+				cli.StringFlag{
+					Name:  "entry-class, ec",
+					Usage: "The entry class to check instead of the entry class of the manifest",
+				},
+			},
+			Action: InspectJarAction,
+		},
+		{
+			Name:  "cache",
+			Usage: "Manage the cache of downloaded artifacts in FLINK_ARTIFACT_CACHE_DIR",
+			Subcommands: []cli.Command{
+				{
+					Name:   "list",
+					Usage:  "List the cached artifacts, the most recently used first",
+					Action: CacheListAction,
+				},
+				{
+					Name:  "prune",
+					Usage: "Evict the least recently used artifacts, or all of them without 'max-size'",
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "max-size",
+							Usage: "The size the cache is pruned to, e.g. 500MB or 2GB",
+						},
+					},
+					Action: CachePruneAction,
+				},
+			},
+		},
 	}
 
 	app.Run(os.Args)
@@ -1,8 +1,9 @@
 package main
 
 import (
-	"github.com/ing-bank/flink-deployer/cmd/cli/flink"
-	"github.com/ing-bank/flink-deployer/cmd/cli/operations"
+	"context"
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/ing-bank/flink-deployer/pkg/operations"
 	"github.com/spf13/afero"
 )
 
@@ -11,24 +12,52 @@ var mockedUpdateError error
 var mockedTerminateError error
 var mockedRetrieveJobsResponse []flink.Job
 var mockedRetrieveJobsError error
+var mockedPlanResponse flink.Plan
+var mockedPlanError error
+var mockedRescaleError error
+var mockedExceptionsResponse flink.JobExceptions
+var mockedExceptionsError error
+var mockedLogsResponse []operations.LogFile
+var mockedLogsError error
+var mockedDoctorResponse []operations.CheckResult
 
 type TestOperator struct {
 	Filesystem   afero.Fs
 	FlinkRestAPI flink.FlinkRestAPI
 }
 
-func (t TestOperator) Deploy(d operations.Deploy) error {
-	return mockedDeployError
+func (t TestOperator) Deploy(ctx context.Context, d operations.Deploy) (operations.DeployResult, error) {
+	return operations.DeployResult{}, mockedDeployError
 }
 
-func (t TestOperator) Update(u operations.UpdateJob) error {
-	return mockedUpdateError
+func (t TestOperator) Update(ctx context.Context, u operations.UpdateJob) (operations.UpdateResult, error) {
+	return operations.UpdateResult{}, mockedUpdateError
 }
 
-func (t TestOperator) Terminate(te operations.TerminateJob) error {
+func (t TestOperator) Terminate(ctx context.Context, te operations.TerminateJob) error {
 	return mockedUpdateError
 }
 
-func (t TestOperator) RetrieveJobs() ([]flink.Job, error) {
+func (t TestOperator) RetrieveJobs(ctx context.Context) ([]flink.Job, error) {
 	return mockedRetrieveJobsResponse, mockedRetrieveJobsError
 }
+
+func (t TestOperator) Plan(ctx context.Context, p operations.Plan) (flink.Plan, error) {
+	return mockedPlanResponse, mockedPlanError
+}
+
+func (t TestOperator) Rescale(ctx context.Context, r operations.Rescale) error {
+	return mockedRescaleError
+}
+
+func (t TestOperator) Exceptions(ctx context.Context, e operations.Exceptions) (flink.JobExceptions, error) {
+	return mockedExceptionsResponse, mockedExceptionsError
+}
+
+func (t TestOperator) Logs(ctx context.Context, l operations.Logs) ([]operations.LogFile, error) {
+	return mockedLogsResponse, mockedLogsError
+}
+
+func (t TestOperator) Doctor(ctx context.Context, d operations.Doctor) []operations.CheckResult {
+	return mockedDoctorResponse
+}
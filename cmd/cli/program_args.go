@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/ing-bank/flink-deployer/pkg/secret"
+	"github.com/spf13/afero"
+	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
+)
+
+// variablePattern matches the variables in program arguments, like ${BROKERS},
+// and the escaped $${, which is kept for the operator resolving the secrets
+var variablePattern = regexp.MustCompile(`\$\$\{|\$\{([^}]*)\}`)
+
+// getProgramArgs returns the program arguments of the command: the
+// arguments of the `args-file` followed by the `program-args`, with
+// their variables substituted. The secret references are resolved by
+// the operator.
+func getProgramArgs(c *cli.Context) ([]string, error) {
+	args := []string{}
+	if path := c.String("args-file"); len(path) > 0 {
+		content, err := afero.ReadFile(filesystem, path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the arguments file: %v", err)
+		}
+		fileArgs, err := parseArgsFile(content)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, fileArgs...)
+	}
+	args = append(args, c.StringSlice("program-args")...)
+
+	values, err := getContextValues()
+	if err != nil {
+		return nil, err
+	}
+	return substituteVariables(args, values)
+}
+
+// parseArgsFile renders the keys and values of a YAML or JSON
+// arguments file as `--key value`, in the order of the file.
+// Keys without a value are rendered as `--key`.
+func parseArgsFile(content []byte) ([]string, error) {
+	items := yaml.MapSlice{}
+	err := yaml.Unmarshal(content, &items)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the arguments file: %v", err)
+	}
+
+	args := []string{}
+	for _, item := range items {
+		key := fmt.Sprint(item.Key)
+		if !strings.HasPrefix(key, "-") {
+			key = "--" + key
+		}
+
+		switch value := item.Value.(type) {
+		case nil:
+			args = append(args, key)
+		case string, int, int64, uint64, float64, bool:
+			args = append(args, key, fmt.Sprint(value))
+		default:
+			return nil, fmt.Errorf("the value of %v in the arguments file must be a string, number or boolean", item.Key)
+		}
+	}
+	return args, nil
+}
+
+// getContextValues returns the values of the context in `FLINK_CONTEXT`,
+// configured by the YAML file in `FLINK_VALUES_FILE`, e.g.
+//
+//	production:
+//	  BROKERS: kafka.production:9092
+func getContextValues() (map[string]string, error) {
+	path := os.Getenv("FLINK_VALUES_FILE")
+	if len(path) == 0 {
+		return map[string]string{}, nil
+	}
+
+	content, err := afero.ReadFile(filesystem, path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the values file: %v", err)
+	}
+	contexts := map[string]map[string]string{}
+	err = yaml.UnmarshalStrict(content, &contexts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the values file: %v", err)
+	}
+
+	values := contexts[os.Getenv("FLINK_CONTEXT")]
+	if values == nil {
+		values = map[string]string{}
+	}
+	return values, nil
+}
+
+// substituteVariables replaces the variables in the program arguments by the
+// values of the context or, when the context has no such value, by the
+// environment variables. Secret references, e.g. ${vault:secret/data/kafka#password},
+// and escaped variables are kept, as the operator resolves them.
+func substituteVariables(args []string, values map[string]string) ([]string, error) {
+	substituted := make([]string, len(args))
+	for i, arg := range args {
+		var err error
+		substituted[i] = variablePattern.ReplaceAllStringFunc(arg, func(match string) string {
+			if match == "$${" {
+				return match
+			}
+			name := match[2 : len(match)-1]
+			if secret.IsReference(name) {
+				return match
+			}
+			if value, ok := values[name]; ok {
+				return value
+			}
+			if value, ok := os.LookupEnv(name); ok {
+				return value
+			}
+			if err == nil {
+				err = fmt.Errorf("the variable %v of the program argument %q is neither a value of the context nor an environment variable", name, arg)
+			}
+			return match
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return substituted, nil
+}
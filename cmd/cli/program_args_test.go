@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+/*
+ * parseArgsFile
+ */
+func TestParseArgsFileShouldRenderTheKeysAndValuesInOrder(t *testing.T) {
+	args, err := parseArgsFile([]byte("input: s3://jobs/input\nintervalMs: 1000\nname: word count\nverbose:\n-p: 2\n"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"--input", "s3://jobs/input", "--intervalMs", "1000", "--name", "word count", "--verbose", "-p", "2"}, args)
+}
+
+func TestParseArgsFileShouldParseJSON(t *testing.T) {
+	args, err := parseArgsFile([]byte(`{"input": "s3://jobs/input", "ratio": 0.5, "dryRun": false}`))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"--input", "s3://jobs/input", "--ratio", "0.5", "--dryRun", "false"}, args)
+}
+
+func TestParseArgsFileShouldRejectNestedValues(t *testing.T) {
+	_, err := parseArgsFile([]byte("topics:\n  - words\n"))
+
+	assert.EqualError(t, err, "the value of topics in the arguments file must be a string, number or boolean")
+}
+
+/*
+ * substituteVariables
+ */
+func TestSubstituteVariablesShouldPreferTheValuesOfTheContext(t *testing.T) {
+	os.Setenv("FLINK_TEST_BROKERS", "kafka.local:9092")
+	os.Setenv("FLINK_TEST_TOPIC", "words")
+	defer os.Unsetenv("FLINK_TEST_BROKERS")
+	defer os.Unsetenv("FLINK_TEST_TOPIC")
+
+	args, err := substituteVariables([]string{"--brokers", "${FLINK_TEST_BROKERS}", "--topic", "${FLINK_TEST_TOPIC}-$${raw}"}, map[string]string{"FLINK_TEST_BROKERS": "kafka.production:9092"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"--brokers", "kafka.production:9092", "--topic", "words-$${raw}"}, args)
+}
+
+func TestSubstituteVariablesShouldReturnAnErrorForAnUndefinedVariable(t *testing.T) {
+	_, err := substituteVariables([]string{"--input", "s3://${FLINK_TEST_UNDEFINED}/input"}, map[string]string{})
+
+	assert.EqualError(t, err, `the variable FLINK_TEST_UNDEFINED of the program argument "s3://${FLINK_TEST_UNDEFINED}/input" is neither a value of the context nor an environment variable`)
+}
+
+func TestSubstituteVariablesShouldKeepTheSecretReferences(t *testing.T) {
+	args, err := substituteVariables([]string{"--password", "${vault:secret/data/kafka#password}"}, map[string]string{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"--password", "${vault:secret/data/kafka#password}"}, args)
+}
+
+/*
+ * getProgramArgs
+ */
+func TestGetProgramArgsShouldAppendTheProgramArgsToTheArgsFile(t *testing.T) {
+	filesystem = afero.NewMemMapFs()
+	afero.WriteFile(filesystem, "/args.yaml", []byte("brokers: ${BROKERS}\n"), 0644)
+	afero.WriteFile(filesystem, "/values.yaml", []byte("production:\n  BROKERS: kafka.production:9092\nstaging:\n  BROKERS: kafka.staging:9092\n"), 0644)
+	os.Setenv("FLINK_VALUES_FILE", "/values.yaml")
+	os.Setenv("FLINK_CONTEXT", "staging")
+	defer os.Unsetenv("FLINK_VALUES_FILE")
+	defer os.Unsetenv("FLINK_CONTEXT")
+
+	app := cli.App{}
+	set := flag.FlagSet{}
+	set.String("args-file", "/args.yaml", "")
+	programArgs := cli.StringSlice{"--intervalMs", "1000"}
+	set.Var(&programArgs, "program-args", "")
+	context := cli.NewContext(&app, &set, nil)
+	args, err := getProgramArgs(context)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"--brokers", "kafka.staging:9092", "--intervalMs", "1000"}, args)
+}
+
+func TestGetProgramArgsShouldReturnAnErrorForAMissingArgsFile(t *testing.T) {
+	filesystem = afero.NewMemMapFs()
+
+	app := cli.App{}
+	set := flag.FlagSet{}
+	set.String("args-file", "/args.yaml", "")
+	context := cli.NewContext(&app, &set, nil)
+	_, err := getProgramArgs(context)
+
+	assert.EqualError(t, err, "unable to read the arguments file: open /args.yaml: file does not exist")
+}
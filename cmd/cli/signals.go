@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifySignals returns a channel receiving SIGINT and SIGTERM
+func notifySignals() <-chan os.Signal {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	return signals
+}
+
+// cancelOnSignal returns a context which is canceled on the first signal, so
+// the running operation stops at the next safe point and reports where it
+// stopped. A second signal exits immediately.
+func cancelOnSignal(signals <-chan os.Signal, exit func(int)) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		sig := <-signals
+		log.Printf("received %v, stopping at the next safe point. Send it again to exit immediately", sig)
+		cancel()
+
+		sig = <-signals
+		log.Printf("received %v again, exiting immediately", sig)
+		exit(exitCodeCanceled)
+	}()
+
+	return ctx
+}
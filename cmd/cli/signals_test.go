@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancelOnSignalShouldCancelTheContextOnTheFirstSignal(t *testing.T) {
+	signals := make(chan os.Signal, 2)
+	exitCodes := make(chan int, 1)
+
+	ctx := cancelOnSignal(signals, func(code int) { exitCodes <- code })
+	assert.Nil(t, ctx.Err())
+
+	signals <- syscall.SIGTERM
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the context wasn't canceled")
+	}
+	assert.Len(t, exitCodes, 0)
+
+	signals <- syscall.SIGINT
+	select {
+	case code := <-exitCodes:
+		assert.Equal(t, exitCodeCanceled, code)
+	case <-time.After(time.Second):
+		t.Fatal("the second signal didn't exit")
+	}
+}
@@ -0,0 +1,113 @@
+// Package artifact downloads the JAR files of jobs from artifact repositories
+package artifact
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// logf logs to the logger, unless it's nil
+func logf(logger *log.Logger, format string, v ...interface{}) {
+	if logger != nil {
+		logger.Printf(format, v...)
+	}
+}
+
+// get sends a GET request for the URL, applying the basic authentication
+// credentials when set, and categorizes the errors of unsuccessful responses
+func get(ctx context.Context, client *http.Client, url string, username string, password string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(username) > 0 || len(password) > 0 {
+		req.SetBasicAuth(username, password)
+	}
+	return do(ctx, client, req)
+}
+
+// do sends the request and categorizes the errors of unsuccessful responses
+func do(ctx context.Context, client *http.Client, req *http.Request) (*http.Response, error) {
+	req = req.WithContext(ctx)
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil && ctx.Err() == context.Canceled {
+		return nil, flink.CategorizedErrorf(flink.ErrCanceled, "%v", err)
+	}
+	if err != nil {
+		return nil, flink.CategorizedErrorf(flink.ErrClusterUnreachable, "%v", err)
+	}
+
+	if res.StatusCode == http.StatusOK || res.StatusCode == http.StatusPartialContent {
+		return res, nil
+	}
+
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+	return nil, &ResponseError{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: res.StatusCode,
+		Body:       string(body[:]),
+		Challenge:  res.Header.Get("WWW-Authenticate"),
+	}
+}
+
+// A ResponseError is returned when an artifact repository
+// responds with an unexpected status code
+type ResponseError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+	// Challenge is the WWW-Authenticate header of an unauthorized response
+	Challenge string
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%v %v returned unexpected response status %v with body %v", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+// Cause returns the category of the response error based on its status code
+func (e *ResponseError) Cause() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return flink.ErrNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return flink.ErrUnauthorized
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return flink.ErrTimeout
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return flink.ErrClusterUnreachable
+	}
+	return nil
+}
+
+// writeFile writes the content to the file at the path, and
+// removes the file again when the content can't be read
+func writeFile(path string, content io.Reader) (int64, error) {
+	out, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(out, content)
+	closeErr := out.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return n, err
+	}
+	return n, nil
+}
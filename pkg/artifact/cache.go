@@ -0,0 +1,369 @@
+package artifact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// Cache keeps downloaded JAR files in a directory, so deploying the same
+// artifact again doesn't download it again. The files are stored once per
+// content hash, in blobs/sha256/<hash>, and entries/<hash of the reference>.json
+// maps the references to them.
+type Cache struct {
+	Dir string
+	// MaxSize is the maximum total size of the cached files in bytes. The
+	// least recently used files are evicted when it's exceeded. Zero means
+	// the size is unlimited.
+	MaxSize int64
+	// MaxAge is how long files of references that may change, like
+	// URLs, S3 locations, tags and snapshots, are used before they're
+	// downloaded again. Files of immutable references, like OCI digests
+	// and Maven releases, are used as long as they're cached.
+	MaxAge time.Duration
+	// Offline uses the cached files regardless of their age and
+	// fails for the references that aren't cached
+	Offline bool
+	// Logger, when set, receives the cached files which are used
+	// and the problems with the cache which are ignored
+	Logger *log.Logger
+	now    func() time.Time
+}
+
+// A CacheEntry is a reference with its cached file
+type CacheEntry struct {
+	Reference  string    `json:"reference"`
+	Digest     string    `json:"digest"`
+	Filename   string    `json:"filename"`
+	Size       int64     `json:"size"`
+	Downloaded time.Time `json:"downloaded"`
+	LastUsed   time.Time `json:"lastUsed"`
+}
+
+// Fetch copies the cached file of the reference into the directory, or
+// downloads it with download and adds it to the cache. It returns the path
+// of the file in the directory.
+func (c Cache) Fetch(reference string, dir string, download func(dir string) (string, error)) (string, error) {
+	entry, ok, err := c.entry(reference)
+	if err != nil {
+		logf(c.Logger, "ignoring the artifact cache: %v", err)
+	}
+	if ok && (c.Offline || immutable(reference) || c.clock().Sub(entry.Downloaded) < c.MaxAge) {
+		path, err := c.copyOut(entry, dir)
+		if err == nil {
+			logf(c.Logger, "using the cached %v (%v)", reference, entry.Digest)
+			return path, nil
+		}
+		logf(c.Logger, "ignoring the cached %v: %v", reference, err)
+		c.remove(entry)
+	}
+
+	if c.Offline {
+		return "", flink.CategorizedErrorf(flink.ErrNotFound, "%v isn't cached, and artifacts aren't downloaded in offline mode", reference)
+	}
+
+	path, err := download(dir)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.store(reference, path)
+	if err != nil {
+		// The cache only saves downloads, so failing to store a file doesn't fail the deployment
+		logf(c.Logger, "unable to cache %v: %v", reference, err)
+	}
+	return path, nil
+}
+
+// List returns the cached entries, the most recently used first
+func (c Cache) List() ([]CacheEntry, error) {
+	files, err := ioutil.ReadDir(filepath.Join(c.Dir, "entries"))
+	if os.IsNotExist(err) {
+		return []CacheEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []CacheEntry{}
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		entry, err := readCacheEntry(filepath.Join(c.Dir, "entries", file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+	return entries, nil
+}
+
+// Prune evicts the least recently used entries until the cached files
+// take at most maxSize bytes, and returns the evicted entries. Files no
+// entry refers to anymore are removed as well.
+func (c Cache) Prune(maxSize int64) ([]CacheEntry, error) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+
+	blobs, err := ioutil.ReadDir(filepath.Join(c.Dir, "blobs", "sha256"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	sizes := map[string]int64{}
+	for _, blob := range blobs {
+		sizes["sha256:"+blob.Name()] = blob.Size()
+	}
+
+	evicted := []CacheEntry{}
+	references := map[string]int{}
+	kept := []CacheEntry{}
+	for _, entry := range entries {
+		if _, ok := sizes[entry.Digest]; !ok {
+			// The file of the entry has been removed by someone else
+			os.Remove(c.entryPath(entry.Reference))
+			evicted = append(evicted, entry)
+			continue
+		}
+		references[entry.Digest]++
+		kept = append(kept, entry)
+	}
+
+	var size int64
+	for digest, blobSize := range sizes {
+		if references[digest] == 0 {
+			os.Remove(c.blobPath(digest))
+			continue
+		}
+		size += blobSize
+	}
+
+	for i := len(kept) - 1; i >= 0 && size > maxSize; i-- {
+		entry := kept[i]
+		err = os.Remove(c.entryPath(entry.Reference))
+		if err != nil && !os.IsNotExist(err) {
+			return evicted, err
+		}
+		evicted = append(evicted, entry)
+
+		references[entry.Digest]--
+		if references[entry.Digest] == 0 {
+			os.Remove(c.blobPath(entry.Digest))
+			size -= sizes[entry.Digest]
+		}
+	}
+	return evicted, nil
+}
+
+// entry returns the entry of the reference when its file is cached
+func (c Cache) entry(reference string) (CacheEntry, bool, error) {
+	entry, err := readCacheEntry(c.entryPath(reference))
+	if os.IsNotExist(err) {
+		return CacheEntry{}, false, nil
+	}
+	if err != nil {
+		return CacheEntry{}, false, err
+	}
+	if _, err := os.Stat(c.blobPath(entry.Digest)); err != nil {
+		return CacheEntry{}, false, nil
+	}
+	return entry, true, nil
+}
+
+// copyOut copies the cached file of the entry into the directory,
+// verifying its content hash, and marks the entry as used
+func (c Cache) copyOut(entry CacheEntry, dir string) (string, error) {
+	in, err := os.Open(c.blobPath(entry.Digest))
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	path := filepath.Join(dir, filepath.Base(entry.Filename))
+	hash := sha256.New()
+	_, err = writeFile(path, io.TeeReader(in, hash))
+	if err != nil {
+		return "", err
+	}
+	if digest := "sha256:" + hex.EncodeToString(hash.Sum(nil)); digest != entry.Digest {
+		os.Remove(path)
+		return "", fmt.Errorf("the cached file is corrupt, its digest is %v", digest)
+	}
+
+	entry.LastUsed = c.clock()
+	err = c.writeEntry(entry)
+	if err != nil {
+		logf(c.Logger, "unable to update the artifact cache: %v", err)
+	}
+	return path, nil
+}
+
+// store adds the downloaded file of the reference to the cache
+// and evicts the least recently used files when it's full
+func (c Cache) store(reference string, path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	err = os.MkdirAll(filepath.Join(c.Dir, "blobs", "sha256"), 0755)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first, so concurrent deployers never see a partial file
+	tmp, err := ioutil.TempFile(filepath.Join(c.Dir, "blobs"), "download")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	size, err := writeFile(tmp.Name(), io.TeeReader(in, hash))
+	if err != nil {
+		return err
+	}
+	digest := "sha256:" + hex.EncodeToString(hash.Sum(nil))
+	err = os.Rename(tmp.Name(), c.blobPath(digest))
+	if err != nil {
+		return err
+	}
+
+	now := c.clock()
+	err = c.writeEntry(CacheEntry{
+		Reference:  reference,
+		Digest:     digest,
+		Filename:   filepath.Base(path),
+		Size:       size,
+		Downloaded: now,
+		LastUsed:   now,
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.MaxSize > 0 {
+		_, err = c.Prune(c.MaxSize)
+	}
+	return err
+}
+
+// remove removes the entry, and its file when no other entry refers to it
+func (c Cache) remove(entry CacheEntry) {
+	os.Remove(c.entryPath(entry.Reference))
+
+	entries, err := c.List()
+	if err != nil {
+		logf(c.Logger, "keeping the cached file %v: %v", entry.Digest, err)
+		return
+	}
+	for _, other := range entries {
+		if other.Digest == entry.Digest {
+			return
+		}
+	}
+	os.Remove(c.blobPath(entry.Digest))
+}
+
+func (c Cache) writeEntry(entry CacheEntry) error {
+	err := os.MkdirAll(filepath.Join(c.Dir, "entries"), 0755)
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Join(c.Dir, "entries"), "entry")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(content)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.entryPath(entry.Reference))
+}
+
+func readCacheEntry(path string) (CacheEntry, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return CacheEntry{}, err
+	}
+	entry := CacheEntry{}
+	err = json.Unmarshal(content, &entry)
+	if err != nil || !digestPattern.MatchString(entry.Digest) {
+		return CacheEntry{}, fmt.Errorf("the cache entry %v is invalid", path)
+	}
+	return entry, nil
+}
+
+func (c Cache) entryPath(reference string) string {
+	return filepath.Join(c.Dir, "entries", hexSHA256([]byte(reference))+".json")
+}
+
+func (c Cache) blobPath(digest string) string {
+	return filepath.Join(c.Dir, "blobs", "sha256", strings.TrimPrefix(digest, "sha256:"))
+}
+
+func (c Cache) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// immutable returns whether the file a reference points to never
+// changes: OCI references pinned by digest and Maven releases
+func immutable(reference string) bool {
+	if strings.HasPrefix(reference, OCIPrefix) {
+		ref, err := ParseOCIReference(reference)
+		return err == nil && len(ref.Digest) > 0
+	}
+	if strings.HasPrefix(reference, MavenPrefix) {
+		coordinates, err := ParseMavenCoordinates(reference)
+		return err == nil && !strings.HasSuffix(coordinates.Version, "-SNAPSHOT") && coordinates.Version != "LATEST" && coordinates.Version != "RELEASE"
+	}
+	return false
+}
+
+var sizePattern = regexp.MustCompile(`^(\d+)\s*([kmgt]?)(i?b)?$`)
+
+var sizeUnits = map[string]int64{"": 1, "k": 1 << 10, "m": 1 << 20, "g": 1 << 30, "t": 1 << 40}
+
+// ParseSize parses a size in bytes with an optional binary unit, e.g. 500MB or 2GiB
+func ParseSize(value string) (int64, error) {
+	match := sizePattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(value)))
+	if match == nil {
+		return 0, fmt.Errorf("invalid size \"%v\", expected a number of bytes with an optional unit, e.g. 500MB or 2GB", value)
+	}
+	size, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size \"%v\": %v", value, err)
+	}
+	return size * sizeUnits[match[2]], nil
+}
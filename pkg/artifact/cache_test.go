@@ -0,0 +1,242 @@
+package artifact
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingDownload returns a download writing the content to the
+// file in the directory, which counts how often it's called
+func countingDownload(t *testing.T, filename string, content string, downloads *int) func(dir string) (string, error) {
+	return func(dir string) (string, error) {
+		*downloads++
+		path := filepath.Join(dir, filename)
+		err := ioutil.WriteFile(path, []byte(content), 0644)
+		assert.Nil(t, err)
+		return path, nil
+	}
+}
+
+// fetchTwice fetches the reference into two separate directories
+// and returns the content of the second fetch
+func fetchTwice(t *testing.T, cache Cache, reference string, download func(dir string) (string, error)) string {
+	for i := 0; i < 2; i++ {
+		dir := createTestDir(t)
+		defer os.RemoveAll(dir)
+
+		path, err := cache.Fetch(reference, dir, download)
+		assert.Nil(t, err)
+		if i == 1 {
+			return readTestFile(t, path)
+		}
+	}
+	return ""
+}
+
+/*
+ * Fetch
+ */
+func TestCacheFetchShouldUseTheCachedFileOfAnImmutableReference(t *testing.T) {
+	cacheDir := createTestDir(t)
+	defer os.RemoveAll(cacheDir)
+	downloads := 0
+
+	cache := Cache{Dir: cacheDir}
+	content := fetchTwice(t, cache, "maven:com.example:word-count:1.2.0", countingDownload(t, "word-count-1.2.0.jar", "jar", &downloads))
+
+	assert.Equal(t, "jar", content)
+	assert.Equal(t, 1, downloads)
+}
+
+func TestCacheFetchShouldDownloadAMutableReferenceAgain(t *testing.T) {
+	cacheDir := createTestDir(t)
+	defer os.RemoveAll(cacheDir)
+	downloads := 0
+
+	cache := Cache{Dir: cacheDir}
+	fetchTwice(t, cache, "maven:com.example:word-count:1.3.0-SNAPSHOT", countingDownload(t, "word-count.jar", "jar", &downloads))
+
+	assert.Equal(t, 2, downloads)
+}
+
+func TestCacheFetchShouldUseTheCachedFileOfAMutableReferenceWithinTheMaxAge(t *testing.T) {
+	cacheDir := createTestDir(t)
+	defer os.RemoveAll(cacheDir)
+	downloads := 0
+
+	cache := Cache{Dir: cacheDir, MaxAge: time.Hour}
+	fetchTwice(t, cache, "https://example.com/job.jar", countingDownload(t, "job.jar", "jar", &downloads))
+
+	assert.Equal(t, 1, downloads)
+}
+
+func TestCacheFetchShouldStoreTheSameContentOnce(t *testing.T) {
+	cacheDir := createTestDir(t)
+	defer os.RemoveAll(cacheDir)
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	downloads := 0
+
+	cache := Cache{Dir: cacheDir}
+	cache.Fetch("s3://jobs/job.jar", dir, countingDownload(t, "job.jar", "jar", &downloads))
+	cache.Fetch("https://example.com/job.jar", dir, countingDownload(t, "job.jar", "jar", &downloads))
+
+	entries, err := cache.List()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, entries[0].Digest, entries[1].Digest)
+	blobs, _ := ioutil.ReadDir(filepath.Join(cacheDir, "blobs", "sha256"))
+	assert.Equal(t, 1, len(blobs))
+}
+
+func TestCacheFetchShouldUseTheCachedFileInOfflineMode(t *testing.T) {
+	cacheDir := createTestDir(t)
+	defer os.RemoveAll(cacheDir)
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	downloads := 0
+	Cache{Dir: cacheDir}.Fetch("oci://ghcr.io/jobs/word-count:latest", dir, countingDownload(t, "word-count.jar", "jar", &downloads))
+
+	path, err := Cache{Dir: cacheDir, Offline: true}.Fetch("oci://ghcr.io/jobs/word-count:latest", createTestDir(t), countingDownload(t, "word-count.jar", "new", &downloads))
+	defer os.RemoveAll(filepath.Dir(path))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "jar", readTestFile(t, path))
+	assert.Equal(t, 1, downloads)
+}
+
+func TestCacheFetchShouldReturnNotFoundForAMissingFileInOfflineMode(t *testing.T) {
+	cacheDir := createTestDir(t)
+	defer os.RemoveAll(cacheDir)
+
+	_, err := Cache{Dir: cacheDir, Offline: true}.Fetch("s3://jobs/job.jar", cacheDir, func(dir string) (string, error) {
+		return "", errors.New("downloaded")
+	})
+
+	assert.Equal(t, flink.ErrNotFound, flink.Category(err))
+	assert.EqualError(t, err, "s3://jobs/job.jar isn't cached, and artifacts aren't downloaded in offline mode")
+}
+
+func TestCacheFetchShouldDownloadACorruptFileAgain(t *testing.T) {
+	cacheDir := createTestDir(t)
+	defer os.RemoveAll(cacheDir)
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	downloads := 0
+	cache := Cache{Dir: cacheDir}
+	cache.Fetch("maven:com.example:word-count:1.2.0", dir, countingDownload(t, "word-count-1.2.0.jar", "jar", &downloads))
+	ioutil.WriteFile(filepath.Join(cacheDir, "blobs", "sha256", sha256Hex("jar")), []byte("corrupt"), 0644)
+
+	path, err := cache.Fetch("maven:com.example:word-count:1.2.0", dir, countingDownload(t, "word-count-1.2.0.jar", "jar", &downloads))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "jar", readTestFile(t, path))
+	assert.Equal(t, 2, downloads)
+}
+
+func TestCacheFetchShouldKeepTheFileOfTheOtherReferencesWhenAnEntryIsRemoved(t *testing.T) {
+	cacheDir := createTestDir(t)
+	defer os.RemoveAll(cacheDir)
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	downloads := 0
+	cache := Cache{Dir: cacheDir}
+	cache.Fetch("maven:com.example:word-count:1.2.0", dir, countingDownload(t, "word-count-1.2.0.jar", "jar", &downloads))
+	cache.Fetch("s3://jobs/word-count-1.2.0.jar", dir, countingDownload(t, "word-count-1.2.0.jar", "jar", &downloads))
+
+	// Copying the cached file into a missing directory fails, which removes the entry
+	_, err := cache.Fetch("maven:com.example:word-count:1.2.0", filepath.Join(dir, "missing"), func(dir string) (string, error) {
+		return "", errors.New("download failed")
+	})
+	assert.EqualError(t, err, "download failed")
+
+	path, err := Cache{Dir: cacheDir, Offline: true}.Fetch("s3://jobs/word-count-1.2.0.jar", dir, countingDownload(t, "word-count-1.2.0.jar", "new", &downloads))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "jar", readTestFile(t, path))
+	assert.Equal(t, 2, downloads)
+	entries, _ := cache.List()
+	assert.Equal(t, 1, len(entries))
+}
+
+/*
+ * Prune
+ */
+func TestCacheShouldEvictTheLeastRecentlyUsedFilesWhenItIsFull(t *testing.T) {
+	cacheDir := createTestDir(t)
+	defer os.RemoveAll(cacheDir)
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	downloads := 0
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := Cache{Dir: cacheDir, MaxSize: 10, now: func() time.Time { return now }}
+
+	cache.Fetch("maven:com.example:a:1.0.0", dir, countingDownload(t, "a.jar", "aaaa", &downloads))
+	now = now.Add(time.Minute)
+	cache.Fetch("maven:com.example:b:1.0.0", dir, countingDownload(t, "b.jar", "bbbb", &downloads))
+	now = now.Add(time.Minute)
+	cache.Fetch("maven:com.example:a:1.0.0", dir, countingDownload(t, "a.jar", "aaaa", &downloads))
+	now = now.Add(time.Minute)
+	cache.Fetch("maven:com.example:c:1.0.0", dir, countingDownload(t, "c.jar", "cccc", &downloads))
+
+	entries, err := cache.List()
+	assert.Nil(t, err)
+	references := []string{}
+	for _, entry := range entries {
+		references = append(references, entry.Reference)
+	}
+	assert.Equal(t, []string{"maven:com.example:c:1.0.0", "maven:com.example:a:1.0.0"}, references)
+	assert.Equal(t, 3, downloads)
+}
+
+func TestCachePruneShouldRemoveAllFiles(t *testing.T) {
+	cacheDir := createTestDir(t)
+	defer os.RemoveAll(cacheDir)
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	downloads := 0
+	cache := Cache{Dir: cacheDir}
+	cache.Fetch("s3://jobs/a.jar", dir, countingDownload(t, "a.jar", "a", &downloads))
+	cache.Fetch("s3://jobs/b.jar", dir, countingDownload(t, "b.jar", "b", &downloads))
+
+	evicted, err := cache.Prune(0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(evicted))
+	entries, _ := cache.List()
+	assert.Equal(t, 0, len(entries))
+	blobs, _ := ioutil.ReadDir(filepath.Join(cacheDir, "blobs", "sha256"))
+	assert.Equal(t, 0, len(blobs))
+}
+
+/*
+ * ParseSize
+ */
+func TestParseSizeShouldParseTheUnits(t *testing.T) {
+	for value, expected := range map[string]int64{
+		"1024":   1024,
+		"500MB":  500 * 1024 * 1024,
+		"2 GiB":  2 * 1024 * 1024 * 1024,
+		"16k":    16 * 1024,
+		"1tb":    1024 * 1024 * 1024 * 1024,
+		"100 b":  100,
+		" 10MB ": 10 * 1024 * 1024,
+	} {
+		size, err := ParseSize(value)
+		assert.Nil(t, err, value)
+		assert.Equal(t, expected, size, value)
+	}
+}
+
+func TestParseSizeShouldReturnAnErrorForAnInvalidSize(t *testing.T) {
+	_, err := ParseSize("lots")
+
+	assert.EqualError(t, err, "invalid size \"lots\", expected a number of bytes with an optional unit, e.g. 500MB or 2GB")
+}
@@ -0,0 +1,301 @@
+package artifact
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// The presets of the authentication of remote JAR files with an API token
+const (
+	PresetGitLab      = "gitlab"
+	PresetGitHub      = "github"
+	PresetArtifactory = "artifactory"
+	PresetBearer      = "bearer"
+)
+
+// Preset returns the authenticator sending the API token the way the
+// service expects it. GitLab, the default, expects it in the PRIVATE-TOKEN
+// header, GitHub as bearer token with a request for the binary content of
+// release assets and Artifactory in the X-JFrog-Art-Api header.
+func Preset(name string, token string) (flink.Authenticator, error) {
+	switch name {
+	case "", PresetGitLab:
+		return flink.HeaderAuthenticator{Headers: map[string]string{"PRIVATE-TOKEN": token}}, nil
+	case PresetGitHub:
+		return flink.Authenticators{
+			flink.BearerTokenAuthenticator{Token: token},
+			flink.HeaderAuthenticator{Headers: map[string]string{"Accept": "application/octet-stream"}},
+		}, nil
+	case PresetArtifactory:
+		return flink.HeaderAuthenticator{Headers: map[string]string{"X-JFrog-Art-Api": token}}, nil
+	case PresetBearer:
+		return flink.BearerTokenAuthenticator{Token: token}, nil
+	}
+	return nil, fmt.Errorf("unknown authentication preset \"%v\", only '%v', '%v', '%v' and '%v' are supported", name, PresetGitLab, PresetGitHub, PresetArtifactory, PresetBearer)
+}
+
+// DefaultTimeout is the default time a download may stall, see HTTP.Timeout
+const DefaultTimeout = time.Minute
+
+// HTTP downloads JAR files over HTTP or HTTPS. The proxy and
+// TLS configuration are part of the transport of the Client.
+type HTTP struct {
+	// Authenticator, when set, authenticates every request
+	Authenticator flink.Authenticator
+	// Netrc is the path of a .netrc file with the credentials per host,
+	// used for the requests the Authenticator doesn't authorize
+	Netrc string
+	// Retries is the number of times a failed download is retried,
+	// continuing where it stopped when the server supports range requests
+	Retries int
+	// RetryWait is the wait before the first retry, which doubles every retry
+	RetryWait time.Duration
+	// Timeout is the maximum time to wait for the response and, while
+	// downloading, for the next bytes of the body. A stalled download fails
+	// and is retried, but a download that makes progress may take longer.
+	// Zero, the default, waits indefinitely.
+	Timeout time.Duration
+	Client  *http.Client
+	// Logger, when set, receives the progress of the downloads
+	Logger *log.Logger
+}
+
+// NewTransport creates the transport of the downloads. Servers are verified
+// with the system roots, to which the certificates of the CA bundle are
+// added when it's set. No client certificate is presented, as artifact
+// repositories aren't part of the Flink cluster.
+func NewTransport(caCertFile string) (*http.Transport, error) {
+	transport := cleanhttp.DefaultPooledTransport()
+	if len(caCertFile) == 0 {
+		return transport, nil
+	}
+
+	caCert, err := ioutil.ReadFile(caCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the CA certificate: %v", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("no PEM encoded certificates found in %v", caCertFile)
+	}
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+
+	return transport, nil
+}
+
+// WithAuthenticator returns a copy of the source which authenticates
+// requests with the authenticator after its own Authenticator
+func (h HTTP) WithAuthenticator(authenticator flink.Authenticator) HTTP {
+	if h.Authenticator != nil {
+		authenticator = flink.Authenticators{h.Authenticator, authenticator}
+	}
+	h.Authenticator = authenticator
+	return h
+}
+
+// Download downloads the file at the URL into the
+// directory and returns the path of the downloaded file
+func (h HTTP) Download(ctx context.Context, rawURL string, dir string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", fmt.Errorf("invalid URL \"%v\", only HTTP and HTTPS are supported", rawURL)
+	}
+
+	filename := path.Base(u.Path)
+	if filename == "." || filename == "/" {
+		filename = "job"
+	}
+	if !strings.HasSuffix(filename, ".jar") {
+		filename += ".jar"
+	}
+	target := filepath.Join(dir, filename)
+
+	out, err := os.Create(target)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	var size int64
+	wait := h.RetryWait
+	for attempt := 0; ; attempt++ {
+		size, err = h.fetch(ctx, u, out, size)
+		if err == nil {
+			break
+		}
+		if responseErr, ok := err.(*ResponseError); ok && responseErr.StatusCode == http.StatusRequestedRangeNotSatisfiable && size > 0 {
+			// Start over when the server rejects the range of the partial download
+			size = 0
+		} else if attempt >= h.Retries || !retryable(err) {
+			os.Remove(target)
+			return "", err
+		}
+
+		logf(h.Logger, "downloading %v failed, retrying from byte %v in %v: %v", u, size, wait, err)
+		select {
+		case <-ctx.Done():
+			os.Remove(target)
+			return "", flink.CategorizedErrorf(flink.ErrCanceled, "downloading %v stopped: %v", u, ctx.Err())
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+
+	logf(h.Logger, "bytes downloaded: %v", size)
+	return target, nil
+}
+
+// fetch requests the file from the offset on with a range request, so a
+// failed download continues where it stopped, writes the response to the
+// file and returns the size of the file
+func (h HTTP) fetch(ctx context.Context, u *url.URL, out *os.File, offset int64) (int64, error) {
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%v-", offset))
+	}
+	err = h.authenticate(req)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stall := newStallTimer(h.Timeout, cancel)
+	defer stall.stop()
+
+	res, err := do(ctx, h.Client, req)
+	if err != nil {
+		return offset, stall.err(u, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusPartialContent {
+		// The server doesn't support range requests and sends the complete file
+		offset = 0
+		err = out.Truncate(0)
+		if err != nil {
+			return 0, err
+		}
+	}
+	_, err = out.Seek(offset, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(out, stall.reader(res.Body))
+	return offset + n, stall.err(u, err)
+}
+
+// stallTimer cancels a download which receives no data for the timeout
+type stallTimer struct {
+	timeout time.Duration
+	timer   *time.Timer
+	stalled int32
+}
+
+// newStallTimer starts the timer, which calls cancel when it expires.
+// A zero timeout never expires.
+func newStallTimer(timeout time.Duration, cancel context.CancelFunc) *stallTimer {
+	s := &stallTimer{timeout: timeout}
+	if timeout > 0 {
+		s.timer = time.AfterFunc(timeout, func() {
+			atomic.StoreInt32(&s.stalled, 1)
+			cancel()
+		})
+	}
+	return s
+}
+
+// reader restarts the timer every time data is read from the reader
+func (s *stallTimer) reader(r io.Reader) io.Reader {
+	if s.timer == nil {
+		return r
+	}
+	return readerFunc(func(p []byte) (int, error) {
+		n, err := r.Read(p)
+		if n > 0 {
+			s.timer.Reset(s.timeout)
+		}
+		return n, err
+	})
+}
+
+// err replaces the error of a request canceled by the timer
+// with a retryable one naming the timeout
+func (s *stallTimer) err(u *url.URL, err error) error {
+	if err == nil || atomic.LoadInt32(&s.stalled) == 0 {
+		return err
+	}
+	return flink.CategorizedErrorf(flink.ErrClusterUnreachable, "no data received from %v for %v", u, s.timeout)
+}
+
+func (s *stallTimer) stop() {
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
+
+// authenticate applies the Authenticator, and the credentials in
+// the .netrc file when the request isn't authorized otherwise
+func (h HTTP) authenticate(req *http.Request) error {
+	if h.Authenticator != nil {
+		err := h.Authenticator.Authenticate(req)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(h.Netrc) == 0 || len(req.Header.Get("Authorization")) > 0 {
+		return nil
+	}
+	login, password, ok, err := netrcCredentials(h.Netrc, req.URL.Hostname())
+	if err != nil {
+		return err
+	}
+	if ok {
+		req.SetBasicAuth(login, password)
+	}
+	return nil
+}
+
+// retryable returns whether a download failing with the error may succeed
+// when it is retried. Client errors, like a missing file, won't.
+func retryable(err error) bool {
+	if responseErr, ok := err.(*ResponseError); ok {
+		return responseErr.StatusCode >= 500 || responseErr.StatusCode == http.StatusTooManyRequests
+	}
+	switch flink.Category(err) {
+	case flink.ErrCanceled, flink.ErrUnauthorized, flink.ErrNotFound:
+		return false
+	}
+	return true
+}
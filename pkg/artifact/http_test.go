@@ -0,0 +1,358 @@
+package artifact
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/stretchr/testify/assert"
+)
+
+// createFlakyServer serves the content, but drops the connection halfway
+// through the first response. Range requests are supported when ranges is set.
+func createFlakyServer(t *testing.T, content string, ranges bool, requests *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.Header.Get("Range"))
+
+		offset := 0
+		if ranges && strings.HasPrefix(r.Header.Get("Range"), "bytes=") {
+			offset, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(r.Header.Get("Range"), "bytes="), "-"))
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %v-%v/%v", offset, len(content)-1, len(content)))
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)-offset))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte(content[offset:]))
+			return
+		}
+
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		if len(*requests) > 1 {
+			w.Write([]byte(content))
+			return
+		}
+
+		// Send half of the content and drop the connection
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		assert.Nil(t, err)
+		fmt.Fprintf(buf, "HTTP/1.1 200 OK\r\nContent-Length: %v\r\n\r\n%v", len(content), content[:len(content)/2])
+		buf.Flush()
+		conn.Close()
+	}))
+}
+
+/*
+ * Preset
+ */
+func TestPresetShouldSendTheTokenTheWayTheServiceExpectsIt(t *testing.T) {
+	for preset, expected := range map[string]map[string]string{
+		"":            {"PRIVATE-TOKEN": "token"},
+		"gitlab":      {"PRIVATE-TOKEN": "token"},
+		"github":      {"Authorization": "Bearer token", "Accept": "application/octet-stream"},
+		"artifactory": {"X-JFrog-Art-Api": "token"},
+		"bearer":      {"Authorization": "Bearer token"},
+	} {
+		authenticator, err := Preset(preset, "token")
+		assert.Nil(t, err)
+
+		req, _ := http.NewRequest("GET", "https://example.com/job.jar", nil)
+		authenticator.Authenticate(req)
+		for name, value := range expected {
+			assert.Equal(t, value, req.Header.Get(name), "preset %v", preset)
+		}
+	}
+}
+
+func TestPresetShouldReturnAnErrorForAnUnknownPreset(t *testing.T) {
+	_, err := Preset("bitbucket", "token")
+
+	assert.EqualError(t, err, "unknown authentication preset \"bitbucket\", only 'gitlab', 'github', 'artifactory' and 'bearer' are supported")
+}
+
+/*
+ * HTTP
+ */
+func TestHTTPDownloadShouldAuthenticateTheRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "token" || r.Header.Get("X-Tenant") != "team" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("jar"))
+	}))
+	defer server.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	source := HTTP{Authenticator: flink.HeaderAuthenticator{Headers: map[string]string{"X-Tenant": "team"}}}
+	gitlab, _ := Preset(PresetGitLab, "token")
+	path, err := source.WithAuthenticator(gitlab).Download(context.Background(), server.URL+"/api/v4/projects/1/jobs/artifacts/master/raw/word-count.jar", dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dir, "word-count.jar"), path)
+	assert.Equal(t, "jar", readTestFile(t, path))
+}
+
+func TestHTTPDownloadShouldUseTheCredentialsOfTheNetrcFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "deployer" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("jar"))
+	}))
+	defer server.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	netrc := filepath.Join(dir, ".netrc")
+	ioutil.WriteFile(netrc, []byte("machine example.com login other password other\nmachine 127.0.0.1\n  login deployer\n  password secret\n"), 0600)
+
+	source := HTTP{Netrc: netrc}
+	path, err := source.Download(context.Background(), server.URL+"/job.jar", dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "jar", readTestFile(t, path))
+}
+
+func TestHTTPDownloadShouldResumeAnInterruptedDownload(t *testing.T) {
+	requests := []string{}
+	server := createFlakyServer(t, "0123456789", true, &requests)
+	defer server.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	source := HTTP{Retries: 2, RetryWait: time.Millisecond}
+	path, err := source.Download(context.Background(), server.URL+"/job.jar", dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "0123456789", readTestFile(t, path))
+	assert.Equal(t, []string{"", "bytes=5-"}, requests)
+}
+
+func TestHTTPDownloadShouldRetryAStalledDownload(t *testing.T) {
+	content := "0123456789"
+	requests := []string{}
+	stop := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Header.Get("Range"))
+		if len(requests) > 1 {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)-5))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte(content[5:]))
+			return
+		}
+
+		// Send half of the content and stall
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.Write([]byte(content[:5]))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-stop:
+		}
+	}))
+	defer server.Close()
+	defer close(stop)
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	source := HTTP{Retries: 2, RetryWait: time.Millisecond, Timeout: 50 * time.Millisecond}
+	path, err := source.Download(context.Background(), server.URL+"/job.jar", dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "0123456789", readTestFile(t, path))
+	assert.Equal(t, []string{"", "bytes=5-"}, requests)
+}
+
+func TestHTTPDownloadShouldFailWhenTheServerDoesNotRespond(t *testing.T) {
+	stop := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-stop:
+		}
+	}))
+	defer server.Close()
+	defer close(stop)
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	source := HTTP{RetryWait: time.Millisecond, Timeout: 50 * time.Millisecond}
+	_, err := source.Download(context.Background(), server.URL+"/job.jar", dir)
+
+	assert.EqualError(t, err, "no data received from "+server.URL+"/job.jar for 50ms")
+	assert.Equal(t, flink.ErrClusterUnreachable, flink.Category(err))
+}
+
+func TestHTTPDownloadShouldStartOverWhenTheServerDoesNotSupportRanges(t *testing.T) {
+	requests := []string{}
+	server := createFlakyServer(t, "0123456789", false, &requests)
+	defer server.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	source := HTTP{Retries: 2, RetryWait: time.Millisecond}
+	path, err := source.Download(context.Background(), server.URL+"/job.jar", dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "0123456789", readTestFile(t, path))
+	assert.Equal(t, 2, len(requests))
+}
+
+func TestHTTPDownloadShouldRetryServerErrors(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	source := HTTP{Retries: 2, RetryWait: time.Millisecond}
+	_, err := source.Download(context.Background(), server.URL+"/job.jar", dir)
+
+	assert.Equal(t, flink.ErrClusterUnreachable, flink.Category(err))
+	assert.Equal(t, 3, attempts)
+	_, err = os.Stat(filepath.Join(dir, "job.jar"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestHTTPDownloadShouldNotRetryAMissingFile(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	source := HTTP{Retries: 2, RetryWait: time.Millisecond}
+	_, err := source.Download(context.Background(), server.URL+"/job.jar", dir)
+
+	assert.Equal(t, flink.ErrNotFound, flink.Category(err))
+	assert.Equal(t, 1, attempts)
+}
+
+func TestHTTPDownloadShouldUseTheProxyOfTheClient(t *testing.T) {
+	proxied := []string{}
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.Write([]byte("jar"))
+	}))
+	defer proxy.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	source := HTTP{Client: &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}}
+	path, err := source.Download(context.Background(), "http://artifacts.example.com/job.jar", dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "jar", readTestFile(t, path))
+	assert.Equal(t, []string{"http://artifacts.example.com/job.jar"}, proxied)
+}
+
+func TestNewTransportShouldAddTheCABundleToTheSystemRoots(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("jar"))
+	}))
+	defer server.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	caCertFile := filepath.Join(dir, "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.Nil(t, ioutil.WriteFile(caCertFile, certificate, 0644))
+
+	transport, err := NewTransport(caCertFile)
+	assert.Nil(t, err)
+	path, err := HTTP{Client: &http.Client{Transport: transport}}.Download(context.Background(), server.URL+"/job.jar", dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "jar", readTestFile(t, path))
+	assert.Empty(t, transport.TLSClientConfig.Certificates)
+}
+
+func TestNewTransportShouldOnlyTrustTheSystemRootsWithoutACABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("jar"))
+	}))
+	defer server.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	transport, err := NewTransport("")
+	assert.Nil(t, err)
+	_, err = HTTP{Client: &http.Client{Transport: transport}}.Download(context.Background(), server.URL+"/job.jar", dir)
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "certificate")
+}
+
+func TestNewTransportShouldReturnAnErrorForAnInvalidCABundle(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	caCertFile := filepath.Join(dir, "ca.pem")
+	assert.Nil(t, ioutil.WriteFile(caCertFile, []byte("not a certificate"), 0644))
+
+	_, err := NewTransport(caCertFile)
+
+	assert.EqualError(t, err, fmt.Sprintf("no PEM encoded certificates found in %v", caCertFile))
+}
+
+/*
+ * netrc
+ */
+func TestParseNetrcShouldSkipMacroDefinitions(t *testing.T) {
+	entries, err := parseNetrc("machine a.example.com login a password x\nmacdef init\ncd /tmp\nput login password\n\ndefault login anonymous password guest\n")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []netrcEntry{
+		{machine: "a.example.com", login: "a", password: "x"},
+		{login: "anonymous", password: "guest", isDefault: true},
+	}, entries)
+}
+
+func TestParseNetrcShouldReturnAnErrorForAMissingValue(t *testing.T) {
+	_, err := parseNetrc("machine a.example.com login")
+
+	assert.EqualError(t, err, "the netrc file misses the value of 'login'")
+}
+
+/*
+ * Sources
+ */
+func TestSourcesShouldFetchThroughTheSupportingSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	sources := Sources{Maven{}, S3{Endpoint: server.URL, PathStyle: true}, HTTP{}}
+	path, err := sources.Fetch(context.Background(), "s3://jobs/job.jar", dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/jobs/job.jar", readTestFile(t, path))
+}
+
+func TestSourcesShouldReturnAnErrorForAnUnsupportedReference(t *testing.T) {
+	sources := Sources{Maven{}, S3{}, HTTP{}}
+
+	_, err := sources.Fetch(context.Background(), "ftp://example.com/job.jar", "/tmp")
+
+	assert.False(t, sources.Supports("ftp://example.com/job.jar"))
+	assert.EqualError(t, err, "unsupported artifact reference \"ftp://example.com/job.jar\"")
+}
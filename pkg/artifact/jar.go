@@ -0,0 +1,171 @@
+package artifact
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+const manifestPath = "META-INF/MANIFEST.MF"
+
+// A JarManifest holds the attributes of the main section of the
+// META-INF/MANIFEST.MF of a JAR file that matter to Flink
+type JarManifest struct {
+	MainClass             string
+	ProgramClass          string
+	ImplementationTitle   string
+	ImplementationVersion string
+}
+
+// A Jar describes the manifest and classes of a JAR file
+type Jar struct {
+	Manifest JarManifest
+	// classes are the class files of the JAR file and of the
+	// JAR files in its lib directory, which Flink adds to the classpath
+	classes map[string]bool
+}
+
+// InspectJar reads the manifest and the classes of the JAR file
+func InspectJar(filename string) (Jar, error) {
+	archive, err := zip.OpenReader(filename)
+	if err != nil {
+		return Jar{}, fmt.Errorf("%v isn't a JAR file: %v", filename, err)
+	}
+	defer archive.Close()
+
+	jar := Jar{classes: map[string]bool{}}
+	for _, file := range archive.File {
+		switch {
+		case file.Name == manifestPath:
+			content, err := readZipFile(file)
+			if err != nil {
+				return Jar{}, fmt.Errorf("unable to read the manifest of %v: %v", filename, err)
+			}
+			jar.Manifest = parseManifest(content)
+		case strings.HasPrefix(file.Name, "lib/") && strings.HasSuffix(file.Name, ".jar"):
+			err := jar.addNestedClasses(file)
+			if err != nil {
+				return Jar{}, fmt.Errorf("unable to read %v in %v: %v", file.Name, filename, err)
+			}
+		default:
+			jar.addClass(file.Name)
+		}
+	}
+	return jar, nil
+}
+
+func (j Jar) addNestedClasses(file *zip.File) error {
+	content, err := readZipFile(file)
+	if err != nil {
+		return err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return err
+	}
+	for _, nested := range archive.File {
+		j.addClass(nested.Name)
+	}
+	return nil
+}
+
+func (j Jar) addClass(name string) {
+	if strings.HasSuffix(name, ".class") {
+		j.classes[strings.TrimSuffix(name, ".class")] = true
+	}
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+// parseManifest parses the main section of a manifest, which ends
+// at the first empty line. Lines starting with a space continue the
+// value of the previous line.
+func parseManifest(content []byte) JarManifest {
+	attributes := map[string]string{}
+	var name string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) == 0 {
+			break
+		}
+		if strings.HasPrefix(line, " ") {
+			attributes[name] += line[1:]
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		name = strings.ToLower(parts[0])
+		attributes[name] = strings.TrimPrefix(parts[1], " ")
+	}
+
+	return JarManifest{
+		MainClass:             strings.TrimSpace(attributes["main-class"]),
+		ProgramClass:          strings.TrimSpace(attributes["program-class"]),
+		ImplementationTitle:   strings.TrimSpace(attributes["implementation-title"]),
+		ImplementationVersion: strings.TrimSpace(attributes["implementation-version"]),
+	}
+}
+
+// HasClass returns whether the JAR file contains the
+// fully qualified class, e.g. com.example.WordCount
+func (j Jar) HasClass(class string) bool {
+	return j.classes[strings.Replace(class, ".", "/", -1)]
+}
+
+// EntryClass returns the class Flink runs when no entry class is
+// specified: the Program-Class of the manifest, or its Main-Class
+func (j Jar) EntryClass() string {
+	if len(j.Manifest.ProgramClass) > 0 {
+		return j.Manifest.ProgramClass
+	}
+	return j.Manifest.MainClass
+}
+
+// ValidateEntryClass checks that the entry class, or the entry class
+// of the manifest when it's empty, exists in the JAR file, and
+// returns the class Flink will run
+func (j Jar) ValidateEntryClass(entryClass string) (string, error) {
+	if len(entryClass) > 0 {
+		if !j.HasClass(entryClass) {
+			return "", fmt.Errorf("the entry class %v doesn't exist in the JAR file", entryClass)
+		}
+		return entryClass, nil
+	}
+
+	attribute := "Program-Class"
+	if len(j.Manifest.ProgramClass) == 0 {
+		attribute = "Main-Class"
+	}
+	class := j.EntryClass()
+	if len(class) == 0 {
+		return "", errors.New("the manifest of the JAR file has neither a Main-Class nor a Program-Class, so the entry class must be specified")
+	}
+	if !j.HasClass(class) {
+		return "", fmt.Errorf("the %v %v of the manifest doesn't exist in the JAR file", attribute, class)
+	}
+	return class, nil
+}
+
+// Version returns the title and implementation version of the
+// manifest, e.g. "word-count 1.2.0", or an empty string without
+// an implementation version
+func (j Jar) Version() string {
+	if len(j.Manifest.ImplementationVersion) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(j.Manifest.ImplementationTitle + " " + j.Manifest.ImplementationVersion)
+}
@@ -0,0 +1,122 @@
+package artifact
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// createTestJar writes a JAR file with the files, mapped to their content
+func createTestJar(t *testing.T, files map[string]string) (string, string) {
+	dir := createTestDir(t)
+	path := filepath.Join(dir, "job.jar")
+	f, err := os.Create(path)
+	assert.Nil(t, err)
+	defer f.Close()
+
+	writeTestZip(t, f, files)
+	return dir, path
+}
+
+func writeTestZip(t *testing.T, f io.Writer, files map[string]string) {
+	archive := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := archive.Create(name)
+		assert.Nil(t, err)
+		w.Write([]byte(content))
+	}
+	assert.Nil(t, archive.Close())
+}
+
+/*
+ * InspectJar
+ */
+func TestInspectJarShouldReadTheManifest(t *testing.T) {
+	dir, path := createTestJar(t, map[string]string{
+		"META-INF/MANIFEST.MF":                  "Manifest-Version: 1.0\r\nMain-Class: com.example.WordCount\r\nImplementation-Title: word-count\r\nImplementation-Version: 1.2.0\r\nX-Long-Attribute: a very long value that conti\r\n nues on the next line\r\n\r\nName: com/example/\r\nImplementation-Version: 0.1\r\n",
+		"com/example/WordCount.class":           "",
+		"com/example/WordCount$Tokenizer.class": "",
+	})
+	defer os.RemoveAll(dir)
+
+	jar, err := InspectJar(path)
+
+	assert.Nil(t, err)
+	assert.Equal(t, JarManifest{MainClass: "com.example.WordCount", ImplementationTitle: "word-count", ImplementationVersion: "1.2.0"}, jar.Manifest)
+	assert.Equal(t, "word-count 1.2.0", jar.Version())
+	assert.True(t, jar.HasClass("com.example.WordCount"))
+	assert.False(t, jar.HasClass("com.example.Tokenizer"))
+}
+
+func TestInspectJarShouldFindTheClassesOfNestedJarFiles(t *testing.T) {
+	nested := bytes.Buffer{}
+	writeTestZip(t, &nested, map[string]string{"com/example/Library.class": ""})
+	dir, path := createTestJar(t, map[string]string{"lib/library.jar": nested.String()})
+	defer os.RemoveAll(dir)
+
+	jar, err := InspectJar(path)
+
+	assert.Nil(t, err)
+	assert.True(t, jar.HasClass("com.example.Library"))
+}
+
+func TestInspectJarShouldReturnAnErrorForAnotherFile(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "job.jar")
+	ioutil.WriteFile(path, []byte("jar"), 0644)
+
+	_, err := InspectJar(path)
+
+	assert.True(t, strings.HasPrefix(err.Error(), path+" isn't a JAR file"), err.Error())
+}
+
+/*
+ * ValidateEntryClass
+ */
+func TestValidateEntryClassShouldPreferTheProgramClass(t *testing.T) {
+	jar := Jar{
+		Manifest: JarManifest{MainClass: "com.example.Main", ProgramClass: "com.example.Program"},
+		classes:  map[string]bool{"com/example/Main": true, "com/example/Program": true},
+	}
+
+	entryClass, err := jar.ValidateEntryClass("")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "com.example.Program", entryClass)
+}
+
+func TestValidateEntryClassShouldRejectAMissingEntryClass(t *testing.T) {
+	jar := Jar{
+		Manifest: JarManifest{MainClass: "com.example.Main"},
+		classes:  map[string]bool{"com/example/Main": true},
+	}
+
+	_, err := jar.ValidateEntryClass("com.example.WordCount")
+
+	assert.EqualError(t, err, "the entry class com.example.WordCount doesn't exist in the JAR file")
+}
+
+func TestValidateEntryClassShouldRejectAMissingMainClass(t *testing.T) {
+	jar := Jar{
+		Manifest: JarManifest{MainClass: "com.example.Main"},
+		classes:  map[string]bool{},
+	}
+
+	_, err := jar.ValidateEntryClass("")
+
+	assert.EqualError(t, err, "the Main-Class com.example.Main of the manifest doesn't exist in the JAR file")
+}
+
+func TestValidateEntryClassShouldRequireAnEntryClassWithoutManifest(t *testing.T) {
+	_, err := Jar{classes: map[string]bool{}}.ValidateEntryClass("")
+
+	assert.EqualError(t, err, "the manifest of the JAR file has neither a Main-Class nor a Program-Class, so the entry class must be specified")
+}
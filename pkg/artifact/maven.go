@@ -0,0 +1,273 @@
+package artifact
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// MavenCentral is the URL of the Maven Central repository
+const MavenCentral = "https://repo.maven.apache.org/maven2"
+
+// MavenPrefix is the prefix of artifact references to Maven coordinates
+const MavenPrefix = "maven:"
+
+// A MavenRepository is a Maven repository, e.g. hosted by Nexus or
+// Artifactory, with the credentials for basic authentication when required
+type MavenRepository struct {
+	URL      string
+	Username string
+	Password string
+}
+
+// MavenCoordinates identify an artifact in a Maven repository. The version
+// is either a release, a SNAPSHOT version, LATEST or RELEASE.
+type MavenCoordinates struct {
+	GroupID    string
+	ArtifactID string
+	Version    string
+	Classifier string
+}
+
+// ParseMavenCoordinates parses a reference in the format
+// maven:groupId:artifactId:version[:classifier]
+func ParseMavenCoordinates(reference string) (MavenCoordinates, error) {
+	if !strings.HasPrefix(reference, MavenPrefix) {
+		return MavenCoordinates{}, fmt.Errorf("Maven coordinates \"%v\" don't start with \"%v\"", reference, MavenPrefix)
+	}
+
+	parts := strings.Split(strings.TrimPrefix(reference, MavenPrefix), ":")
+	if len(parts) < 3 || len(parts) > 4 {
+		return MavenCoordinates{}, fmt.Errorf("Maven coordinates \"%v\" don't match the format maven:groupId:artifactId:version[:classifier]", reference)
+	}
+	for _, part := range parts {
+		if len(part) == 0 {
+			return MavenCoordinates{}, fmt.Errorf("Maven coordinates \"%v\" contain an empty part", reference)
+		}
+	}
+
+	coordinates := MavenCoordinates{
+		GroupID:    parts[0],
+		ArtifactID: parts[1],
+		Version:    parts[2],
+	}
+	if len(parts) == 4 {
+		coordinates.Classifier = parts[3]
+	}
+	return coordinates, nil
+}
+
+func (c MavenCoordinates) String() string {
+	s := fmt.Sprintf("%v:%v:%v", c.GroupID, c.ArtifactID, c.Version)
+	if len(c.Classifier) > 0 {
+		s += ":" + c.Classifier
+	}
+	return s
+}
+
+// artifactPath returns the path of the directory of the
+// artifact, relative to the URL of the repository
+func (c MavenCoordinates) artifactPath() string {
+	return strings.Replace(c.GroupID, ".", "/", -1) + "/" + c.ArtifactID
+}
+
+// Maven downloads JAR files from Maven repositories
+type Maven struct {
+	Repositories []MavenRepository
+	Client       *http.Client
+	// Logger, when set, receives the progress of the downloads
+	Logger *log.Logger
+}
+
+type mavenSnapshotVersion struct {
+	Classifier string `xml:"classifier"`
+	Extension  string `xml:"extension"`
+	Value      string `xml:"value"`
+}
+
+type mavenMetadata struct {
+	Versioning struct {
+		Latest   string   `xml:"latest"`
+		Release  string   `xml:"release"`
+		Versions []string `xml:"versions>version"`
+		Snapshot struct {
+			Timestamp   string `xml:"timestamp"`
+			BuildNumber int    `xml:"buildNumber"`
+		} `xml:"snapshot"`
+		SnapshotVersions []mavenSnapshotVersion `xml:"snapshotVersions>snapshotVersion"`
+	} `xml:"versioning"`
+}
+
+// Download resolves the coordinates against the repositories in order,
+// downloads the JAR file into the directory and verifies its checksum.
+// It returns the path of the downloaded JAR file.
+func (m Maven) Download(ctx context.Context, coordinates MavenCoordinates, dir string) (string, error) {
+	if len(m.Repositories) == 0 {
+		return "", fmt.Errorf("no Maven repositories configured to resolve %v", coordinates)
+	}
+
+	for _, repository := range m.Repositories {
+		path, err := m.downloadFrom(ctx, repository, coordinates, dir)
+		if flink.Category(err) == flink.ErrNotFound {
+			logf(m.Logger, "%v not found in Maven repository %v", coordinates, repository.URL)
+			continue
+		}
+		return path, err
+	}
+	return "", flink.CategorizedErrorf(flink.ErrNotFound, "%v not found in any of the Maven repositories", coordinates)
+}
+
+func (m Maven) downloadFrom(ctx context.Context, repository MavenRepository, coordinates MavenCoordinates, dir string) (string, error) {
+	baseURL := strings.TrimRight(repository.URL, "/") + "/" + coordinates.artifactPath()
+
+	version := coordinates.Version
+	if version == "LATEST" || version == "RELEASE" {
+		metadata, err := m.metadata(ctx, repository, baseURL+"/maven-metadata.xml")
+		if err != nil {
+			return "", err
+		}
+		version, err = resolveVersion(metadata, coordinates.Version)
+		if err != nil {
+			return "", err
+		}
+		logf(m.Logger, "resolved %v to version %v", coordinates, version)
+	}
+
+	fileVersion := version
+	if strings.HasSuffix(version, "-SNAPSHOT") {
+		metadata, err := m.metadata(ctx, repository, baseURL+"/"+version+"/maven-metadata.xml")
+		if err != nil && flink.Category(err) != flink.ErrNotFound {
+			return "", err
+		}
+		if err == nil {
+			fileVersion = resolveSnapshotVersion(metadata, version, coordinates.Classifier)
+			logf(m.Logger, "resolved %v to snapshot %v", coordinates, fileVersion)
+		}
+	}
+
+	filename := coordinates.ArtifactID + "-" + fileVersion
+	if len(coordinates.Classifier) > 0 {
+		filename += "-" + coordinates.Classifier
+	}
+	filename += ".jar"
+	fileURL := baseURL + "/" + version + "/" + filename
+
+	res, err := get(ctx, m.Client, fileURL, repository.Username, repository.Password)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	sha256Hash := sha256.New()
+	sha1Hash := sha1.New()
+	path := filepath.Join(dir, filename)
+	n, err := writeFile(path, io.TeeReader(res.Body, io.MultiWriter(sha256Hash, sha1Hash)))
+	if err != nil {
+		return "", err
+	}
+
+	err = m.verifyChecksum(ctx, repository, fileURL, map[string]hash.Hash{".sha256": sha256Hash, ".sha1": sha1Hash})
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+
+	logf(m.Logger, "downloaded %v (%v bytes) from %v", coordinates, n, fileURL)
+	return path, nil
+}
+
+func (m Maven) metadata(ctx context.Context, repository MavenRepository, url string) (mavenMetadata, error) {
+	res, err := get(ctx, m.Client, url, repository.Username, repository.Password)
+	if err != nil {
+		return mavenMetadata{}, err
+	}
+	defer res.Body.Close()
+
+	metadata := mavenMetadata{}
+	err = xml.NewDecoder(res.Body).Decode(&metadata)
+	if err != nil {
+		return mavenMetadata{}, fmt.Errorf("unable to parse %v: %v", url, err)
+	}
+	return metadata, nil
+}
+
+// verifyChecksum verifies the hash of the downloaded file against the published
+// SHA-256 checksum, falling back to the SHA-1 checksum when there is none
+func (m Maven) verifyChecksum(ctx context.Context, repository MavenRepository, fileURL string, hashes map[string]hash.Hash) error {
+	for _, extension := range []string{".sha256", ".sha1"} {
+		res, err := get(ctx, m.Client, fileURL+extension, repository.Username, repository.Password)
+		if flink.Category(err) == flink.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		content, err := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		res.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		// Checksum files may contain the file name after the checksum
+		fields := strings.Fields(string(content[:]))
+		if len(fields) == 0 {
+			return fmt.Errorf("empty checksum file %v", fileURL+extension)
+		}
+
+		expected := strings.ToLower(fields[0])
+		actual := hex.EncodeToString(hashes[extension].Sum(nil))
+		if actual != expected {
+			return fmt.Errorf("checksum mismatch for %v: %v expected %v but the download has %v", fileURL, extension, expected, actual)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("no .sha256 or .sha1 checksum published for %v", fileURL)
+}
+
+// resolveVersion resolves LATEST or RELEASE to a version using the metadata
+func resolveVersion(metadata mavenMetadata, version string) (string, error) {
+	versioning := metadata.Versioning
+
+	resolved := versioning.Release
+	if version == "LATEST" {
+		resolved = versioning.Latest
+		if len(resolved) == 0 && len(versioning.Versions) > 0 {
+			resolved = versioning.Versions[len(versioning.Versions)-1]
+		}
+	}
+	if len(resolved) == 0 {
+		return "", flink.CategorizedErrorf(flink.ErrNotFound, "the Maven metadata doesn't contain a %v version", version)
+	}
+	return resolved, nil
+}
+
+// resolveSnapshotVersion resolves a SNAPSHOT version to the timestamped
+// version of the latest snapshot JAR file using the metadata
+func resolveSnapshotVersion(metadata mavenMetadata, version string, classifier string) string {
+	for _, snapshotVersion := range metadata.Versioning.SnapshotVersions {
+		if snapshotVersion.Extension == "jar" && snapshotVersion.Classifier == classifier {
+			return snapshotVersion.Value
+		}
+	}
+
+	snapshot := metadata.Versioning.Snapshot
+	if len(snapshot.Timestamp) == 0 {
+		return version
+	}
+	return fmt.Sprintf("%v-%v-%v", strings.TrimSuffix(version, "-SNAPSHOT"), snapshot.Timestamp, snapshot.BuildNumber)
+}
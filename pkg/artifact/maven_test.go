@@ -0,0 +1,271 @@
+package artifact
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/stretchr/testify/assert"
+)
+
+func sha1Hex(content string) string {
+	sum := sha1.Sum([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// createTestRepository serves the files by path, and
+// requires basic authentication when a username is set
+func createTestRepository(files map[string]string, username string, password string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(username) > 0 {
+			u, p, ok := r.BasicAuth()
+			if !ok || u != username || p != password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		content, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+}
+
+func createTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "artifact")
+	assert.Nil(t, err)
+	return dir
+}
+
+func readTestFile(t *testing.T, path string) string {
+	content, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	return string(content[:])
+}
+
+/*
+ * ParseMavenCoordinates
+ */
+func TestParseMavenCoordinatesShouldParseTheCoordinates(t *testing.T) {
+	coordinates, err := ParseMavenCoordinates("maven:com.example:word-count:1.2.0")
+
+	assert.Nil(t, err)
+	assert.Equal(t, MavenCoordinates{GroupID: "com.example", ArtifactID: "word-count", Version: "1.2.0"}, coordinates)
+}
+
+func TestParseMavenCoordinatesShouldParseTheClassifier(t *testing.T) {
+	coordinates, err := ParseMavenCoordinates("maven:com.example:word-count:1.2.0:all")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "all", coordinates.Classifier)
+	assert.Equal(t, "com.example:word-count:1.2.0:all", coordinates.String())
+}
+
+func TestParseMavenCoordinatesShouldReturnAnErrorForMissingParts(t *testing.T) {
+	_, err := ParseMavenCoordinates("maven:com.example:word-count")
+
+	assert.EqualError(t, err, "Maven coordinates \"maven:com.example:word-count\" don't match the format maven:groupId:artifactId:version[:classifier]")
+}
+
+func TestParseMavenCoordinatesShouldReturnAnErrorForEmptyParts(t *testing.T) {
+	_, err := ParseMavenCoordinates("maven:com.example::1.0")
+
+	assert.EqualError(t, err, "Maven coordinates \"maven:com.example::1.0\" contain an empty part")
+}
+
+/*
+ * Download
+ */
+func TestDownloadShouldDownloadAReleaseAndVerifyTheSHA1Checksum(t *testing.T) {
+	repository := createTestRepository(map[string]string{
+		"/com/example/word-count/1.2.0/word-count-1.2.0.jar":      "jar",
+		"/com/example/word-count/1.2.0/word-count-1.2.0.jar.sha1": sha1Hex("jar"),
+	}, "", "")
+	defer repository.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	maven := Maven{Repositories: []MavenRepository{{URL: repository.URL}}}
+	path, err := maven.Download(context.Background(), MavenCoordinates{GroupID: "com.example", ArtifactID: "word-count", Version: "1.2.0"}, dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dir, "word-count-1.2.0.jar"), path)
+	assert.Equal(t, "jar", readTestFile(t, path))
+}
+
+func TestDownloadShouldPreferTheSHA256Checksum(t *testing.T) {
+	repository := createTestRepository(map[string]string{
+		"/com/example/word-count/1.2.0/word-count-1.2.0-all.jar":        "jar",
+		"/com/example/word-count/1.2.0/word-count-1.2.0-all.jar.sha256": sha256Hex("jar") + "  word-count-1.2.0-all.jar\n",
+		"/com/example/word-count/1.2.0/word-count-1.2.0-all.jar.sha1":   "invalid",
+	}, "", "")
+	defer repository.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	maven := Maven{Repositories: []MavenRepository{{URL: repository.URL}}}
+	path, err := maven.Download(context.Background(), MavenCoordinates{GroupID: "com.example", ArtifactID: "word-count", Version: "1.2.0", Classifier: "all"}, dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dir, "word-count-1.2.0-all.jar"), path)
+}
+
+func TestDownloadShouldReturnAnErrorWhenTheChecksumDoesNotMatch(t *testing.T) {
+	repository := createTestRepository(map[string]string{
+		"/com/example/word-count/1.2.0/word-count-1.2.0.jar":      "tampered",
+		"/com/example/word-count/1.2.0/word-count-1.2.0.jar.sha1": sha1Hex("jar"),
+	}, "", "")
+	defer repository.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	maven := Maven{Repositories: []MavenRepository{{URL: repository.URL}}}
+	_, err := maven.Download(context.Background(), MavenCoordinates{GroupID: "com.example", ArtifactID: "word-count", Version: "1.2.0"}, dir)
+
+	assert.Contains(t, err.Error(), "checksum mismatch")
+	_, err = os.Stat(filepath.Join(dir, "word-count-1.2.0.jar"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDownloadShouldReturnAnErrorWithoutAChecksum(t *testing.T) {
+	repository := createTestRepository(map[string]string{
+		"/com/example/word-count/1.2.0/word-count-1.2.0.jar": "jar",
+	}, "", "")
+	defer repository.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	maven := Maven{Repositories: []MavenRepository{{URL: repository.URL}}}
+	_, err := maven.Download(context.Background(), MavenCoordinates{GroupID: "com.example", ArtifactID: "word-count", Version: "1.2.0"}, dir)
+
+	assert.EqualError(t, err, "no .sha256 or .sha1 checksum published for "+repository.URL+"/com/example/word-count/1.2.0/word-count-1.2.0.jar")
+}
+
+func TestDownloadShouldResolveTheLatestAndReleaseVersions(t *testing.T) {
+	repository := createTestRepository(map[string]string{
+		"/com/example/word-count/maven-metadata.xml": `<metadata>
+  <versioning>
+    <latest>1.3.0-SNAPSHOT</latest>
+    <release>1.2.0</release>
+  </versioning>
+</metadata>`,
+		"/com/example/word-count/1.2.0/word-count-1.2.0.jar":                        "release",
+		"/com/example/word-count/1.2.0/word-count-1.2.0.jar.sha1":                   sha1Hex("release"),
+		"/com/example/word-count/1.3.0-SNAPSHOT/word-count-1.3.0-SNAPSHOT.jar":      "latest",
+		"/com/example/word-count/1.3.0-SNAPSHOT/word-count-1.3.0-SNAPSHOT.jar.sha1": sha1Hex("latest"),
+	}, "", "")
+	defer repository.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	maven := Maven{Repositories: []MavenRepository{{URL: repository.URL}}}
+
+	path, err := maven.Download(context.Background(), MavenCoordinates{GroupID: "com.example", ArtifactID: "word-count", Version: "RELEASE"}, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, "release", readTestFile(t, path))
+
+	path, err = maven.Download(context.Background(), MavenCoordinates{GroupID: "com.example", ArtifactID: "word-count", Version: "LATEST"}, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, "latest", readTestFile(t, path))
+}
+
+func TestDownloadShouldResolveTheTimestampOfASnapshot(t *testing.T) {
+	repository := createTestRepository(map[string]string{
+		"/com/example/word-count/1.3.0-SNAPSHOT/maven-metadata.xml": `<metadata>
+  <versioning>
+    <snapshot>
+      <timestamp>20190101.120000</timestamp>
+      <buildNumber>7</buildNumber>
+    </snapshot>
+    <snapshotVersions>
+      <snapshotVersion>
+        <extension>pom</extension>
+        <value>1.3.0-20190101.120000-7</value>
+      </snapshotVersion>
+      <snapshotVersion>
+        <classifier>all</classifier>
+        <extension>jar</extension>
+        <value>1.3.0-20190101.115500-6</value>
+      </snapshotVersion>
+    </snapshotVersions>
+  </versioning>
+</metadata>`,
+		"/com/example/word-count/1.3.0-SNAPSHOT/word-count-1.3.0-20190101.120000-7.jar":          "snapshot",
+		"/com/example/word-count/1.3.0-SNAPSHOT/word-count-1.3.0-20190101.120000-7.jar.sha1":     sha1Hex("snapshot"),
+		"/com/example/word-count/1.3.0-SNAPSHOT/word-count-1.3.0-20190101.115500-6-all.jar":      "snapshot-all",
+		"/com/example/word-count/1.3.0-SNAPSHOT/word-count-1.3.0-20190101.115500-6-all.jar.sha1": sha1Hex("snapshot-all"),
+	}, "", "")
+	defer repository.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	maven := Maven{Repositories: []MavenRepository{{URL: repository.URL}}}
+
+	path, err := maven.Download(context.Background(), MavenCoordinates{GroupID: "com.example", ArtifactID: "word-count", Version: "1.3.0-SNAPSHOT"}, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, "snapshot", readTestFile(t, path))
+
+	path, err = maven.Download(context.Background(), MavenCoordinates{GroupID: "com.example", ArtifactID: "word-count", Version: "1.3.0-SNAPSHOT", Classifier: "all"}, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, "snapshot-all", readTestFile(t, path))
+}
+
+func TestDownloadShouldTryTheNextRepositoryWhenTheArtifactIsNotFound(t *testing.T) {
+	empty := createTestRepository(map[string]string{}, "", "")
+	defer empty.Close()
+	repository := createTestRepository(map[string]string{
+		"/com/example/word-count/1.2.0/word-count-1.2.0.jar":      "jar",
+		"/com/example/word-count/1.2.0/word-count-1.2.0.jar.sha1": sha1Hex("jar"),
+	}, "deployer", "secret")
+	defer repository.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	maven := Maven{Repositories: []MavenRepository{
+		{URL: empty.URL},
+		{URL: repository.URL, Username: "deployer", Password: "secret"},
+	}}
+	path, err := maven.Download(context.Background(), MavenCoordinates{GroupID: "com.example", ArtifactID: "word-count", Version: "1.2.0"}, dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "jar", readTestFile(t, path))
+}
+
+func TestDownloadShouldReturnNotFoundWhenNoRepositoryHasTheArtifact(t *testing.T) {
+	empty := createTestRepository(map[string]string{}, "", "")
+	defer empty.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	maven := Maven{Repositories: []MavenRepository{{URL: empty.URL}}}
+	_, err := maven.Download(context.Background(), MavenCoordinates{GroupID: "com.example", ArtifactID: "word-count", Version: "1.2.0"}, dir)
+
+	assert.EqualError(t, err, "com.example:word-count:1.2.0 not found in any of the Maven repositories")
+	assert.Equal(t, flink.ErrNotFound, flink.Category(err))
+}
+
+func TestDownloadShouldReturnUnauthorizedWithInvalidCredentials(t *testing.T) {
+	repository := createTestRepository(map[string]string{}, "deployer", "secret")
+	defer repository.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	maven := Maven{Repositories: []MavenRepository{{URL: repository.URL, Username: "deployer", Password: "wrong"}}}
+	_, err := maven.Download(context.Background(), MavenCoordinates{GroupID: "com.example", ArtifactID: "word-count", Version: "1.2.0"}, dir)
+
+	assert.Equal(t, flink.ErrUnauthorized, flink.Category(err))
+}
@@ -0,0 +1,96 @@
+package artifact
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// A netrcEntry holds the credentials of a machine in a .netrc file
+type netrcEntry struct {
+	machine   string
+	login     string
+	password  string
+	isDefault bool
+}
+
+// parseNetrc parses the entries of a .netrc file, skipping macro definitions
+func parseNetrc(content string) ([]netrcEntry, error) {
+	entries := []netrcEntry{}
+	var entry *netrcEntry
+	key := ""
+	inMacro := false
+
+	for _, line := range strings.Split(content, "\n") {
+		if inMacro {
+			// A macro definition ends at the first empty line
+			inMacro = len(strings.TrimSpace(line)) > 0
+			continue
+		}
+
+		for _, token := range strings.Fields(line) {
+			if len(key) > 0 {
+				if entry == nil {
+					return nil, fmt.Errorf("the netrc file contains '%v' outside of a machine entry", key)
+				}
+				switch key {
+				case "machine":
+					entry.machine = token
+				case "login":
+					entry.login = token
+				case "password":
+					entry.password = token
+				}
+				key = ""
+				continue
+			}
+
+			switch token {
+			case "machine", "default":
+				entries = append(entries, netrcEntry{isDefault: token == "default"})
+				entry = &entries[len(entries)-1]
+				if token == "machine" {
+					key = token
+				}
+			case "login", "password", "account":
+				key = token
+			case "macdef":
+				inMacro = true
+			default:
+				return nil, fmt.Errorf("the netrc file contains the unknown token '%v'", token)
+			}
+			if inMacro {
+				break
+			}
+		}
+	}
+	if len(key) > 0 {
+		return nil, fmt.Errorf("the netrc file misses the value of '%v'", key)
+	}
+	return entries, nil
+}
+
+// netrcCredentials returns the login and password of the host in
+// the .netrc file at the path, or the ones of the default entry
+func netrcCredentials(path string, host string) (string, string, bool, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", "", false, fmt.Errorf("unable to read the netrc file: %v", err)
+	}
+	entries, err := parseNetrc(string(content[:]))
+	if err != nil {
+		return "", "", false, err
+	}
+
+	for _, entry := range entries {
+		if !entry.isDefault && entry.machine == host {
+			return entry.login, entry.password, true, nil
+		}
+	}
+	for _, entry := range entries {
+		if entry.isDefault {
+			return entry.login, entry.password, true, nil
+		}
+	}
+	return "", "", false, nil
+}
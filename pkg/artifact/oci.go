@@ -0,0 +1,412 @@
+package artifact
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// OCIPrefix is the prefix of artifact references to OCI registries
+const OCIPrefix = "oci://"
+
+// The media types of manifests
+const (
+	MediaTypeOCIManifest    = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeOCIIndex       = "application/vnd.oci.image.index.v1+json"
+	MediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeDockerList     = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+// DefaultJarMediaTypes are the media types of the layers containing JAR files
+var DefaultJarMediaTypes = []string{"application/java-archive", "application/x-java-archive"}
+
+// annotationTitle is the annotation with the file name of a layer
+const annotationTitle = "org.opencontainers.image.title"
+
+var digestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// An OCIReference identifies an artifact in an OCI registry by tag or digest
+type OCIReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseOCIReference parses a reference in the format
+// oci://registry/repository[:tag][@digest], where the tag defaults to latest
+func ParseOCIReference(reference string) (OCIReference, error) {
+	if !strings.HasPrefix(reference, OCIPrefix) {
+		return OCIReference{}, fmt.Errorf("OCI reference \"%v\" doesn't start with \"%v\"", reference, OCIPrefix)
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(reference, OCIPrefix), "/", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return OCIReference{}, fmt.Errorf("OCI reference \"%v\" doesn't match the format oci://registry/repository[:tag][@digest]", reference)
+	}
+	ref := OCIReference{Registry: parts[0], Repository: parts[1]}
+
+	if i := strings.Index(ref.Repository, "@"); i >= 0 {
+		ref.Digest = ref.Repository[i+1:]
+		ref.Repository = ref.Repository[:i]
+		if !digestPattern.MatchString(ref.Digest) {
+			return OCIReference{}, fmt.Errorf("OCI reference \"%v\" contains the invalid digest \"%v\", only sha256 digests are supported", reference, ref.Digest)
+		}
+	}
+	if i := strings.LastIndex(ref.Repository, ":"); i > strings.LastIndex(ref.Repository, "/") {
+		ref.Tag = ref.Repository[i+1:]
+		ref.Repository = ref.Repository[:i]
+	}
+	if len(ref.Tag) == 0 && len(ref.Digest) == 0 {
+		ref.Tag = "latest"
+	}
+	if len(ref.Repository) == 0 {
+		return OCIReference{}, fmt.Errorf("OCI reference \"%v\" doesn't contain a repository", reference)
+	}
+	return ref, nil
+}
+
+func (r OCIReference) String() string {
+	s := OCIPrefix + r.Registry + "/" + r.Repository
+	if len(r.Tag) > 0 {
+		s += ":" + r.Tag
+	}
+	if len(r.Digest) > 0 {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// OCI downloads JAR files stored as layers of artifacts in OCI registries,
+// e.g. pushed with `oras push registry/repository:tag job.jar:application/java-archive`
+type OCI struct {
+	// DockerConfig is the path of the Docker config file with the
+	// credentials of the registries, e.g. ~/.docker/config.json
+	DockerConfig string
+	// PlainHTTP connects to the registries over HTTP rather than HTTPS
+	PlainHTTP bool
+	// JarMediaTypes are the media types of the layers containing
+	// JAR files, which default to the DefaultJarMediaTypes
+	JarMediaTypes []string
+	Client        *http.Client
+	// Logger, when set, receives the progress of the downloads
+	Logger *log.Logger
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Layers    []ociDescriptor `json:"layers"`
+	Manifests []ociDescriptor `json:"manifests"`
+}
+
+// ociSession sends the requests to a repository, authenticating
+// with the token of the registry once it has challenged a request
+type ociSession struct {
+	oci           OCI
+	baseURL       string
+	reference     OCIReference
+	authorization string
+}
+
+// Download resolves the manifest of the reference, downloads the JAR
+// layer into the directory and verifies the digests of the manifest and
+// the layer. It returns the path of the downloaded JAR file.
+func (o OCI) Download(ctx context.Context, reference OCIReference, dir string) (string, error) {
+	scheme := "https"
+	if o.PlainHTTP {
+		scheme = "http"
+	}
+	registry := reference.Registry
+	if registry == "docker.io" {
+		registry = "registry-1.docker.io"
+	}
+	session := &ociSession{
+		oci:       o,
+		baseURL:   scheme + "://" + registry + "/v2/" + reference.Repository,
+		reference: reference,
+	}
+
+	manifestReference := reference.Digest
+	if len(manifestReference) == 0 {
+		manifestReference = reference.Tag
+	}
+	manifest, digest, err := session.manifest(ctx, manifestReference, reference.Digest)
+	if err != nil {
+		return "", err
+	}
+
+	// Artifacts pushed for multiple platforms list their manifests in an index
+	if len(manifest.Manifests) > 0 {
+		manifest, _, err = session.manifest(ctx, manifest.Manifests[0].Digest, manifest.Manifests[0].Digest)
+		if err != nil {
+			return "", err
+		}
+	}
+	logf(o.Logger, "resolved %v to digest %v", reference, digest)
+
+	layer, err := o.jarLayer(reference, manifest)
+	if err != nil {
+		return "", err
+	}
+	return session.blob(ctx, layer, dir)
+}
+
+// jarLayer returns the layer with one of the JAR media types, or the
+// single layer with the file name of a JAR file, as pushed by ORAS
+// without specifying the media type
+func (o OCI) jarLayer(reference OCIReference, manifest ociManifest) (ociDescriptor, error) {
+	mediaTypes := o.JarMediaTypes
+	if len(mediaTypes) == 0 {
+		mediaTypes = DefaultJarMediaTypes
+	}
+	for _, layer := range manifest.Layers {
+		for _, mediaType := range mediaTypes {
+			if layer.MediaType == mediaType {
+				return layer, nil
+			}
+		}
+	}
+
+	if len(manifest.Layers) == 1 && strings.HasSuffix(manifest.Layers[0].Annotations[annotationTitle], ".jar") {
+		return manifest.Layers[0], nil
+	}
+	return ociDescriptor{}, fmt.Errorf("%v doesn't contain a layer with one of the media types %v", reference, strings.Join(mediaTypes, ", "))
+}
+
+// manifest retrieves the manifest, verifying its digest when it's expected,
+// and returns the manifest and its digest
+func (s *ociSession) manifest(ctx context.Context, manifestReference string, expectedDigest string) (ociManifest, string, error) {
+	res, err := s.get(ctx, s.baseURL+"/manifests/"+manifestReference, strings.Join([]string{MediaTypeOCIManifest, MediaTypeOCIIndex, MediaTypeDockerManifest, MediaTypeDockerList}, ", "))
+	if err != nil {
+		return ociManifest{}, "", err
+	}
+	defer res.Body.Close()
+
+	content, err := ioutil.ReadAll(io.LimitReader(res.Body, 4*1024*1024))
+	if err != nil {
+		return ociManifest{}, "", err
+	}
+	sum := sha256.Sum256(content)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	if len(expectedDigest) > 0 && digest != expectedDigest {
+		return ociManifest{}, "", fmt.Errorf("digest mismatch for the manifest of %v: expected %v but the registry returned %v", s.reference, expectedDigest, digest)
+	}
+
+	manifest := ociManifest{}
+	err = json.Unmarshal(content, &manifest)
+	if err != nil {
+		return ociManifest{}, "", fmt.Errorf("unable to parse the manifest of %v: %v", s.reference, err)
+	}
+	return manifest, digest, nil
+}
+
+// blob downloads the layer into the directory while verifying its digest
+func (s *ociSession) blob(ctx context.Context, layer ociDescriptor, dir string) (string, error) {
+	if !digestPattern.MatchString(layer.Digest) {
+		return "", fmt.Errorf("the JAR layer of %v has the unsupported digest \"%v\"", s.reference, layer.Digest)
+	}
+
+	res, err := s.get(ctx, s.baseURL+"/blobs/"+layer.Digest, "")
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	filename := path.Base(layer.Annotations[annotationTitle])
+	if filename == "." || filename == "/" {
+		filename = path.Base(s.reference.Repository)
+	}
+	if !strings.HasSuffix(filename, ".jar") {
+		filename += ".jar"
+	}
+	target := filepath.Join(dir, filename)
+
+	hash := sha256.New()
+	n, err := writeFile(target, io.TeeReader(res.Body, hash))
+	if err != nil {
+		return "", err
+	}
+	digest := "sha256:" + hex.EncodeToString(hash.Sum(nil))
+	if digest != layer.Digest || (layer.Size > 0 && n != layer.Size) {
+		os.Remove(target)
+		return "", fmt.Errorf("digest mismatch for the JAR layer of %v: expected %v (%v bytes) but the download has %v (%v bytes)", s.reference, layer.Digest, layer.Size, digest, n)
+	}
+
+	logf(s.oci.Logger, "downloaded %v (%v bytes)", s.reference, n)
+	return target, nil
+}
+
+// get sends a GET request, and authenticates it again when the
+// registry challenges it for the credentials of the Docker config
+func (s *ociSession) get(ctx context.Context, rawURL string, accept string) (*http.Response, error) {
+	send := func() (*http.Response, error) {
+		req, err := http.NewRequest("GET", rawURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(accept) > 0 {
+			req.Header.Set("Accept", accept)
+		}
+		if len(s.authorization) > 0 {
+			req.Header.Set("Authorization", s.authorization)
+		}
+		return do(ctx, s.oci.Client, req)
+	}
+
+	res, err := send()
+	responseErr, ok := err.(*ResponseError)
+	if !ok || responseErr.StatusCode != http.StatusUnauthorized || len(s.authorization) > 0 {
+		return res, err
+	}
+
+	err = s.authenticate(ctx, responseErr.Challenge)
+	if err != nil {
+		return nil, err
+	}
+	return send()
+}
+
+// authenticate answers the challenge of the registry, either with
+// basic authentication or with a bearer token of its token service
+func (s *ociSession) authenticate(ctx context.Context, challenge string) error {
+	username, password, err := dockerCredentials(s.oci.DockerConfig, s.reference.Registry)
+	if err != nil {
+		return err
+	}
+
+	scheme, params := parseChallenge(challenge)
+	switch scheme {
+	case "basic":
+		if len(username) == 0 {
+			return flink.CategorizedErrorf(flink.ErrUnauthorized, "the registry %v requires credentials, but the Docker config has none", s.reference.Registry)
+		}
+		s.authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+		return nil
+	case "bearer":
+	default:
+		return flink.CategorizedErrorf(flink.ErrUnauthorized, "the registry %v requires the unsupported authentication \"%v\"", s.reference.Registry, challenge)
+	}
+
+	tokenURL, err := url.Parse(params["realm"])
+	if err != nil || len(tokenURL.Host) == 0 {
+		return fmt.Errorf("the registry %v challenged with the invalid realm \"%v\"", s.reference.Registry, params["realm"])
+	}
+	query := tokenURL.Query()
+	if len(params["service"]) > 0 {
+		query.Set("service", params["service"])
+	}
+	scope := params["scope"]
+	if len(scope) == 0 {
+		scope = "repository:" + s.reference.Repository + ":pull"
+	}
+	query.Set("scope", scope)
+	tokenURL.RawQuery = query.Encode()
+
+	res, err := get(ctx, s.oci.Client, tokenURL.String(), username, password)
+	if err != nil {
+		return flink.Errorf("retrieving a token for %v failed: %v", s.reference, err)
+	}
+	defer res.Body.Close()
+
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	err = json.NewDecoder(res.Body).Decode(&token)
+	if err != nil {
+		return fmt.Errorf("unable to parse the token for %v: %v", s.reference, err)
+	}
+	if len(token.Token) == 0 {
+		token.Token = token.AccessToken
+	}
+	s.authorization = "Bearer " + token.Token
+	return nil
+}
+
+var challengeParamPattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// parseChallenge parses a WWW-Authenticate header, e.g.
+// Bearer realm="https://auth.docker.io/token",service="registry.docker.io"
+func parseChallenge(challenge string) (string, map[string]string) {
+	parts := strings.SplitN(strings.TrimSpace(challenge), " ", 2)
+	params := map[string]string{}
+	if len(parts) == 2 {
+		for _, match := range challengeParamPattern.FindAllStringSubmatch(parts[1], -1) {
+			params[strings.ToLower(match[1])] = match[2]
+		}
+	}
+	return strings.ToLower(parts[0]), params
+}
+
+// dockerCredentials returns the credentials of the registry in the
+// Docker config file, or empty credentials when there are none
+func dockerCredentials(configPath string, registry string) (string, string, error) {
+	if len(configPath) == 0 {
+		return "", "", nil
+	}
+	content, err := ioutil.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("unable to read the Docker config: %v", err)
+	}
+
+	config := struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}{}
+	err = json.Unmarshal(content, &config)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to parse the Docker config: %v", err)
+	}
+
+	keys := []string{registry, "https://" + registry, "http://" + registry}
+	if registry == "docker.io" {
+		keys = append(keys, "https://index.docker.io/v1/")
+	}
+	for _, key := range keys {
+		auth, ok := config.Auths[key]
+		if !ok {
+			continue
+		}
+		if len(auth.Auth) == 0 {
+			return auth.Username, auth.Password, nil
+		}
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return "", "", fmt.Errorf("the Docker config contains invalid credentials for %v", registry)
+		}
+		parts := strings.SplitN(string(decoded[:]), ":", 2)
+		if len(parts) != 2 {
+			return "", "", fmt.Errorf("the Docker config contains invalid credentials for %v", registry)
+		}
+		return parts[0], parts[1], nil
+	}
+	return "", "", nil
+}
@@ -0,0 +1,263 @@
+package artifact
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/stretchr/testify/assert"
+)
+
+// testRegistry is a local stand-in for an OCI registry serving a single
+// repository. When credentials are set it challenges the requests for a
+// bearer token, which its token service issues for these credentials.
+type testRegistry struct {
+	repository string
+	manifests  map[string]string
+	blobs      map[string]string
+	username   string
+	password   string
+	requests   *[]string
+}
+
+func (r testRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.requests != nil {
+		*r.requests = append(*r.requests, req.URL.Path)
+	}
+	if req.URL.Path == "/token" {
+		username, password, ok := req.BasicAuth()
+		if !ok || username != r.username || password != r.password || req.URL.Query().Get("scope") != "repository:"+r.repository+":pull" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"token": "registry-token"}`))
+		return
+	}
+
+	if len(r.username) > 0 && req.Header.Get("Authorization") != "Bearer registry-token" {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%v/token",service="registry",scope="repository:%v:pull"`, req.Host, r.repository))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	prefix := "/v2/" + r.repository + "/"
+	var content string
+	var ok bool
+	switch {
+	case strings.HasPrefix(req.URL.Path, prefix+"manifests/"):
+		content, ok = r.manifests[strings.TrimPrefix(req.URL.Path, prefix+"manifests/")]
+		w.Header().Set("Content-Type", MediaTypeOCIManifest)
+	case strings.HasPrefix(req.URL.Path, prefix+"blobs/"):
+		content, ok = r.blobs[strings.TrimPrefix(req.URL.Path, prefix+"blobs/")]
+	}
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors": [{"code": "MANIFEST_UNKNOWN"}]}`))
+		return
+	}
+	w.Write([]byte(content))
+}
+
+// createTestRegistry serves the jar as layer of the repository jobs/word-count,
+// tagged 1.0.0 and referenced by the digest of its manifest
+func createTestRegistry(jar string, mediaType string) (testRegistry, string) {
+	layerDigest := "sha256:" + sha256Hex(jar)
+	manifest := fmt.Sprintf(`{
+  "schemaVersion": 2,
+  "mediaType": "%v",
+  "config": {"mediaType": "application/vnd.oci.empty.v1+json", "digest": "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a", "size": 2},
+  "layers": [
+    {"mediaType": "%v", "digest": "%v", "size": %v, "annotations": {"org.opencontainers.image.title": "word-count-1.0.0.jar"}}
+  ]
+}`, MediaTypeOCIManifest, mediaType, layerDigest, len(jar))
+	manifestDigest := "sha256:" + sha256Hex(manifest)
+
+	return testRegistry{
+		repository: "jobs/word-count",
+		manifests:  map[string]string{"1.0.0": manifest, manifestDigest: manifest},
+		blobs:      map[string]string{layerDigest: jar},
+	}, manifestDigest
+}
+
+/*
+ * ParseOCIReference
+ */
+func TestParseOCIReferenceShouldParseATag(t *testing.T) {
+	reference, err := ParseOCIReference("oci://localhost:5000/jobs/word-count:1.0.0")
+
+	assert.Nil(t, err)
+	assert.Equal(t, OCIReference{Registry: "localhost:5000", Repository: "jobs/word-count", Tag: "1.0.0"}, reference)
+	assert.Equal(t, "oci://localhost:5000/jobs/word-count:1.0.0", reference.String())
+}
+
+func TestParseOCIReferenceShouldDefaultToTheLatestTag(t *testing.T) {
+	reference, err := ParseOCIReference("oci://ghcr.io/ing-bank/word-count")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "latest", reference.Tag)
+}
+
+func TestParseOCIReferenceShouldParseADigest(t *testing.T) {
+	digest := "sha256:" + sha256Hex("manifest")
+
+	reference, err := ParseOCIReference("oci://ghcr.io/ing-bank/word-count@" + digest)
+
+	assert.Nil(t, err)
+	assert.Equal(t, OCIReference{Registry: "ghcr.io", Repository: "ing-bank/word-count", Digest: digest}, reference)
+}
+
+func TestParseOCIReferenceShouldReturnAnErrorForAnInvalidDigest(t *testing.T) {
+	_, err := ParseOCIReference("oci://ghcr.io/ing-bank/word-count@md5:abc")
+
+	assert.EqualError(t, err, "OCI reference \"oci://ghcr.io/ing-bank/word-count@md5:abc\" contains the invalid digest \"md5:abc\", only sha256 digests are supported")
+}
+
+func TestParseOCIReferenceShouldReturnAnErrorWithoutARepository(t *testing.T) {
+	_, err := ParseOCIReference("oci://ghcr.io")
+
+	assert.EqualError(t, err, "OCI reference \"oci://ghcr.io\" doesn't match the format oci://registry/repository[:tag][@digest]")
+}
+
+/*
+ * Download
+ */
+func TestOCIDownloadShouldDownloadTheJarLayerOfATag(t *testing.T) {
+	registry, _ := createTestRegistry("jar", "application/java-archive")
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	reference, _ := ParseOCIReference("oci://" + strings.TrimPrefix(server.URL, "http://") + "/jobs/word-count:1.0.0")
+	path, err := OCI{PlainHTTP: true}.Download(context.Background(), reference, dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dir, "word-count-1.0.0.jar"), path)
+	assert.Equal(t, "jar", readTestFile(t, path))
+}
+
+func TestOCIDownloadShouldDownloadTheJarLayerOfADigest(t *testing.T) {
+	registry, digest := createTestRegistry("jar", "application/vnd.oci.image.layer.v1.tar")
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	reference, _ := ParseOCIReference("oci://" + strings.TrimPrefix(server.URL, "http://") + "/jobs/word-count@" + digest)
+	path, err := OCI{PlainHTTP: true}.Download(context.Background(), reference, dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "jar", readTestFile(t, path))
+}
+
+func TestOCIDownloadShouldReturnAnErrorWhenTheManifestDoesNotMatchTheDigest(t *testing.T) {
+	registry, _ := createTestRegistry("jar", "application/java-archive")
+	digest := "sha256:" + sha256Hex("other manifest")
+	registry.manifests[digest] = registry.manifests["1.0.0"]
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	reference, _ := ParseOCIReference("oci://" + strings.TrimPrefix(server.URL, "http://") + "/jobs/word-count@" + digest)
+	_, err := OCI{PlainHTTP: true}.Download(context.Background(), reference, dir)
+
+	assert.True(t, strings.HasPrefix(err.Error(), "digest mismatch for the manifest of "+reference.String()))
+}
+
+func TestOCIDownloadShouldReturnAnErrorWhenTheLayerDoesNotMatchTheDigest(t *testing.T) {
+	registry, _ := createTestRegistry("jar", "application/java-archive")
+	registry.blobs["sha256:"+sha256Hex("jar")] = "tampered"
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	reference, _ := ParseOCIReference("oci://" + strings.TrimPrefix(server.URL, "http://") + "/jobs/word-count:1.0.0")
+	_, err := OCI{PlainHTTP: true}.Download(context.Background(), reference, dir)
+
+	assert.True(t, strings.HasPrefix(err.Error(), "digest mismatch for the JAR layer of "+reference.String()))
+	_, err = os.Stat(filepath.Join(dir, "word-count-1.0.0.jar"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestOCIDownloadShouldReturnAnErrorWithoutAJarLayer(t *testing.T) {
+	registry, _ := createTestRegistry("jar", "application/vnd.oci.image.layer.v1.tar+gzip")
+	registry.manifests["1.0.0"] = strings.Replace(registry.manifests["1.0.0"], "word-count-1.0.0.jar", "word-count.tar.gz", 1)
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	reference, _ := ParseOCIReference("oci://" + strings.TrimPrefix(server.URL, "http://") + "/jobs/word-count:1.0.0")
+	_, err := OCI{PlainHTTP: true}.Download(context.Background(), reference, dir)
+
+	assert.EqualError(t, err, reference.String()+" doesn't contain a layer with one of the media types application/java-archive, application/x-java-archive")
+}
+
+func TestOCIDownloadShouldAuthenticateWithTheCredentialsOfTheDockerConfig(t *testing.T) {
+	requests := []string{}
+	registry, _ := createTestRegistry("jar", "application/java-archive")
+	registry.username = "deployer"
+	registry.password = "secret"
+	registry.requests = &requests
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	host := strings.TrimPrefix(server.URL, "http://")
+	dockerConfig := filepath.Join(dir, "config.json")
+	auth := base64.StdEncoding.EncodeToString([]byte("deployer:secret"))
+	ioutil.WriteFile(dockerConfig, []byte(`{"auths": {"`+host+`": {"auth": "`+auth+`"}}}`), 0600)
+
+	reference, _ := ParseOCIReference("oci://" + host + "/jobs/word-count:1.0.0")
+	path, err := OCI{DockerConfig: dockerConfig, PlainHTTP: true}.Download(context.Background(), reference, dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "jar", readTestFile(t, path))
+	assert.Equal(t, []string{
+		"/v2/jobs/word-count/manifests/1.0.0",
+		"/token",
+		"/v2/jobs/word-count/manifests/1.0.0",
+		"/v2/jobs/word-count/blobs/sha256:" + sha256Hex("jar"),
+	}, requests)
+}
+
+func TestOCIDownloadShouldReturnUnauthorizedWithoutCredentials(t *testing.T) {
+	registry, _ := createTestRegistry("jar", "application/java-archive")
+	registry.username = "deployer"
+	registry.password = "secret"
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	reference, _ := ParseOCIReference("oci://" + strings.TrimPrefix(server.URL, "http://") + "/jobs/word-count:1.0.0")
+	_, err := OCI{DockerConfig: filepath.Join(dir, "missing.json"), PlainHTTP: true}.Download(context.Background(), reference, dir)
+
+	assert.Equal(t, flink.ErrUnauthorized, flink.Category(err))
+}
+
+/*
+ * dockerCredentials
+ */
+func TestDockerCredentialsShouldUseTheDockerHubKey(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	dockerConfig := filepath.Join(dir, "config.json")
+	ioutil.WriteFile(dockerConfig, []byte(`{"auths": {"https://index.docker.io/v1/": {"username": "hub", "password": "secret"}}}`), 0600)
+
+	username, password, err := dockerCredentials(dockerConfig, "docker.io")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "hub", username)
+	assert.Equal(t, "secret", password)
+}
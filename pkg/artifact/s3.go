@@ -0,0 +1,239 @@
+package artifact
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Prefix is the prefix of artifact references to objects in S3
+const S3Prefix = "s3://"
+
+// emptyPayloadHash is the SHA-256 hash of the empty body of GET requests
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// An S3Location identifies an object in a bucket
+type S3Location struct {
+	Bucket string
+	Key    string
+}
+
+// ParseS3Location parses a reference in the format s3://bucket/key
+func ParseS3Location(reference string) (S3Location, error) {
+	if !strings.HasPrefix(reference, S3Prefix) {
+		return S3Location{}, fmt.Errorf("S3 location \"%v\" doesn't start with \"%v\"", reference, S3Prefix)
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(reference, S3Prefix), "/", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return S3Location{}, fmt.Errorf("S3 location \"%v\" doesn't match the format s3://bucket/key", reference)
+	}
+	return S3Location{Bucket: parts[0], Key: parts[1]}, nil
+}
+
+func (l S3Location) String() string {
+	return S3Prefix + l.Bucket + "/" + l.Key
+}
+
+// S3 downloads JAR files from S3 or S3-compatible object storage, like
+// MinIO. Requests are signed with AWS Signature Version 4 when Credentials
+// are set, and sent anonymously otherwise.
+type S3 struct {
+	// Endpoint is the URL of the object storage, which defaults
+	// to the regional endpoint of AWS, e.g. https://s3.eu-west-1.amazonaws.com
+	Endpoint string
+	// Region is the region requests are signed for, which defaults to us-east-1
+	Region string
+	// PathStyle addresses buckets by path, e.g. https://minio:9000/bucket/key,
+	// rather than by virtual host, e.g. https://bucket.s3.amazonaws.com/key
+	PathStyle   bool
+	Credentials S3CredentialsProvider
+	Client      *http.Client
+	// Logger, when set, receives the progress of the downloads
+	Logger *log.Logger
+
+	now func() time.Time
+}
+
+func (s S3) region() string {
+	if len(s.Region) == 0 {
+		return "us-east-1"
+	}
+	return s.Region
+}
+
+// objectURL returns the URL of the object at the location
+func (s S3) objectURL(location S3Location) (*url.URL, error) {
+	endpoint := s.Endpoint
+	if len(endpoint) == 0 {
+		endpoint = fmt.Sprintf("https://s3.%v.amazonaws.com", s.region())
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || len(u.Host) == 0 {
+		return nil, fmt.Errorf("invalid S3 endpoint \"%v\"", endpoint)
+	}
+
+	objectPath := "/" + location.Key
+	if s.PathStyle {
+		objectPath = "/" + location.Bucket + objectPath
+	} else {
+		u.Host = location.Bucket + "." + u.Host
+	}
+	u.Path = strings.TrimRight(u.Path, "/") + objectPath
+	u.RawPath = uriEncode(u.Path, false)
+	return u, nil
+}
+
+// Download streams the object at the location into
+// the directory and returns the path of the file
+func (s S3) Download(ctx context.Context, location S3Location, dir string) (string, error) {
+	objectURL, err := s.objectURL(location)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest("GET", objectURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	if s.Credentials != nil {
+		credentials, err := s.Credentials.Retrieve(ctx)
+		if err != nil {
+			return "", fmt.Errorf("unable to retrieve the S3 credentials: %v", err)
+		}
+		now := time.Now
+		if s.now != nil {
+			now = s.now
+		}
+		signS3Request(req, credentials, s.region(), now())
+	}
+
+	res, err := do(ctx, s.Client, req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	filename := path.Base(location.Key)
+	if !strings.HasSuffix(filename, ".jar") {
+		filename += ".jar"
+	}
+	target := filepath.Join(dir, filename)
+	n, err := writeFile(target, res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	logf(s.Logger, "downloaded %v (%v bytes)", location, n)
+	return target, nil
+}
+
+// signS3Request signs the request with AWS Signature Version 4
+func signS3Request(req *http.Request, credentials S3Credentials, region string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+	if len(credentials.SessionToken) > 0 {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "range" || strings.HasPrefix(name, "x-amz-") {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := []string{}
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := ""
+	for _, name := range names {
+		canonicalHeaders += name + ":" + headers[name] + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%v/%v, SignedHeaders=%v, Signature=%v", credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := []string{}
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := []string{}
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode encodes all characters except the unreserved characters
+// of RFC 3986, and the slash unless encodeSlash is set
+func uriEncode(s string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for _, b := range []byte(s) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~':
+			encoded.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			encoded.WriteByte(b)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return encoded.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,158 @@
+package artifact
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSTSEndpoint is the endpoint of the AWS Security Token Service
+const DefaultSTSEndpoint = "https://sts.amazonaws.com"
+
+// S3Credentials are the credentials requests to S3 are signed with
+type S3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// An S3CredentialsProvider provides the credentials for a request to S3
+type S3CredentialsProvider interface {
+	Retrieve(ctx context.Context) (S3Credentials, error)
+}
+
+// Retrieve returns the static credentials
+func (c S3Credentials) Retrieve(ctx context.Context) (S3Credentials, error) {
+	if len(c.AccessKeyID) == 0 || len(c.SecretAccessKey) == 0 {
+		return S3Credentials{}, errors.New("both an access key ID and a secret access key are required")
+	}
+	return c, nil
+}
+
+// EnvS3Credentials provides the credentials in the `AWS_ACCESS_KEY_ID`,
+// `AWS_SECRET_ACCESS_KEY` and `AWS_SESSION_TOKEN` environment variables
+type EnvS3Credentials struct{}
+
+// Retrieve reads the credentials from the environment variables
+func (EnvS3Credentials) Retrieve(ctx context.Context) (S3Credentials, error) {
+	credentials := S3Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if len(credentials.AccessKeyID) == 0 || len(credentials.SecretAccessKey) == 0 {
+		return S3Credentials{}, errors.New("`AWS_ACCESS_KEY_ID` and `AWS_SECRET_ACCESS_KEY` environment variables not found")
+	}
+	return credentials, nil
+}
+
+// WebIdentityS3Credentials exchanges a web identity token, e.g. the token of
+// a Kubernetes service account, for temporary credentials of the role through
+// the AWS Security Token Service. The credentials are cached until shortly
+// before they expire.
+type WebIdentityS3Credentials struct {
+	RoleARN     string
+	TokenFile   string
+	SessionName string
+	// STSEndpoint defaults to the DefaultSTSEndpoint
+	STSEndpoint string
+	Client      *http.Client
+
+	mutex       sync.Mutex
+	credentials S3Credentials
+	expiration  time.Time
+}
+
+type assumeRoleWithWebIdentityResponse struct {
+	Credentials struct {
+		AccessKeyID     string    `xml:"AccessKeyId"`
+		SecretAccessKey string    `xml:"SecretAccessKey"`
+		SessionToken    string    `xml:"SessionToken"`
+		Expiration      time.Time `xml:"Expiration"`
+	} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+}
+
+// Retrieve returns the cached credentials, or assumes the
+// role again when they are about to expire
+func (w *WebIdentityS3Credentials) Retrieve(ctx context.Context) (S3Credentials, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if time.Now().Add(time.Minute).Before(w.expiration) {
+		return w.credentials, nil
+	}
+
+	token, err := ioutil.ReadFile(w.TokenFile)
+	if err != nil {
+		return S3Credentials{}, fmt.Errorf("unable to read the web identity token: %v", err)
+	}
+
+	sessionName := w.SessionName
+	if len(sessionName) == 0 {
+		sessionName = fmt.Sprintf("flink-deployer-%v", time.Now().Unix())
+	}
+	endpoint := w.STSEndpoint
+	if len(endpoint) == 0 {
+		endpoint = DefaultSTSEndpoint
+	}
+
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {w.RoleARN},
+		"RoleSessionName":  {sessionName},
+		"WebIdentityToken": {strings.TrimSpace(string(token[:]))},
+	}
+	req, err := http.NewRequest("POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return S3Credentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := do(ctx, w.Client, req)
+	if err != nil {
+		return S3Credentials{}, fmt.Errorf("assuming role \"%v\" failed: %v", w.RoleARN, err)
+	}
+	defer res.Body.Close()
+
+	response := assumeRoleWithWebIdentityResponse{}
+	err = xml.NewDecoder(res.Body).Decode(&response)
+	if err != nil {
+		return S3Credentials{}, fmt.Errorf("unable to parse the response of the security token service: %v", err)
+	}
+
+	w.credentials = S3Credentials{
+		AccessKeyID:     response.Credentials.AccessKeyID,
+		SecretAccessKey: response.Credentials.SecretAccessKey,
+		SessionToken:    response.Credentials.SessionToken,
+	}
+	w.expiration = response.Credentials.Expiration
+	return w.credentials, nil
+}
+
+// S3CredentialsFromEnvironment returns the web identity credentials when
+// `AWS_WEB_IDENTITY_TOKEN_FILE` and `AWS_ROLE_ARN` are set, as they are on
+// EKS, the environment credentials when `AWS_ACCESS_KEY_ID` is set, and nil
+// otherwise, so requests are sent anonymously
+func S3CredentialsFromEnvironment(client *http.Client) S3CredentialsProvider {
+	if len(os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")) > 0 && len(os.Getenv("AWS_ROLE_ARN")) > 0 {
+		return &WebIdentityS3Credentials{
+			RoleARN:     os.Getenv("AWS_ROLE_ARN"),
+			TokenFile:   os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"),
+			SessionName: os.Getenv("AWS_ROLE_SESSION_NAME"),
+			Client:      client,
+		}
+	}
+	if len(os.Getenv("AWS_ACCESS_KEY_ID")) > 0 {
+		return EnvS3Credentials{}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package artifact
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+)
+
+// A PublicKey is a key signatures of JAR files are verified with, either
+// an OpenPGP key with its subkeys, or a PEM encoded public key as used by cosign
+type PublicKey struct {
+	// Entity is the OpenPGP key, and nil for PEM encoded keys
+	Entity *openpgp.Entity
+	// Key is the PEM encoded public key, and nil for OpenPGP keys
+	Key crypto.PublicKey
+}
+
+// TrustedKeys are the public keys signatures of JAR files are verified with
+type TrustedKeys []PublicKey
+
+// ParseTrustedKeys parses the public keys in PEM encoded "PUBLIC KEY"
+// blocks, as written by `cosign generate-key-pair`, or in an OpenPGP
+// public key block, armored or binary, as exported by `gpg --export`
+func ParseTrustedKeys(content []byte) (TrustedKeys, error) {
+	if bytes.Contains(content, []byte("-----BEGIN PUBLIC KEY-----")) {
+		keys := TrustedKeys{}
+		for rest := content; ; {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type != "PUBLIC KEY" {
+				continue
+			}
+			key, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse the public key: %v", err)
+			}
+			switch key.(type) {
+			case *rsa.PublicKey, *ecdsa.PublicKey:
+			default:
+				return nil, fmt.Errorf("unsupported public key type %T, only RSA and ECDSA keys are supported", key)
+			}
+			keys = append(keys, PublicKey{Key: key})
+		}
+		if len(keys) == 0 {
+			return nil, errors.New("no public keys found, the PEM encoded \"PUBLIC KEY\" blocks can't be decoded")
+		}
+		return keys, nil
+	}
+
+	if len(bytes.TrimSpace(content)) == 0 {
+		return nil, errors.New("no public keys found, expected PEM encoded public keys or an OpenPGP public key block")
+	}
+
+	var entities openpgp.EntityList
+	var err error
+	if bytes.Contains(content, []byte("-----BEGIN PGP PUBLIC KEY BLOCK-----")) {
+		entities, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(content))
+	} else {
+		entities, err = openpgp.ReadKeyRing(bytes.NewReader(content))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the OpenPGP public keys: %v", err)
+	}
+	keys := TrustedKeys{}
+	for _, entity := range entities {
+		keys = append(keys, PublicKey{Entity: entity})
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no public keys found in the OpenPGP public key block")
+	}
+	return keys, nil
+}
+
+// VerifySignature verifies the detached signature of the file, either an
+// OpenPGP signature, armored or binary, as written by `gpg --detach-sign`,
+// or a base64 encoded signature, as written by `cosign sign-blob`
+func (k TrustedKeys) VerifySignature(path string, signature []byte) error {
+	if len(k) == 0 {
+		return errors.New("no trusted keys are configured to verify signatures with")
+	}
+
+	trimmed := bytes.TrimSpace(signature)
+	armored := bytes.HasPrefix(trimmed, []byte("-----BEGIN PGP SIGNATURE-----"))
+	if armored || (len(signature) > 0 && signature[0]&0x80 != 0) {
+		return k.verifyOpenPGP(path, signature, armored)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(string(trimmed))
+	if err != nil {
+		return fmt.Errorf("the signature of %v is neither an OpenPGP signature nor base64 encoded", path)
+	}
+	digest, err := fileDigest(path, crypto.SHA256)
+	if err != nil {
+		return err
+	}
+	for _, key := range k {
+		if key.Key != nil && verifyDigest(key.Key, crypto.SHA256, digest, decoded) {
+			return nil
+		}
+	}
+	return fmt.Errorf("the signature of %v doesn't match any of the trusted keys", path)
+}
+
+// verifyOpenPGP verifies the OpenPGP signature with the OpenPGP keys, which
+// must neither be expired nor revoked. Signatures of subkeys are verified
+// when the subkey is bound to its primary key.
+func (k TrustedKeys) verifyOpenPGP(path string, signature []byte, armored bool) error {
+	keyring := openpgp.EntityList{}
+	for _, key := range k {
+		if key.Entity != nil {
+			keyring = append(keyring, key.Entity)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	check := openpgp.CheckDetachedSignature
+	if armored {
+		check = openpgp.CheckArmoredDetachedSignature
+	}
+	_, err = check(keyring, f, bytes.NewReader(signature), nil)
+	switch err {
+	case nil:
+		return nil
+	case pgperrors.ErrUnknownIssuer:
+		return fmt.Errorf("the signature of %v was made with a key which is unknown, revoked or can't sign, so it isn't trusted", path)
+	case pgperrors.ErrKeyExpired:
+		return fmt.Errorf("the signature of %v was made with an expired key", path)
+	case pgperrors.ErrSignatureExpired:
+		return fmt.Errorf("the signature of %v is expired", path)
+	}
+	return fmt.Errorf("the signature of %v is invalid: %v", path, err)
+}
+
+// VerifySHA256 verifies the SHA-256 checksum of the file
+// against the expected checksum in hexadecimal encoding
+func VerifySHA256(path string, expected string) error {
+	digest, err := fileDigest(path, crypto.SHA256)
+	if err != nil {
+		return err
+	}
+	actual := hex.EncodeToString(digest)
+	if !strings.EqualFold(actual, strings.TrimPrefix(strings.TrimSpace(expected), "sha256:")) {
+		return fmt.Errorf("the SHA-256 checksum of %v is %v, but %v was expected", path, actual, expected)
+	}
+	return nil
+}
+
+func fileDigest(path string, hash crypto.Hash) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := hash.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// verifyDigest verifies an ASN.1 encoded ECDSA or a PKCS #1 v1.5 RSA signature.
+// ECDSA signatures with data after the ASN.1 structure are rejected.
+func verifyDigest(key crypto.PublicKey, hash crypto.Hash, digest []byte, signature []byte) bool {
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		values := struct{ R, S *big.Int }{}
+		rest, err := asn1.Unmarshal(signature, &values)
+		return err == nil && len(rest) == 0 && ecdsa.Verify(key, digest, values.R, values.S)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil
+	}
+	return false
+}
@@ -0,0 +1,224 @@
+package artifact
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The keys and signatures in testdata are generated with gpg and openssl:
+//
+//   gpg --quick-gen-key "CI RSA <ci@example.com>" rsa2048 sign never
+//   gpg --armor --export ci@example.com > rsa.asc
+//   gpg --local-user ci@example.com --armor --detach-sign -o job.jar.rsa.asc job.jar
+//   openssl ecparam -name prime256v1 -genkey -noout -out cosign.key
+//   openssl ec -in cosign.key -pubout -out cosign.pub
+//   openssl dgst -sha256 -sign cosign.key job.jar | base64 > job.jar.cosign.sig
+//
+// The ECDSA and EdDSA keys use the curves nistp256 and ed25519. The expired
+// key is generated and used with --faked-system-time, the revoked key is
+// exported after importing its revocation certificate, and the subkey key
+// has a primary key which can only certify and a signing subkey:
+//
+//   gpg --quick-gen-key "CI Subkey <subkey@example.com>" ed25519 cert never
+//   gpg --quick-add-key <fingerprint> ed25519 sign never
+
+func readTestKeys(t *testing.T, name string) TrustedKeys {
+	content, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	assert.Nil(t, err)
+	keys, err := ParseTrustedKeys(content)
+	assert.Nil(t, err)
+	return keys
+}
+
+func readTestSignature(t *testing.T, name string) []byte {
+	content, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	assert.Nil(t, err)
+	return content
+}
+
+// createTamperedJar copies the test JAR file with a changed last byte
+func createTamperedJar(t *testing.T) (string, string) {
+	dir := createTestDir(t)
+	content, _ := ioutil.ReadFile(filepath.Join("testdata", "job.jar"))
+	content[len(content)-1] = '!'
+	path := filepath.Join(dir, "job.jar")
+	ioutil.WriteFile(path, content, 0644)
+	return dir, path
+}
+
+/*
+ * ParseTrustedKeys
+ */
+func TestParseTrustedKeysShouldParseAnArmoredOpenPGPKey(t *testing.T) {
+	keys := readTestKeys(t, "rsa.asc")
+
+	assert.Equal(t, 1, len(keys))
+	assert.NotNil(t, keys[0].Entity)
+	assert.Nil(t, keys[0].Key)
+}
+
+func TestParseTrustedKeysShouldParseAPEMEncodedKey(t *testing.T) {
+	keys := readTestKeys(t, "cosign.pub")
+
+	assert.Equal(t, 1, len(keys))
+	assert.Nil(t, keys[0].Entity)
+	assert.NotNil(t, keys[0].Key)
+}
+
+func TestParseTrustedKeysShouldReturnAnErrorWithoutKeys(t *testing.T) {
+	_, err := ParseTrustedKeys([]byte{})
+
+	assert.EqualError(t, err, "no public keys found, expected PEM encoded public keys or an OpenPGP public key block")
+}
+
+func TestParseTrustedKeysShouldReturnAnErrorForAnUndecodablePEMBlock(t *testing.T) {
+	_, err := ParseTrustedKeys([]byte("-----BEGIN PUBLIC KEY-----\nnot base64\n-----END PUBLIC KEY-----\n"))
+
+	assert.EqualError(t, err, "no public keys found, the PEM encoded \"PUBLIC KEY\" blocks can't be decoded")
+}
+
+/*
+ * VerifySignature
+ */
+func TestVerifySignatureShouldVerifyAnArmoredRSASignature(t *testing.T) {
+	keys := readTestKeys(t, "rsa.asc")
+
+	err := keys.VerifySignature(filepath.Join("testdata", "job.jar"), readTestSignature(t, "job.jar.rsa.asc"))
+
+	assert.Nil(t, err)
+}
+
+func TestVerifySignatureShouldVerifyABinaryECDSASignature(t *testing.T) {
+	keys := append(readTestKeys(t, "rsa.asc"), readTestKeys(t, "ecdsa.gpg")...)
+
+	err := keys.VerifySignature(filepath.Join("testdata", "job.jar"), readTestSignature(t, "job.jar.ecdsa.sig"))
+
+	assert.Nil(t, err)
+}
+
+func TestVerifySignatureShouldVerifyACosignSignature(t *testing.T) {
+	keys := readTestKeys(t, "cosign.pub")
+
+	err := keys.VerifySignature(filepath.Join("testdata", "job.jar"), readTestSignature(t, "job.jar.cosign.sig"))
+
+	assert.Nil(t, err)
+}
+
+func TestVerifySignatureShouldRejectATamperedJarFile(t *testing.T) {
+	dir, path := createTamperedJar(t)
+	defer os.RemoveAll(dir)
+
+	openpgpErr := readTestKeys(t, "rsa.asc").VerifySignature(path, readTestSignature(t, "job.jar.rsa.asc"))
+	cosignErr := readTestKeys(t, "cosign.pub").VerifySignature(path, readTestSignature(t, "job.jar.cosign.sig"))
+
+	assert.True(t, strings.HasPrefix(openpgpErr.Error(), "the signature of "+path+" is invalid: "), openpgpErr.Error())
+	assert.EqualError(t, cosignErr, "the signature of "+path+" doesn't match any of the trusted keys")
+}
+
+func TestVerifySignatureShouldRejectACosignSignatureWithTrailingData(t *testing.T) {
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(readTestSignature(t, "job.jar.cosign.sig"))))
+	assert.Nil(t, err)
+	signature = append(signature, 0)
+
+	err = readTestKeys(t, "cosign.pub").VerifySignature(filepath.Join("testdata", "job.jar"), []byte(base64.StdEncoding.EncodeToString(signature)))
+
+	assert.EqualError(t, err, "the signature of testdata/job.jar doesn't match any of the trusted keys")
+}
+
+func TestVerifySignatureShouldRejectASignatureOfAnUntrustedKey(t *testing.T) {
+	keys := readTestKeys(t, "ecdsa.gpg")
+
+	err := keys.VerifySignature(filepath.Join("testdata", "job.jar"), readTestSignature(t, "job.jar.rsa.asc"))
+
+	assert.EqualError(t, err, "the signature of testdata/job.jar was made with a key which is unknown, revoked or can't sign, so it isn't trusted")
+}
+
+func TestVerifySignatureShouldVerifyAnEdDSASignature(t *testing.T) {
+	keys := readTestKeys(t, "ed25519.asc")
+
+	err := keys.VerifySignature(filepath.Join("testdata", "job.jar"), readTestSignature(t, "job.jar.ed25519.asc"))
+
+	assert.Nil(t, err)
+}
+
+func TestVerifySignatureShouldVerifyASignatureOfASigningSubkey(t *testing.T) {
+	keys := readTestKeys(t, "subkey.asc")
+
+	err := keys.VerifySignature(filepath.Join("testdata", "job.jar"), readTestSignature(t, "job.jar.subkey.asc"))
+
+	assert.Nil(t, err)
+}
+
+func TestVerifySignatureShouldRejectASignatureOfAnExpiredKey(t *testing.T) {
+	keys := readTestKeys(t, "expired.asc")
+
+	err := keys.VerifySignature(filepath.Join("testdata", "job.jar"), readTestSignature(t, "job.jar.expired.asc"))
+
+	assert.EqualError(t, err, "the signature of testdata/job.jar was made with an expired key")
+}
+
+func TestVerifySignatureShouldRejectASignatureOfARevokedKey(t *testing.T) {
+	keys := readTestKeys(t, "revoked.asc")
+
+	err := keys.VerifySignature(filepath.Join("testdata", "job.jar"), readTestSignature(t, "job.jar.revoked.asc"))
+
+	assert.EqualError(t, err, "the signature of testdata/job.jar was made with a key which is unknown, revoked or can't sign, so it isn't trusted")
+}
+
+func TestVerifySignatureShouldReturnAnErrorWithoutTrustedKeys(t *testing.T) {
+	err := TrustedKeys{}.VerifySignature(filepath.Join("testdata", "job.jar"), readTestSignature(t, "job.jar.rsa.asc"))
+
+	assert.EqualError(t, err, "no trusted keys are configured to verify signatures with")
+}
+
+/*
+ * VerifySHA256
+ */
+func TestVerifySHA256ShouldAcceptTheChecksumOfTheFile(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "job.jar")
+	ioutil.WriteFile(path, []byte("jar"), 0644)
+
+	assert.Nil(t, VerifySHA256(path, sha256Hex("jar")))
+	assert.Nil(t, VerifySHA256(path, "sha256:"+strings.ToUpper(sha256Hex("jar"))))
+}
+
+func TestVerifySHA256ShouldRejectAnotherChecksum(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "job.jar")
+	ioutil.WriteFile(path, []byte("jar"), 0644)
+
+	err := VerifySHA256(path, sha256Hex("other"))
+
+	assert.EqualError(t, err, "the SHA-256 checksum of "+path+" is "+sha256Hex("jar")+", but "+sha256Hex("other")+" was expected")
+}
+
+/*
+ * VerificationConfig
+ */
+func TestVerificationConfigShouldApplyTheContext(t *testing.T) {
+	config, err := ParseVerificationConfig([]byte("keys:\n  - /keys/ci.asc\ncontexts:\n  production:\n    requireSignature: true\n    keys:\n      - /keys/release.pub\n"))
+	assert.Nil(t, err)
+
+	productionKeys, productionRequired := config.ForContext("production")
+	stagingKeys, stagingRequired := config.ForContext("staging")
+
+	assert.Equal(t, []string{"/keys/ci.asc", "/keys/release.pub"}, productionKeys)
+	assert.True(t, productionRequired)
+	assert.Equal(t, []string{"/keys/ci.asc"}, stagingKeys)
+	assert.False(t, stagingRequired)
+}
+
+func TestParseVerificationConfigShouldRejectUnknownProperties(t *testing.T) {
+	_, err := ParseVerificationConfig([]byte("requireSignatures: true\n"))
+
+	assert.True(t, strings.HasPrefix(err.Error(), "unable to parse the verification configuration"))
+}
@@ -0,0 +1,93 @@
+package artifact
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// A Source downloads the JAR files of the artifact references it supports,
+// e.g. Maven coordinates or the locations of objects in S3
+type Source interface {
+	// Supports returns whether the source downloads the reference
+	Supports(reference string) bool
+	// Fetch downloads the JAR file the reference points to into
+	// the directory and returns the path of the downloaded file
+	Fetch(ctx context.Context, reference string, dir string) (string, error)
+}
+
+// Sources downloads the artifact references through the first source
+// that supports them
+type Sources []Source
+
+// Supports returns whether any of the sources supports the reference
+func (s Sources) Supports(reference string) bool {
+	for _, source := range s {
+		if source.Supports(reference) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fetch downloads the reference through the first source supporting it
+func (s Sources) Fetch(ctx context.Context, reference string, dir string) (string, error) {
+	for _, source := range s {
+		if source.Supports(reference) {
+			return source.Fetch(ctx, reference, dir)
+		}
+	}
+	return "", fmt.Errorf("unsupported artifact reference \"%v\"", reference)
+}
+
+// Supports returns whether the reference contains Maven coordinates
+func (m Maven) Supports(reference string) bool {
+	return strings.HasPrefix(reference, MavenPrefix)
+}
+
+// Fetch downloads the artifact with the Maven coordinates of the reference
+func (m Maven) Fetch(ctx context.Context, reference string, dir string) (string, error) {
+	coordinates, err := ParseMavenCoordinates(reference)
+	if err != nil {
+		return "", err
+	}
+	return m.Download(ctx, coordinates, dir)
+}
+
+// Supports returns whether the reference is the location of an object in S3
+func (s S3) Supports(reference string) bool {
+	return strings.HasPrefix(reference, S3Prefix)
+}
+
+// Fetch downloads the object at the S3 location of the reference
+func (s S3) Fetch(ctx context.Context, reference string, dir string) (string, error) {
+	location, err := ParseS3Location(reference)
+	if err != nil {
+		return "", err
+	}
+	return s.Download(ctx, location, dir)
+}
+
+// Supports returns whether the reference is an HTTP or HTTPS URL
+func (h HTTP) Supports(reference string) bool {
+	return strings.HasPrefix(reference, "http://") || strings.HasPrefix(reference, "https://")
+}
+
+// Fetch downloads the file at the URL of the reference
+func (h HTTP) Fetch(ctx context.Context, reference string, dir string) (string, error) {
+	return h.Download(ctx, reference, dir)
+}
+
+// Supports returns whether the reference points to an OCI registry
+func (o OCI) Supports(reference string) bool {
+	return strings.HasPrefix(reference, OCIPrefix)
+}
+
+// Fetch downloads the JAR layer of the OCI artifact of the reference
+func (o OCI) Fetch(ctx context.Context, reference string, dir string) (string, error) {
+	ref, err := ParseOCIReference(reference)
+	if err != nil {
+		return "", err
+	}
+	return o.Download(ctx, ref, dir)
+}
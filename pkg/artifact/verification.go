@@ -0,0 +1,63 @@
+package artifact
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// A VerificationPolicy determines how JAR files are verified before
+// they're uploaded to the cluster
+type VerificationPolicy struct {
+	// Keys are the keys detached signatures are verified with
+	Keys TrustedKeys
+	// RequireSignature rejects JAR files without a detached signature
+	RequireSignature bool
+}
+
+// VerificationConfig is the YAML configuration of the verification of JAR
+// files, with the key files and whether signatures are required by default
+// and per context, e.g. the production cluster:
+//
+//	keys:
+//	  - /etc/flink-deployer/ci.asc
+//	contexts:
+//	  production:
+//	    requireSignature: true
+type VerificationConfig struct {
+	Keys             []string                       `yaml:"keys"`
+	RequireSignature bool                           `yaml:"requireSignature"`
+	Contexts         map[string]VerificationContext `yaml:"contexts"`
+}
+
+// A VerificationContext overrides the verification of JAR files for a context
+type VerificationContext struct {
+	// Keys are trusted in addition to the keys of the configuration
+	Keys             []string `yaml:"keys"`
+	RequireSignature *bool    `yaml:"requireSignature"`
+}
+
+// ParseVerificationConfig parses the YAML verification configuration
+func ParseVerificationConfig(content []byte) (VerificationConfig, error) {
+	config := VerificationConfig{}
+	err := yaml.UnmarshalStrict(content, &config)
+	if err != nil {
+		return VerificationConfig{}, fmt.Errorf("unable to parse the verification configuration: %v", err)
+	}
+	return config, nil
+}
+
+// ForContext returns the key files and whether signatures are required in
+// the context. Contexts that aren't configured use the defaults.
+func (c VerificationConfig) ForContext(context string) ([]string, bool) {
+	keys := append([]string{}, c.Keys...)
+	requireSignature := c.RequireSignature
+
+	if override, ok := c.Contexts[context]; ok {
+		keys = append(keys, override.Keys...)
+		if override.RequireSignature != nil {
+			requireSignature = *override.RequireSignature
+		}
+	}
+	return keys, requireSignature
+}
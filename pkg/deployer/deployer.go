@@ -0,0 +1,307 @@
+// Package deployer deploys and updates jobs on Apache Flink clusters. It is
+// the library behind the flink-deployer command-line utility and can be
+// embedded in other Go tooling:
+//
+//	d, err := deployer.New(ctx, deployer.WithBaseURL("http://jobmanager:8081"))
+//	if err != nil {
+//		return err
+//	}
+//	result, err := d.Update(ctx, deployer.UpdateRequest{
+//		JobNameBase:   "WordCount",
+//		LocalFilename: "wordcount.jar",
+//		SavepointDir:  "/data/flink/savepoints",
+//	})
+//
+// All operations stop at the next safe point when their context is done.
+package deployer
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/ing-bank/flink-deployer/pkg/artifact"
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/ing-bank/flink-deployer/pkg/operations"
+	"github.com/ing-bank/flink-deployer/pkg/secret"
+	"github.com/spf13/afero"
+)
+
+// defaultTimeout is the default timeout of a single request
+const defaultTimeout = 10 * time.Second
+
+// defaultDownloadRetries is the default number of retries of a failed download
+const defaultDownloadRetries = 3
+
+// A Deployer executes operations on a Flink cluster
+type Deployer struct {
+	operator operations.RealOperator
+}
+
+var _ operations.Operator = &Deployer{}
+
+// NewTransport creates an HTTP transport with the TLS options
+func NewTransport(options flink.TLSOptions) (*http.Transport, error) {
+	transport := cleanhttp.DefaultPooledTransport()
+	if !options.Enabled() {
+		return transport, nil
+	}
+
+	tlsConfig, err := flink.NewTLSConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
+
+// cloneTransport copies the configuration of the transport, like its proxy
+// and timeouts, into a new transport with connections of its own
+func cloneTransport(transport *http.Transport) *http.Transport {
+	return &http.Transport{
+		Proxy:                  transport.Proxy,
+		DialContext:            transport.DialContext,
+		Dial:                   transport.Dial,
+		DialTLS:                transport.DialTLS,
+		TLSClientConfig:        transport.TLSClientConfig,
+		TLSHandshakeTimeout:    transport.TLSHandshakeTimeout,
+		DisableKeepAlives:      transport.DisableKeepAlives,
+		DisableCompression:     transport.DisableCompression,
+		MaxIdleConns:           transport.MaxIdleConns,
+		MaxIdleConnsPerHost:    transport.MaxIdleConnsPerHost,
+		MaxConnsPerHost:        transport.MaxConnsPerHost,
+		IdleConnTimeout:        transport.IdleConnTimeout,
+		ResponseHeaderTimeout:  transport.ResponseHeaderTimeout,
+		ExpectContinueTimeout:  transport.ExpectContinueTimeout,
+		TLSNextProto:           transport.TLSNextProto,
+		ProxyConnectHeader:     transport.ProxyConnectHeader,
+		MaxResponseHeaderBytes: transport.MaxResponseHeaderBytes,
+	}
+}
+
+// New creates a Deployer configured by the options. Either WithBaseURL or
+// WithDiscoverer is required. The endpoint is discovered within the context.
+func New(ctx context.Context, options ...Option) (*Deployer, error) {
+	c := config{
+		timeout:           defaultTimeout,
+		filesystem:        afero.NewOsFs(),
+		mavenRepositories: []artifact.MavenRepository{{URL: artifact.MavenCentral}},
+	}
+	for _, option := range options {
+		err := option(&c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.secrets.Redactor == nil {
+		c.secrets.Redactor = &secret.Redactor{}
+	}
+	c.secrets.Redactor.Add(c.secrets.Vault.Token)
+	if !c.loggerSet {
+		c.logger = log.New(c.secrets.Redactor.Writer(os.Stderr), "", log.LstdFlags)
+	}
+
+	if len(c.baseURLs) == 0 && c.discoverer == nil {
+		return nil, errors.New("no Flink REST endpoint configured, use either WithBaseURL or WithDiscoverer")
+	}
+	if len(c.baseURLs) > 0 && c.discoverer != nil {
+		return nil, errors.New("both WithBaseURL and WithDiscoverer are used, only one allowed")
+	}
+
+	if c.tlsOptions.InsecureSkipVerify {
+		c.logf("WARNING: TLS certificate verification of the Flink cluster is disabled")
+	}
+
+	transport := c.transport
+	if transport == nil {
+		var err error
+		transport, err = NewTransport(c.tlsOptions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	client := retryablehttp.NewClient()
+	client.HTTPClient = &http.Client{
+		Timeout:   c.timeout,
+		Transport: transport,
+	}
+	client.Logger = c.logger
+
+	var authenticator flink.Authenticator
+	switch len(c.authenticators) {
+	case 0:
+	case 1:
+		authenticator = c.authenticators[0]
+	default:
+		authenticator = c.authenticators
+	}
+
+	baseURLs := c.baseURLs
+	if c.discoverer != nil {
+		endpoint, err := c.discoverer.Discover(ctx)
+		if err != nil {
+			return nil, flink.Errorf("unable to discover the Flink REST endpoint: %v", err)
+		}
+		c.logf("discovered the Flink REST endpoint %v", endpoint.BaseURL)
+		baseURLs = []string{endpoint.BaseURL}
+
+		if endpoint.TLSConfig != nil {
+			discoveredTransport := cloneTransport(transport)
+			discoveredTransport.TLSClientConfig = endpoint.TLSConfig
+			client.HTTPClient = &http.Client{
+				Timeout:   client.HTTPClient.Timeout,
+				Transport: discoveredTransport,
+			}
+		}
+		if endpoint.Authenticator != nil {
+			if authenticator != nil {
+				authenticator = flink.Authenticators{authenticator, endpoint.Authenticator}
+			} else {
+				authenticator = endpoint.Authenticator
+			}
+		}
+	}
+
+	var endpoints *flink.Endpoints
+	baseURL := baseURLs[0]
+	if len(baseURLs) > 1 {
+		endpoints = flink.NewEndpoints(baseURLs)
+		baseURL = endpoints.Current()
+	}
+
+	policies := c.policies
+	if policies == nil {
+		policies = flink.DefaultPolicies(c.timeout)
+	}
+
+	s3 := c.s3
+	if s3.Client == nil {
+		s3.Client = cleanhttp.DefaultPooledClient()
+	}
+	if s3.Logger == nil {
+		s3.Logger = c.logger
+	}
+	oci := c.oci
+	if oci.Client == nil {
+		oci.Client = cleanhttp.DefaultPooledClient()
+	}
+	if oci.Logger == nil {
+		oci.Logger = c.logger
+	}
+	httpSource := artifact.HTTP{Retries: defaultDownloadRetries, RetryWait: time.Second, Timeout: artifact.DefaultTimeout}
+	if c.httpSource != nil {
+		httpSource = *c.httpSource
+	}
+	if httpSource.Client == nil {
+		// Artifact repositories aren't part of the Flink cluster, so they
+		// get neither its client certificate nor, by default, its CA bundle
+		caCertFile := ""
+		if c.clusterCA {
+			caCertFile = c.tlsOptions.CACertFile
+		}
+		artifactTransport, err := artifact.NewTransport(caCertFile)
+		if err != nil {
+			return nil, err
+		}
+		httpSource.Client = &http.Client{Transport: artifactTransport}
+	}
+	if httpSource.Logger == nil {
+		httpSource.Logger = c.logger
+	}
+	var cache *artifact.Cache
+	if c.cache != nil {
+		cached := *c.cache
+		if cached.Logger == nil {
+			cached.Logger = c.logger
+		}
+		cache = &cached
+	}
+
+	return &Deployer{
+		operator: operations.RealOperator{
+			Filesystem: c.filesystem,
+			FlinkRestAPI: flink.NewVersionedFlinkRestClient(flink.FlinkRestClient{
+				BaseURL:           baseURL,
+				BasicAuthUsername: c.basicAuthUsername,
+				BasicAuthPassword: c.basicAuthPassword,
+				Authenticator:     authenticator,
+				Endpoints:         endpoints,
+				Policies:          policies,
+				CircuitBreaker:    c.circuitBreaker,
+				Client:            client,
+			}),
+			HTTP: httpSource,
+			// Maven repositories, object storage and OCI registries aren't
+			// part of the Flink cluster, so they don't share its TLS configuration
+			Maven: artifact.Maven{
+				Repositories: c.mavenRepositories,
+				Client:       cleanhttp.DefaultPooledClient(),
+				Logger:       c.logger,
+			},
+			S3:           s3,
+			OCI:          oci,
+			Sources:      c.sources,
+			Cache:        cache,
+			Verification: c.verification,
+			Policies:     policies,
+			Observer:     c.observer,
+			Logger:       c.logger,
+			Secrets:      c.secrets,
+		},
+	}, nil
+}
+
+// Deploy uploads the JAR file and runs it as a new job
+func (d *Deployer) Deploy(ctx context.Context, request DeployRequest) (DeployResult, error) {
+	return d.operator.Deploy(ctx, request)
+}
+
+// Update replaces the running job with the JAR file, restoring the new job
+// from a savepoint of the running job
+func (d *Deployer) Update(ctx context.Context, request UpdateRequest) (UpdateResult, error) {
+	return d.operator.Update(ctx, request)
+}
+
+// RetrieveJobs returns the jobs on the Flink cluster
+func (d *Deployer) RetrieveJobs(ctx context.Context) ([]Job, error) {
+	return d.operator.RetrieveJobs(ctx)
+}
+
+// Terminate cancels or stops a job
+func (d *Deployer) Terminate(ctx context.Context, request TerminateRequest) error {
+	return d.operator.Terminate(ctx, request)
+}
+
+// Plan returns the execution plan of a running job or of a JAR file
+func (d *Deployer) Plan(ctx context.Context, request PlanRequest) (JobPlan, error) {
+	return d.operator.Plan(ctx, request)
+}
+
+// Rescale changes the parallelism of a running job
+func (d *Deployer) Rescale(ctx context.Context, request RescaleRequest) error {
+	return d.operator.Rescale(ctx, request)
+}
+
+// Exceptions returns the exceptions of a job specified by ID or of
+// the most recently started job matching the base name
+func (d *Deployer) Exceptions(ctx context.Context, request ExceptionsRequest) (JobExceptions, error) {
+	return d.operator.Exceptions(ctx, request)
+}
+
+// Logs returns the (tail of the) log files of the job manager and task managers
+func (d *Deployer) Logs(ctx context.Context, request LogsRequest) ([]LogFile, error) {
+	return d.operator.Logs(ctx, request)
+}
+
+// Doctor checks the health of the Flink cluster
+func (d *Deployer) Doctor(ctx context.Context, request DoctorRequest) []CheckResult {
+	return d.operator.Doctor(ctx, request)
+}
@@ -0,0 +1,251 @@
+package deployer
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/ing-bank/flink-deployer/pkg/discovery"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * Flink stub
+ */
+func createTestFlinkServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/config", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"flink-version": "1.9.0"}`))
+	})
+	mux.HandleFunc("/jobs/overview", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jobs": [{"jid": "job-1", "name": "WordCount v1", "state": "RUNNING"}]}`))
+	})
+	mux.HandleFunc("/jars/upload", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"filename": "/tmp/flink-web/flink-web-upload/jar-1_wordcount.jar", "status": "success"}`))
+	})
+	mux.HandleFunc("/jars/jar-1_wordcount.jar/run", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jobid": "job-2"}`))
+	})
+	return httptest.NewServer(mux)
+}
+
+// writeTestJar writes a JAR file running com.example.WordCount
+func writeTestJar(path string) error {
+	content := new(bytes.Buffer)
+	archive := zip.NewWriter(content)
+	w, _ := archive.Create("META-INF/MANIFEST.MF")
+	w.Write([]byte("Main-Class: com.example.WordCount\n"))
+	archive.Create("com/example/WordCount.class")
+	err := archive.Close()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content.Bytes(), 0644)
+}
+
+func createTestJarFile(t *testing.T) string {
+	file, err := ioutil.TempFile("", "wordcount-*.jar")
+	assert.Nil(t, err)
+	file.Close()
+	assert.Nil(t, writeTestJar(file.Name()))
+	return file.Name()
+}
+
+type testDiscoverer struct {
+	baseURL   string
+	tlsConfig *tls.Config
+}
+
+func (d testDiscoverer) Discover(ctx context.Context) (discovery.Endpoint, error) {
+	return discovery.Endpoint{BaseURL: d.baseURL, TLSConfig: d.tlsConfig}, nil
+}
+
+/*
+ * New
+ */
+func TestNewShouldReturnAnErrorWithoutAnEndpoint(t *testing.T) {
+	_, err := New(context.Background())
+
+	assert.EqualError(t, err, "no Flink REST endpoint configured, use either WithBaseURL or WithDiscoverer")
+}
+
+func TestNewShouldReturnAnErrorWithBothABaseURLAndADiscoverer(t *testing.T) {
+	_, err := New(
+		context.Background(),
+		WithBaseURL("http://localhost:8081"),
+		WithDiscoverer(testDiscoverer{}),
+	)
+
+	assert.EqualError(t, err, "both WithBaseURL and WithDiscoverer are used, only one allowed")
+}
+
+func TestNewShouldReturnAnErrorForAnEmptyBaseURL(t *testing.T) {
+	_, err := New(context.Background(), WithBaseURL(""))
+
+	assert.EqualError(t, err, "empty base URL")
+}
+
+func TestNewShouldReturnAnErrorForANonPositiveTimeout(t *testing.T) {
+	_, err := New(context.Background(), WithBaseURL("http://localhost:8081"), WithTimeout(0))
+
+	assert.EqualError(t, err, "the timeout must be positive")
+}
+
+/*
+ * Operations
+ */
+func TestRetrieveJobsShouldReturnTheJobsOfTheCluster(t *testing.T) {
+	server := createTestFlinkServer()
+	defer server.Close()
+
+	d, err := New(context.Background(), WithBaseURL(server.URL))
+	assert.Nil(t, err)
+
+	jobs, err := d.RetrieveJobs(context.Background())
+
+	assert.Nil(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "job-1", jobs[0].ID)
+}
+
+func TestRetrieveJobsShouldUseTheDiscoveredEndpoint(t *testing.T) {
+	server := createTestFlinkServer()
+	defer server.Close()
+
+	d, err := New(context.Background(), WithDiscoverer(testDiscoverer{baseURL: server.URL}))
+	assert.Nil(t, err)
+
+	jobs, err := d.RetrieveJobs(context.Background())
+
+	assert.Nil(t, err)
+	assert.Len(t, jobs, 1)
+}
+
+func TestRetrieveJobsShouldKeepTheProxyOfTheTransportWithTheDiscoveredTLSConfig(t *testing.T) {
+	proxy := createTestFlinkServer()
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+	transport := cleanhttp.DefaultPooledTransport()
+	transport.Proxy = http.ProxyURL(proxyURL)
+
+	d, err := New(context.Background(),
+		WithTransport(transport),
+		WithDiscoverer(testDiscoverer{baseURL: "http://jobmanager.invalid:8081", tlsConfig: &tls.Config{}}),
+		WithLogger(nil),
+	)
+	assert.Nil(t, err)
+
+	jobs, err := d.RetrieveJobs(context.Background())
+
+	assert.Nil(t, err)
+	assert.Len(t, jobs, 1)
+}
+
+func TestRetrieveJobsShouldFailOverToTheNextBaseURL(t *testing.T) {
+	server := createTestFlinkServer()
+	defer server.Close()
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+
+	d, err := New(context.Background(), WithBaseURL(unavailable.URL, server.URL), WithLogger(nil))
+	assert.Nil(t, err)
+
+	jobs, err := d.RetrieveJobs(context.Background())
+
+	assert.Nil(t, err)
+	assert.Len(t, jobs, 1)
+}
+
+func TestDeployShouldReturnTheResultAndNotifyTheObserver(t *testing.T) {
+	server := createTestFlinkServer()
+	defer server.Close()
+	filename := createTestJarFile(t)
+	defer os.Remove(filename)
+
+	events := []EventType{}
+	d, err := New(
+		context.Background(),
+		WithBaseURL(server.URL),
+		WithTimeout(5*time.Second),
+		WithObserver(ObserverFunc(func(event Event) {
+			events = append(events, event.Type)
+		})),
+	)
+	assert.Nil(t, err)
+
+	result, err := d.Deploy(context.Background(), DeployRequest{
+		LocalFilename: filename,
+		Parallelism:   2,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "jar-1_wordcount.jar", result.JarID)
+	assert.Equal(t, "job-2", result.JobID)
+	assert.Equal(t, []EventType{EventJarUploaded, EventJobRunning}, events)
+}
+
+func TestDeployShouldLogTheProgressToTheLogger(t *testing.T) {
+	server := createTestFlinkServer()
+	defer server.Close()
+	filename := createTestJarFile(t)
+	defer os.Remove(filename)
+
+	output := new(bytes.Buffer)
+	d, err := New(context.Background(), WithBaseURL(server.URL), WithLogger(log.New(output, "", 0)))
+	assert.Nil(t, err)
+
+	_, err = d.Deploy(context.Background(), DeployRequest{LocalFilename: filename})
+
+	assert.Nil(t, err)
+	assert.Contains(t, output.String(), "Uploading JAR file\n")
+	assert.Contains(t, output.String(), "Running job\n")
+}
+
+type testSource struct {
+	fetched *[]string
+}
+
+func (s testSource) Supports(reference string) bool {
+	return strings.HasPrefix(reference, "test:")
+}
+
+func (s testSource) Fetch(ctx context.Context, reference string, dir string) (string, error) {
+	*s.fetched = append(*s.fetched, reference)
+	path := filepath.Join(dir, "wordcount.jar")
+	return path, writeTestJar(path)
+}
+
+func TestDeployShouldFetchTheArtifactFromTheArtifactSource(t *testing.T) {
+	server := createTestFlinkServer()
+	defer server.Close()
+
+	fetched := []string{}
+	d, err := New(
+		context.Background(),
+		WithBaseURL(server.URL),
+		WithArtifactSource(testSource{fetched: &fetched}),
+	)
+	assert.Nil(t, err)
+
+	result, err := d.Deploy(context.Background(), DeployRequest{
+		Artifact: "test:wordcount",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "job-2", result.JobID)
+	assert.Equal(t, []string{"test:wordcount"}, fetched)
+}
@@ -0,0 +1,270 @@
+package deployer
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ing-bank/flink-deployer/pkg/artifact"
+	"github.com/ing-bank/flink-deployer/pkg/discovery"
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/ing-bank/flink-deployer/pkg/secret"
+	"github.com/spf13/afero"
+)
+
+// config is the configuration the options of New are applied to
+type config struct {
+	baseURLs          []string
+	discoverer        discovery.Discoverer
+	basicAuthUsername string
+	basicAuthPassword string
+	authenticators    flink.Authenticators
+	tlsOptions        flink.TLSOptions
+	clusterCA         bool
+	transport         *http.Transport
+	timeout           time.Duration
+	policies          flink.Policies
+	circuitBreaker    *flink.CircuitBreaker
+	observer          Observer
+	filesystem        afero.Fs
+	mavenRepositories []artifact.MavenRepository
+	s3                artifact.S3
+	oci               artifact.OCI
+	httpSource        *artifact.HTTP
+	sources           artifact.Sources
+	cache             *artifact.Cache
+	verification      artifact.VerificationPolicy
+	secrets           secret.Resolver
+	logger            *log.Logger
+	loggerSet         bool
+}
+
+// An Option configures the Deployer created by New
+type Option func(c *config) error
+
+// WithBaseURL sets the base URL of the Flink REST API. Supply the URLs of
+// all JobManagers of a cluster running in high-availability mode to fail
+// over between them.
+func WithBaseURL(baseURLs ...string) Option {
+	return func(c *config) error {
+		for _, baseURL := range baseURLs {
+			if len(strings.TrimSpace(baseURL)) == 0 {
+				return errors.New("empty base URL")
+			}
+		}
+		c.baseURLs = append(c.baseURLs, baseURLs...)
+		return nil
+	}
+}
+
+// WithDiscoverer discovers the base URL of the Flink REST API when the
+// Deployer is created, instead of using a fixed base URL
+func WithDiscoverer(discoverer discovery.Discoverer) Option {
+	return func(c *config) error {
+		c.discoverer = discoverer
+		return nil
+	}
+}
+
+// WithBasicAuth authenticates the requests with basic authentication
+func WithBasicAuth(username string, password string) Option {
+	return func(c *config) error {
+		c.basicAuthUsername = username
+		c.basicAuthPassword = password
+		return nil
+	}
+}
+
+// WithAuthenticator authenticates the requests with the authenticator.
+// Multiple authenticators are applied in the order they are supplied.
+func WithAuthenticator(authenticator flink.Authenticator) Option {
+	return func(c *config) error {
+		c.authenticators = append(c.authenticators, authenticator)
+		return nil
+	}
+}
+
+// WithTLS configures TLS, and optionally mutual TLS, for the requests
+// to the Flink REST API
+func WithTLS(options flink.TLSOptions) Option {
+	return func(c *config) error {
+		c.tlsOptions = options
+		return nil
+	}
+}
+
+// WithClusterCAForArtifacts adds the CA bundle of the cluster, configured
+// with WithTLS, to the system roots the servers of remote JAR files are
+// verified with. Without it, only the system roots are trusted.
+func WithClusterCAForArtifacts() Option {
+	return func(c *config) error {
+		c.clusterCA = true
+		return nil
+	}
+}
+
+// WithTransport sets the HTTP transport of the requests to the Flink REST
+// API, which replaces WithTLS
+func WithTransport(transport *http.Transport) Option {
+	return func(c *config) error {
+		c.transport = transport
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of a single request, which defaults to 10 seconds
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *config) error {
+		if timeout <= 0 {
+			return errors.New("the timeout must be positive")
+		}
+		c.timeout = timeout
+		return nil
+	}
+}
+
+// WithPolicies sets the timeouts, retries and backoff per operation, which
+// default to flink.DefaultPolicies for the timeout of a single request
+func WithPolicies(policies flink.Policies) Option {
+	return func(c *config) error {
+		c.policies = policies
+		return nil
+	}
+}
+
+// WithCircuitBreaker stops sending requests to a cluster after
+// a number of consecutive failed requests
+func WithCircuitBreaker(circuitBreaker *flink.CircuitBreaker) Option {
+	return func(c *config) error {
+		c.circuitBreaker = circuitBreaker
+		return nil
+	}
+}
+
+// WithObserver notifies the observer of the progress of the operations
+func WithObserver(observer Observer) Option {
+	return func(c *config) error {
+		c.observer = observer
+		return nil
+	}
+}
+
+// WithFilesystem sets the filesystem of the savepoint directories,
+// which defaults to the filesystem of the OS
+func WithFilesystem(filesystem afero.Fs) Option {
+	return func(c *config) error {
+		c.filesystem = filesystem
+		return nil
+	}
+}
+
+// WithMavenRepositories sets the Maven repositories the artifacts of
+// jobs are resolved against in order, which defaults to Maven Central
+func WithMavenRepositories(repositories ...artifact.MavenRepository) Option {
+	return func(c *config) error {
+		for _, repository := range repositories {
+			if len(repository.URL) == 0 {
+				return errors.New("empty Maven repository URL")
+			}
+		}
+		c.mavenRepositories = repositories
+		return nil
+	}
+}
+
+// WithS3 configures the S3 or S3-compatible object storage JAR files
+// are downloaded from. Requests are sent anonymously without it.
+func WithS3(s3 artifact.S3) Option {
+	return func(c *config) error {
+		c.s3 = s3
+		return nil
+	}
+}
+
+// WithOCI configures the downloads of JAR files from OCI registries,
+// which are sent anonymously unless a Docker config is set
+func WithOCI(oci artifact.OCI) Option {
+	return func(c *config) error {
+		c.oci = oci
+		return nil
+	}
+}
+
+// WithHTTP configures the downloads of remote JAR files and artifact
+// URLs, which are retried three times by default and fail when they
+// stall for artifact.DefaultTimeout. The client defaults to
+// one verifying the servers with the system roots, see
+// WithClusterCAForArtifacts, and the proxy configured in the environment.
+func WithHTTP(source artifact.HTTP) Option {
+	return func(c *config) error {
+		c.httpSource = &source
+		return nil
+	}
+}
+
+// WithArtifactSource adds artifact sources, which take precedence over
+// the built-in Maven, S3, OCI and HTTP sources for the references they support
+func WithArtifactSource(sources ...artifact.Source) Option {
+	return func(c *config) error {
+		c.sources = append(c.sources, sources...)
+		return nil
+	}
+}
+
+// WithCache keeps the downloaded JAR files in the directory of the cache,
+// so deploying the same artifact again doesn't download it again
+func WithCache(cache artifact.Cache) Option {
+	return func(c *config) error {
+		if len(cache.Dir) == 0 {
+			return errors.New("the directory of the artifact cache is unspecified")
+		}
+		c.cache = &cache
+		return nil
+	}
+}
+
+// WithVerification sets the keys the detached signatures of JAR files
+// are verified with, and whether JAR files must be signed
+func WithVerification(policy artifact.VerificationPolicy) Option {
+	return func(c *config) error {
+		if policy.RequireSignature && len(policy.Keys) == 0 {
+			return errors.New("signatures are required, but no keys are trusted")
+		}
+		c.verification = policy
+		return nil
+	}
+}
+
+// WithSecrets sets the resolver of the secret references in program
+// arguments, e.g. ${vault:secret/data/kafka#password}. References to
+// files, e.g. ${file:/run/secrets/kafka-password}, are resolved without
+// it. The resolved secrets and the Vault token are added to the Redactor
+// of the resolver, which is created when it has none.
+func WithSecrets(resolver secret.Resolver) Option {
+	return func(c *config) error {
+		c.secrets = resolver
+		return nil
+	}
+}
+
+// WithLogger sets the logger receiving the progress of the operations,
+// the downloads and the retries of requests, which defaults to logging
+// to stderr with the secrets redacted. Supply nil to disable logging.
+// Use the Redactor of WithSecrets to redact the secrets from the logger.
+// Artifact sources and caches with a logger of their own keep using it.
+func WithLogger(logger *log.Logger) Option {
+	return func(c *config) error {
+		c.logger = logger
+		c.loggerSet = true
+		return nil
+	}
+}
+
+// logf logs to the logger of the configuration, unless it's nil
+func (c config) logf(format string, v ...interface{}) {
+	if c.logger != nil {
+		c.logger.Printf(format, v...)
+	}
+}
@@ -0,0 +1,46 @@
+package deployer
+
+import (
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/ing-bank/flink-deployer/pkg/operations"
+)
+
+// The requests of the operations of the Deployer
+type (
+	DeployRequest     = operations.Deploy
+	UpdateRequest     = operations.UpdateJob
+	TerminateRequest  = operations.TerminateJob
+	RescaleRequest    = operations.Rescale
+	PlanRequest       = operations.Plan
+	ExceptionsRequest = operations.Exceptions
+	LogsRequest       = operations.Logs
+	DoctorRequest     = operations.Doctor
+)
+
+// The results of the operations of the Deployer
+type (
+	DeployResult  = operations.DeployResult
+	UpdateResult  = operations.UpdateResult
+	Job           = flink.Job
+	JobPlan       = flink.Plan
+	JobExceptions = flink.JobExceptions
+	LogFile       = operations.LogFile
+	CheckResult   = operations.CheckResult
+)
+
+// The events reported to an Observer
+type (
+	Event        = operations.Event
+	EventType    = operations.EventType
+	Observer     = operations.Observer
+	ObserverFunc = operations.ObserverFunc
+)
+
+// The types of the events reported to an Observer
+const (
+	EventSavepointStarted   = operations.EventSavepointStarted
+	EventSavepointCompleted = operations.EventSavepointCompleted
+	EventJobCancelled       = operations.EventJobCancelled
+	EventJarUploaded        = operations.EventJarUploaded
+	EventJobRunning         = operations.EventJobRunning
+)
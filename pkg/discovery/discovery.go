@@ -0,0 +1,74 @@
+package discovery
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// An Endpoint is the discovered REST endpoint of a Flink cluster. The TLS
+// configuration and the authenticator, when set, are required to reach the
+// endpoint, e.g. because it is proxied by the Kubernetes API server.
+type Endpoint struct {
+	BaseURL       string
+	TLSConfig     *tls.Config
+	Authenticator flink.Authenticator
+}
+
+// A Discoverer discovers the REST endpoint of a Flink cluster
+type Discoverer interface {
+	Discover(ctx context.Context) (Endpoint, error)
+}
+
+// getJSON retrieves the URL and parses the JSON response into the target
+func getJSON(ctx context.Context, client *http.Client, url string, authenticator flink.Authenticator, target interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if authenticator != nil {
+		err = authenticator.Authenticate(req)
+		if err != nil {
+			return err
+		}
+	}
+
+	res, err := client.Do(req)
+	if err != nil && ctx.Err() == context.Canceled {
+		return flink.CategorizedErrorf(flink.ErrCanceled, "%v", err)
+	}
+	if err != nil {
+		return flink.CategorizedErrorf(flink.ErrClusterUnreachable, "%v", err)
+	}
+
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return flink.CategorizedErrorf(flink.ErrNotFound, "Unexpected response status %v with body %v", res.StatusCode, string(body[:]))
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return flink.CategorizedErrorf(flink.ErrUnauthorized, "Unexpected response status %v with body %v", res.StatusCode, string(body[:]))
+	default:
+		return fmt.Errorf("Unexpected response status %v with body %v", res.StatusCode, string(body[:]))
+	}
+
+	err = json.Unmarshal(body, target)
+	if err != nil {
+		return fmt.Errorf("Unable to parse API response as valid JSON: %v", string(body[:]))
+	}
+
+	return nil
+}
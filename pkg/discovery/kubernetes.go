@@ -0,0 +1,317 @@
+package discovery
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"gopkg.in/yaml.v2"
+)
+
+// serviceAccountDir is the directory in which Kubernetes mounts
+// the credentials of the service account of a pod
+var serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// Kubernetes discovers the REST endpoint of a Flink cluster running in
+// Kubernetes. Requests are sent through the service proxy of the API server,
+// so the cluster can be reached from outside Kubernetes. The credentials for
+// the API server are read from the kubeconfig or, when running in a pod,
+// from the service account.
+type Kubernetes struct {
+	Kubeconfig string
+	Context    string
+	InCluster  bool
+	Namespace  string
+	Service    string
+	Port       string
+}
+
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string            `yaml:"name"`
+		Cluster kubeconfigCluster `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string         `yaml:"name"`
+		User kubeconfigUser `yaml:"user"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string            `yaml:"name"`
+		Context kubeconfigContext `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+type kubeconfigCluster struct {
+	Server                   string `yaml:"server"`
+	CertificateAuthority     string `yaml:"certificate-authority"`
+	CertificateAuthorityData string `yaml:"certificate-authority-data"`
+	InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+	TLSServerName            string `yaml:"tls-server-name"`
+}
+
+type kubeconfigUser struct {
+	Token                 string      `yaml:"token"`
+	TokenFile             string      `yaml:"tokenFile"`
+	ClientCertificate     string      `yaml:"client-certificate"`
+	ClientCertificateData string      `yaml:"client-certificate-data"`
+	ClientKey             string      `yaml:"client-key"`
+	ClientKeyData         string      `yaml:"client-key-data"`
+	Username              string      `yaml:"username"`
+	Password              string      `yaml:"password"`
+	Exec                  interface{} `yaml:"exec"`
+	AuthProvider          interface{} `yaml:"auth-provider"`
+}
+
+type kubeconfigContext struct {
+	Cluster   string `yaml:"cluster"`
+	User      string `yaml:"user"`
+	Namespace string `yaml:"namespace"`
+}
+
+// apiServer contains everything needed to send requests to the API server
+type apiServer struct {
+	URL           string
+	Namespace     string
+	TLSConfig     *tls.Config
+	Authenticator flink.Authenticator
+}
+
+// A tokenFileAuthenticator reads the bearer token from a file on every
+// request, as Kubernetes rotates the tokens of service accounts
+type tokenFileAuthenticator struct {
+	Path string
+}
+
+// Authenticate adds the bearer token read from the file to the request
+func (a tokenFileAuthenticator) Authenticate(req *http.Request) error {
+	token, err := ioutil.ReadFile(a.Path)
+	if err != nil {
+		return fmt.Errorf("unable to read the token: %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	return nil
+}
+
+// kubeconfigPath returns the kubeconfig to use: the configured one, the
+// first existing file of the KUBECONFIG variable or the default location
+func (k Kubernetes) kubeconfigPath() string {
+	if len(k.Kubeconfig) > 0 {
+		return k.Kubeconfig
+	}
+
+	for _, path := range filepath.SplitList(os.Getenv("KUBECONFIG")) {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+
+	return filepath.Join(os.Getenv("HOME"), ".kube", "config")
+}
+
+// readData returns the base64 decoded inline data or the content of
+// the file, which is relative to the directory of the kubeconfig
+func readData(data string, path string, dir string) ([]byte, error) {
+	if len(data) > 0 {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if len(path) == 0 {
+		return nil, nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return ioutil.ReadFile(path)
+}
+
+func (k Kubernetes) inClusterAPIServer() (apiServer, error) {
+	host := os.Getenv("KUBERNETES_SERVICE_HOST")
+	port := os.Getenv("KUBERNETES_SERVICE_PORT")
+	if len(host) == 0 || len(port) == 0 {
+		return apiServer{}, errors.New("not running in a Kubernetes pod, `KUBERNETES_SERVICE_HOST` and `KUBERNETES_SERVICE_PORT` are unset")
+	}
+
+	caCert, err := ioutil.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return apiServer{}, fmt.Errorf("unable to read the CA certificate of the service account: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caCert) {
+		return apiServer{}, errors.New("no PEM encoded certificates found in the CA certificate of the service account")
+	}
+
+	namespace, err := ioutil.ReadFile(filepath.Join(serviceAccountDir, "namespace"))
+	if err != nil {
+		return apiServer{}, fmt.Errorf("unable to read the namespace of the service account: %v", err)
+	}
+
+	return apiServer{
+		URL:           "https://" + net.JoinHostPort(host, port),
+		Namespace:     strings.TrimSpace(string(namespace)),
+		TLSConfig:     &tls.Config{RootCAs: pool},
+		Authenticator: tokenFileAuthenticator{Path: filepath.Join(serviceAccountDir, "token")},
+	}, nil
+}
+
+func (k Kubernetes) kubeconfigAPIServer() (apiServer, error) {
+	path := k.kubeconfigPath()
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return apiServer{}, fmt.Errorf("unable to read the kubeconfig: %v", err)
+	}
+
+	config := kubeconfig{}
+	err = yaml.Unmarshal(content, &config)
+	if err != nil {
+		return apiServer{}, fmt.Errorf("unable to parse the kubeconfig %v: %v", path, err)
+	}
+
+	contextName := k.Context
+	if len(contextName) == 0 {
+		contextName = config.CurrentContext
+	}
+
+	var context *kubeconfigContext
+	for i := range config.Contexts {
+		if config.Contexts[i].Name == contextName {
+			context = &config.Contexts[i].Context
+		}
+	}
+	if context == nil {
+		return apiServer{}, fmt.Errorf("context \"%v\" not found in the kubeconfig %v", contextName, path)
+	}
+
+	var cluster *kubeconfigCluster
+	for i := range config.Clusters {
+		if config.Clusters[i].Name == context.Cluster {
+			cluster = &config.Clusters[i].Cluster
+		}
+	}
+	if cluster == nil {
+		return apiServer{}, fmt.Errorf("cluster \"%v\" not found in the kubeconfig %v", context.Cluster, path)
+	}
+
+	user := kubeconfigUser{}
+	for _, u := range config.Users {
+		if u.Name == context.User {
+			user = u.User
+		}
+	}
+	if user.Exec != nil || user.AuthProvider != nil {
+		return apiServer{}, fmt.Errorf("user \"%v\" uses an exec or auth provider plugin, which isn't supported", context.User)
+	}
+
+	dir := filepath.Dir(path)
+	tlsConfig := &tls.Config{
+		ServerName:         cluster.TLSServerName,
+		InsecureSkipVerify: cluster.InsecureSkipTLSVerify,
+	}
+
+	caCert, err := readData(cluster.CertificateAuthorityData, cluster.CertificateAuthority, dir)
+	if err != nil {
+		return apiServer{}, fmt.Errorf("unable to read the certificate authority of cluster \"%v\": %v", context.Cluster, err)
+	}
+	if caCert != nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return apiServer{}, fmt.Errorf("no PEM encoded certificates found in the certificate authority of cluster \"%v\"", context.Cluster)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	clientCert, err := readData(user.ClientCertificateData, user.ClientCertificate, dir)
+	if err != nil {
+		return apiServer{}, fmt.Errorf("unable to read the client certificate of user \"%v\": %v", context.User, err)
+	}
+	clientKey, err := readData(user.ClientKeyData, user.ClientKey, dir)
+	if err != nil {
+		return apiServer{}, fmt.Errorf("unable to read the client key of user \"%v\": %v", context.User, err)
+	}
+	if clientCert != nil || clientKey != nil {
+		certificate, err := tls.X509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return apiServer{}, fmt.Errorf("unable to load the client certificate of user \"%v\": %v", context.User, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	var authenticator flink.Authenticator
+	switch {
+	case len(user.Token) > 0:
+		authenticator = flink.BearerTokenAuthenticator{Token: user.Token}
+	case len(user.TokenFile) > 0:
+		tokenFile := user.TokenFile
+		if !filepath.IsAbs(tokenFile) {
+			tokenFile = filepath.Join(dir, tokenFile)
+		}
+		authenticator = tokenFileAuthenticator{Path: tokenFile}
+	case len(user.Username) > 0:
+		authenticator = flink.BasicAuthenticator{Username: user.Username, Password: user.Password}
+	}
+
+	namespace := context.Namespace
+	if len(namespace) == 0 {
+		namespace = "default"
+	}
+
+	return apiServer{
+		URL:           strings.TrimRight(cluster.Server, "/"),
+		Namespace:     namespace,
+		TLSConfig:     tlsConfig,
+		Authenticator: authenticator,
+	}, nil
+}
+
+// Discover returns the URL of the service proxy of the Flink
+// REST service together with the credentials for the API server
+func (k Kubernetes) Discover(ctx context.Context) (Endpoint, error) {
+	if len(k.Service) == 0 {
+		return Endpoint{}, errors.New("unspecified argument 'Service'")
+	}
+
+	var server apiServer
+	var err error
+	if k.InCluster {
+		server, err = k.inClusterAPIServer()
+	} else {
+		server, err = k.kubeconfigAPIServer()
+	}
+	if err != nil {
+		return Endpoint{}, err
+	}
+
+	namespace := k.Namespace
+	if len(namespace) == 0 {
+		namespace = server.Namespace
+	}
+
+	service := k.Service
+	if len(k.Port) > 0 {
+		service = fmt.Sprintf("%v:%v", service, k.Port)
+	}
+
+	endpoint := Endpoint{
+		BaseURL:       fmt.Sprintf("%v/api/v1/namespaces/%v/services/%v/proxy", server.URL, namespace, service),
+		TLSConfig:     server.TLSConfig,
+		Authenticator: server.Authenticator,
+	}
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: server.TLSConfig}}
+	err = getJSON(ctx, client, fmt.Sprintf("%v/api/v1/namespaces/%v/services/%v", server.URL, namespace, k.Service), server.Authenticator, &struct{}{})
+	if err != nil {
+		return Endpoint{}, flink.Errorf("retrieving service \"%v\" in namespace \"%v\" failed: %v", k.Service, namespace, err)
+	}
+
+	return endpoint, nil
+}
@@ -0,0 +1,241 @@
+package discovery
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/stretchr/testify/assert"
+)
+
+// createAPIServerTestServer creates a stand-in for the Kubernetes API server
+// which proxies the Flink REST service in the flink namespace
+func createAPIServerTestServer(t *testing.T, token string) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer "+token {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch req.URL.Path {
+		case "/api/v1/namespaces/flink/services/flink-jobmanager":
+			rw.Write([]byte(`{"kind": "Service", "metadata": {"name": "flink-jobmanager"}}`))
+		case "/api/v1/namespaces/flink/services/flink-jobmanager:rest/proxy/jobs/overview":
+			rw.Write([]byte(`{"jobs": [{"jid": "job-1", "name": "job", "state": "RUNNING"}]}`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+			rw.Write([]byte(`{"kind": "Status", "reason": "NotFound"}`))
+		}
+	}))
+}
+
+func writeKubeconfig(t *testing.T, dir string, server *httptest.Server, token string) string {
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	err := ioutil.WriteFile(filepath.Join(dir, "ca.crt"), caCert, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+- name: test
+  cluster:
+    server: %v
+    certificate-authority: ca.crt
+- name: inline
+  cluster:
+    server: %v
+    certificate-authority-data: %v
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: deployer
+    namespace: flink
+- name: inline
+  context:
+    cluster: inline
+    user: deployer
+- name: plugin
+  context:
+    cluster: test
+    user: plugin
+users:
+- name: deployer
+  user:
+    token: %v
+- name: plugin
+  user:
+    exec:
+      command: aws
+`, server.URL, server.URL, base64.StdEncoding.EncodeToString(caCert), token)
+
+	path := filepath.Join(dir, "config")
+	err = ioutil.WriteFile(path, []byte(kubeconfig), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestKubernetesDiscoverReturnsAnErrorWhenTheServiceIsUnspecified(t *testing.T) {
+	_, err := Kubernetes{}.Discover(context.Background())
+
+	assert.EqualError(t, err, "unspecified argument 'Service'")
+}
+
+func TestKubernetesDiscoverReturnsTheServiceProxyFromTheKubeconfig(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "kubernetes")
+	defer os.RemoveAll(dir)
+	server := createAPIServerTestServer(t, "secret")
+	defer server.Close()
+
+	endpoint, err := Kubernetes{
+		Kubeconfig: writeKubeconfig(t, dir, server, "secret"),
+		Service:    "flink-jobmanager",
+		Port:       "rest",
+	}.Discover(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, server.URL+"/api/v1/namespaces/flink/services/flink-jobmanager:rest/proxy", endpoint.BaseURL)
+
+	client := retryablehttp.NewClient()
+	client.HTTPClient = &http.Client{Transport: &http.Transport{TLSClientConfig: endpoint.TLSConfig}}
+	api := flink.FlinkRestClient{
+		BaseURL:       endpoint.BaseURL,
+		Authenticator: endpoint.Authenticator,
+		Client:        client,
+	}
+	jobs, err := api.RetrieveJobs(context.Background())
+
+	assert.Nil(t, err)
+	assert.Len(t, jobs, 1)
+}
+
+func TestKubernetesDiscoverUsesTheInlineCertificateAuthorityAndNamespaceOverride(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "kubernetes")
+	defer os.RemoveAll(dir)
+	server := createAPIServerTestServer(t, "secret")
+	defer server.Close()
+
+	endpoint, err := Kubernetes{
+		Kubeconfig: writeKubeconfig(t, dir, server, "secret"),
+		Context:    "inline",
+		Namespace:  "flink",
+		Service:    "flink-jobmanager",
+	}.Discover(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, server.URL+"/api/v1/namespaces/flink/services/flink-jobmanager/proxy", endpoint.BaseURL)
+}
+
+func TestKubernetesDiscoverReturnsANotFoundErrorForAnUnknownService(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "kubernetes")
+	defer os.RemoveAll(dir)
+	server := createAPIServerTestServer(t, "secret")
+	defer server.Close()
+
+	_, err := Kubernetes{
+		Kubeconfig: writeKubeconfig(t, dir, server, "secret"),
+		Service:    "unknown",
+	}.Discover(context.Background())
+
+	assert.Equal(t, flink.ErrNotFound, flink.Category(err))
+}
+
+func TestKubernetesDiscoverReturnsAnUnauthorizedErrorForAnInvalidToken(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "kubernetes")
+	defer os.RemoveAll(dir)
+	server := createAPIServerTestServer(t, "secret")
+	defer server.Close()
+
+	_, err := Kubernetes{
+		Kubeconfig: writeKubeconfig(t, dir, server, "wrong"),
+		Service:    "flink-jobmanager",
+	}.Discover(context.Background())
+
+	assert.Equal(t, flink.ErrUnauthorized, flink.Category(err))
+}
+
+func TestKubernetesDiscoverRejectsAuthenticationPlugins(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "kubernetes")
+	defer os.RemoveAll(dir)
+	server := createAPIServerTestServer(t, "secret")
+	defer server.Close()
+
+	_, err := Kubernetes{
+		Kubeconfig: writeKubeconfig(t, dir, server, "secret"),
+		Context:    "plugin",
+		Service:    "flink-jobmanager",
+	}.Discover(context.Background())
+
+	assert.EqualError(t, err, "user \"plugin\" uses an exec or auth provider plugin, which isn't supported")
+}
+
+func TestKubernetesDiscoverReturnsAnErrorForAnUnknownContext(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "kubernetes")
+	defer os.RemoveAll(dir)
+	server := createAPIServerTestServer(t, "secret")
+	defer server.Close()
+	path := writeKubeconfig(t, dir, server, "secret")
+
+	_, err := Kubernetes{
+		Kubeconfig: path,
+		Context:    "unknown",
+		Service:    "flink-jobmanager",
+	}.Discover(context.Background())
+
+	assert.EqualError(t, err, fmt.Sprintf("context \"unknown\" not found in the kubeconfig %v", path))
+}
+
+func TestKubernetesDiscoverUsesTheServiceAccountInCluster(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "serviceaccount")
+	defer os.RemoveAll(dir)
+	server := createAPIServerTestServer(t, "secret")
+	defer server.Close()
+
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	ioutil.WriteFile(filepath.Join(dir, "ca.crt"), caCert, 0600)
+	ioutil.WriteFile(filepath.Join(dir, "namespace"), []byte("flink"), 0600)
+	ioutil.WriteFile(filepath.Join(dir, "token"), []byte("secret\n"), 0600)
+
+	originalDir := serviceAccountDir
+	serviceAccountDir = dir
+	defer func() { serviceAccountDir = originalDir }()
+
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	os.Setenv("KUBERNETES_SERVICE_HOST", host)
+	os.Setenv("KUBERNETES_SERVICE_PORT", port)
+	defer os.Unsetenv("KUBERNETES_SERVICE_HOST")
+	defer os.Unsetenv("KUBERNETES_SERVICE_PORT")
+
+	endpoint, err := Kubernetes{
+		InCluster: true,
+		Service:   "flink-jobmanager",
+		Port:      "rest",
+	}.Discover(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, server.URL+"/api/v1/namespaces/flink/services/flink-jobmanager:rest/proxy", endpoint.BaseURL)
+}
+
+func TestKubernetesDiscoverReturnsAnErrorInClusterOutsideOfAPod(t *testing.T) {
+	_, err := Kubernetes{
+		InCluster: true,
+		Service:   "flink-jobmanager",
+	}.Discover(context.Background())
+
+	assert.EqualError(t, err, "not running in a Kubernetes pod, `KUBERNETES_SERVICE_HOST` and `KUBERNETES_SERVICE_PORT` are unset")
+}
@@ -0,0 +1,117 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// YARN discovers the REST endpoint of a Flink cluster running as a YARN
+// application through the ResourceManager REST API. The application is
+// either specified by ID or by name, in which case the most recently
+// started running application with that name is used.
+type YARN struct {
+	ResourceManagerURL string
+	ApplicationID      string
+	ApplicationName    string
+	Client             *http.Client
+}
+
+// YARNApplication is a YARN application as returned by the ResourceManager
+type YARNApplication struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	State       string `json:"state"`
+	TrackingURL string `json:"trackingUrl"`
+	StartedTime int64  `json:"startedTime"`
+}
+
+type yarnApplicationResponse struct {
+	App YARNApplication `json:"app"`
+}
+
+type yarnApplicationsResponse struct {
+	Apps *struct {
+		App []YARNApplication `json:"app"`
+	} `json:"apps"`
+}
+
+func (y YARN) client() *http.Client {
+	if y.Client == nil {
+		return &http.Client{}
+	}
+	return y.Client
+}
+
+func (y YARN) constructURL(path string) string {
+	return fmt.Sprintf("%v/ws/v1/cluster/%v", strings.TrimRight(y.ResourceManagerURL, "/"), path)
+}
+
+func (y YARN) findApplication(ctx context.Context) (YARNApplication, error) {
+	if len(y.ApplicationID) > 0 {
+		response := yarnApplicationResponse{}
+		err := getJSON(ctx, y.client(), y.constructURL("apps/"+url.PathEscape(y.ApplicationID)), nil, &response)
+		if err != nil {
+			return YARNApplication{}, flink.Errorf("retrieving YARN application \"%v\" failed: %v", y.ApplicationID, err)
+		}
+		return response.App, nil
+	}
+
+	response := yarnApplicationsResponse{}
+	err := getJSON(ctx, y.client(), y.constructURL("apps?states=RUNNING"), nil, &response)
+	if err != nil {
+		return YARNApplication{}, flink.Errorf("retrieving YARN applications failed: %v", err)
+	}
+
+	var latest YARNApplication
+	found := false
+	if response.Apps != nil {
+		for _, app := range response.Apps.App {
+			if app.Name != y.ApplicationName {
+				continue
+			}
+			if !found || app.StartedTime > latest.StartedTime {
+				latest = app
+				found = true
+			}
+		}
+	}
+
+	if !found {
+		return YARNApplication{}, flink.CategorizedErrorf(flink.ErrNotFound, "no running YARN application found with name \"%v\"", y.ApplicationName)
+	}
+
+	return latest, nil
+}
+
+// Discover returns the tracking URL of the YARN application, which proxies
+// the requests to the REST endpoint of the Flink JobManager
+func (y YARN) Discover(ctx context.Context) (Endpoint, error) {
+	if len(y.ResourceManagerURL) == 0 {
+		return Endpoint{}, errors.New("unspecified argument 'ResourceManagerURL'")
+	}
+	if len(y.ApplicationID) == 0 && len(y.ApplicationName) == 0 {
+		return Endpoint{}, errors.New("both properties 'ApplicationID' and 'ApplicationName' are unspecified")
+	}
+
+	app, err := y.findApplication(ctx)
+	if err != nil {
+		return Endpoint{}, err
+	}
+
+	if app.State != "RUNNING" {
+		return Endpoint{}, fmt.Errorf("YARN application \"%v\" is %v, expected RUNNING", app.ID, app.State)
+	}
+	if len(app.TrackingURL) == 0 {
+		return Endpoint{}, fmt.Errorf("YARN application \"%v\" has no tracking URL", app.ID)
+	}
+
+	return Endpoint{
+		BaseURL: strings.TrimRight(app.TrackingURL, "/"),
+	}, nil
+}
@@ -0,0 +1,91 @@
+package discovery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/stretchr/testify/assert"
+)
+
+func createResourceManagerTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.String() {
+		case "/ws/v1/cluster/apps/application_1_0001":
+			rw.Write([]byte(`{"app": {"id": "application_1_0001", "name": "flink-session", "state": "RUNNING", "trackingUrl": "http://rm:8088/proxy/application_1_0001/"}}`))
+		case "/ws/v1/cluster/apps/application_1_0002":
+			rw.Write([]byte(`{"app": {"id": "application_1_0002", "name": "flink-session", "state": "FINISHED", "trackingUrl": "http://rm:8088/proxy/application_1_0002/"}}`))
+		case "/ws/v1/cluster/apps?states=RUNNING":
+			rw.Write([]byte(`{"apps": {"app": [
+				{"id": "application_1_0003", "name": "flink-session", "state": "RUNNING", "trackingUrl": "http://rm:8088/proxy/application_1_0003/", "startedTime": 3},
+				{"id": "application_1_0004", "name": "flink-session", "state": "RUNNING", "trackingUrl": "http://rm:8088/proxy/application_1_0004/", "startedTime": 4},
+				{"id": "application_1_0005", "name": "spark", "state": "RUNNING", "trackingUrl": "http://rm:8088/proxy/application_1_0005/", "startedTime": 5}
+			]}}`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+			rw.Write([]byte(`{"RemoteException": {"message": "not found"}}`))
+		}
+	}))
+}
+
+func TestYARNDiscoverReturnsAnErrorWhenTheResourceManagerIsUnspecified(t *testing.T) {
+	_, err := YARN{ApplicationID: "application_1_0001"}.Discover(context.Background())
+
+	assert.EqualError(t, err, "unspecified argument 'ResourceManagerURL'")
+}
+
+func TestYARNDiscoverReturnsAnErrorWhenTheApplicationIsUnspecified(t *testing.T) {
+	_, err := YARN{ResourceManagerURL: "http://rm:8088"}.Discover(context.Background())
+
+	assert.EqualError(t, err, "both properties 'ApplicationID' and 'ApplicationName' are unspecified")
+}
+
+func TestYARNDiscoverReturnsTheTrackingURLOfTheApplicationID(t *testing.T) {
+	server := createResourceManagerTestServer(t)
+	defer server.Close()
+
+	endpoint, err := YARN{ResourceManagerURL: server.URL, ApplicationID: "application_1_0001"}.Discover(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, "http://rm:8088/proxy/application_1_0001", endpoint.BaseURL)
+}
+
+func TestYARNDiscoverReturnsAnErrorWhenTheApplicationIsNotRunning(t *testing.T) {
+	server := createResourceManagerTestServer(t)
+	defer server.Close()
+
+	_, err := YARN{ResourceManagerURL: server.URL, ApplicationID: "application_1_0002"}.Discover(context.Background())
+
+	assert.EqualError(t, err, "YARN application \"application_1_0002\" is FINISHED, expected RUNNING")
+}
+
+func TestYARNDiscoverReturnsANotFoundErrorForAnUnknownApplicationID(t *testing.T) {
+	server := createResourceManagerTestServer(t)
+	defer server.Close()
+
+	_, err := YARN{ResourceManagerURL: server.URL, ApplicationID: "application_1_0009"}.Discover(context.Background())
+
+	assert.Equal(t, flink.ErrNotFound, flink.Category(err))
+}
+
+func TestYARNDiscoverReturnsTheMostRecentlyStartedApplicationWithTheName(t *testing.T) {
+	server := createResourceManagerTestServer(t)
+	defer server.Close()
+
+	endpoint, err := YARN{ResourceManagerURL: server.URL, ApplicationName: "flink-session"}.Discover(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, "http://rm:8088/proxy/application_1_0004", endpoint.BaseURL)
+}
+
+func TestYARNDiscoverReturnsANotFoundErrorForAnUnknownApplicationName(t *testing.T) {
+	server := createResourceManagerTestServer(t)
+	defer server.Close()
+
+	_, err := YARN{ResourceManagerURL: server.URL, ApplicationName: "unknown"}.Discover(context.Background())
+
+	assert.EqualError(t, err, "no running YARN application found with name \"unknown\"")
+	assert.Equal(t, flink.ErrNotFound, flink.Category(err))
+}
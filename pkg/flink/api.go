@@ -0,0 +1,29 @@
+package flink
+
+import "context"
+
+// FlinkRestAPI is an interface representing the ability to execute
+// multiple HTTP requests against the Apache Flink API. Requests are
+// canceled when their context is done.
+type FlinkRestAPI interface {
+	Terminate(ctx context.Context, jobID string, mode string) error
+	StopJob(ctx context.Context, jobID string, targetDirectory string) (CreateSavepointResponse, error)
+	CreateSavepoint(ctx context.Context, jobID string, savepointPath string) (CreateSavepointResponse, error)
+	MonitorSavepointCreation(ctx context.Context, jobID string, requestID string) (MonitorSavepointCreationResponse, error)
+	RetrieveJobs(ctx context.Context) ([]Job, error)
+	RunJar(ctx context.Context, jarID string, entryClass string, jarArgs []string, parallelism int, savepointPath string, allowNonRestoredState bool, options RunJarOptions) (RunJarResponse, error)
+	CheckRunJarOptions(ctx context.Context, options RunJarOptions) error
+	UploadJar(ctx context.Context, filename string) (UploadJarResponse, error)
+	DeleteJar(ctx context.Context, jarID string) error
+	RetrieveJobPlan(ctx context.Context, jobID string) (Plan, error)
+	RetrieveJarPlan(ctx context.Context, jarID string, entryClass string, jarArgs []string, parallelism int) (Plan, error)
+	RetrieveClusterOverview(ctx context.Context) (ClusterOverview, error)
+	RetrieveTaskManagers(ctx context.Context) ([]TaskManager, error)
+	Rescale(ctx context.Context, jobID string, parallelism int) (RescaleResponse, error)
+	MonitorRescaling(ctx context.Context, jobID string, requestID string) (MonitorRescalingResponse, error)
+	RetrieveJobExceptions(ctx context.Context, jobID string) (JobExceptions, error)
+	RetrieveJobManagerLog(ctx context.Context, lines int) (string, error)
+	RetrieveTaskManagerLog(ctx context.Context, taskManagerID string, lines int) (string, error)
+	RetrieveCheckpoints(ctx context.Context, jobID string) (CheckpointStatistics, error)
+	RetrieveBackPressure(ctx context.Context, jobID string, vertexID string) (VertexBackPressure, error)
+}
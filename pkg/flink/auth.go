@@ -0,0 +1,242 @@
+package flink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// An Authenticator adds credentials to the requests
+// sent to the Flink REST API
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// An invalidator is an Authenticator which caches credentials
+// that have to be dropped when they are rejected by the cluster
+type invalidator interface {
+	Invalidate()
+}
+
+// refreshable returns whether the authenticator caches
+// credentials which are renewed after invalidating them
+func refreshable(authenticator Authenticator) bool {
+	switch a := authenticator.(type) {
+	case Authenticators:
+		for _, authenticator := range a {
+			if refreshable(authenticator) {
+				return true
+			}
+		}
+		return false
+	case invalidator:
+		return true
+	}
+	return false
+}
+
+// A BasicAuthenticator authenticates requests with HTTP basic authentication
+type BasicAuthenticator struct {
+	Username string
+	Password string
+}
+
+// Authenticate adds the basic authentication header to the request
+func (a BasicAuthenticator) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Password)
+	return nil
+}
+
+// A BearerTokenAuthenticator authenticates requests with a static bearer token
+type BearerTokenAuthenticator struct {
+	Token string
+}
+
+// Authenticate adds the bearer token to the request
+func (a BearerTokenAuthenticator) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.Token)
+	return nil
+}
+
+// A HeaderAuthenticator adds arbitrary headers to the requests,
+// e.g. the API key expected by a proxy in front of Flink
+type HeaderAuthenticator struct {
+	Headers map[string]string
+}
+
+// Authenticate adds the headers to the request
+func (a HeaderAuthenticator) Authenticate(req *http.Request) error {
+	for name, value := range a.Headers {
+		req.Header.Set(name, value)
+	}
+	return nil
+}
+
+// Authenticators combines multiple authenticators,
+// which are applied in order
+type Authenticators []Authenticator
+
+// Authenticate applies all authenticators to the request
+func (a Authenticators) Authenticate(req *http.Request) error {
+	for _, authenticator := range a {
+		err := authenticator.Authenticate(req)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Invalidate drops the cached credentials of all authenticators
+func (a Authenticators) Invalidate() {
+	for _, authenticator := range a {
+		if i, ok := authenticator.(invalidator); ok {
+			i.Invalidate()
+		}
+	}
+}
+
+// tokenExpiryMargin is the time before its expiry at which a cached access
+// token is refreshed. For short-lived tokens it's at most half their lifetime,
+// so they are still reused.
+const tokenExpiryMargin = 30 * time.Second
+
+// tokenExpiry returns the time at which a token with the given lifetime is refreshed
+func tokenExpiry(now time.Time, lifetime time.Duration) time.Time {
+	margin := tokenExpiryMargin
+	if margin > lifetime/2 {
+		margin = lifetime / 2
+	}
+	return now.Add(lifetime - margin)
+}
+
+// A ClientCredentialsAuthenticator authenticates requests with an access token
+// obtained through the OAuth2 client credentials flow. The token is cached
+// until shortly before it expires or until it is rejected by the cluster.
+type ClientCredentialsAuthenticator struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	Client       *http.Client
+
+	mutex  *sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// NewClientCredentialsAuthenticator creates a ClientCredentialsAuthenticator which
+// requests tokens from the token URL through the supplied HTTP client
+func NewClientCredentialsAuthenticator(tokenURL string, clientID string, clientSecret string, scopes []string, client *http.Client) *ClientCredentialsAuthenticator {
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	return &ClientCredentialsAuthenticator{
+		TokenURL:     tokenURL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       scopes,
+		Client:       client,
+		mutex:        &sync.Mutex{},
+	}
+}
+
+// Authenticate adds the cached or a newly requested access token to the request
+func (a *ClientCredentialsAuthenticator) Authenticate(req *http.Request) error {
+	token, err := a.accessToken(req.Context())
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// Invalidate drops the cached access token
+func (a *ClientCredentialsAuthenticator) Invalidate() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.token = ""
+}
+
+func (a *ClientCredentialsAuthenticator) accessToken(ctx context.Context) (string, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if len(a.token) > 0 && (a.expiry.IsZero() || time.Now().Before(a.expiry)) {
+		return a.token, nil
+	}
+
+	response, err := a.requestToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	a.token = response.AccessToken
+	a.expiry = time.Time{}
+	if response.ExpiresIn > 0 {
+		a.expiry = tokenExpiry(time.Now(), time.Duration(response.ExpiresIn)*time.Second)
+	}
+
+	return a.token, nil
+}
+
+func (a *ClientCredentialsAuthenticator) requestToken(ctx context.Context) (tokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if len(a.Scopes) > 0 {
+		form.Set("scope", strings.Join(a.Scopes, " "))
+	}
+
+	req, err := http.NewRequest("POST", a.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return tokenResponse{}, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(a.ClientID), url.QueryEscape(a.ClientSecret))
+
+	res, err := a.Client.Do(req)
+	if err != nil {
+		return tokenResponse{}, Errorf("unable to request an access token: %v", transportError(ctx, err))
+	}
+
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return tokenResponse{}, err
+	}
+
+	if res.StatusCode != 200 {
+		return tokenResponse{}, CategorizedErrorf(ErrUnauthorized, "unable to request an access token: unexpected response status %v with body %v", res.StatusCode, string(body[:]))
+	}
+
+	response := tokenResponse{}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return tokenResponse{}, fmt.Errorf("Unable to parse API response as valid JSON: %v", string(body[:]))
+	}
+	if len(response.AccessToken) == 0 {
+		return tokenResponse{}, CategorizedErrorf(ErrUnauthorized, "the token endpoint returned no access token")
+	}
+	if len(response.TokenType) > 0 && !strings.EqualFold(response.TokenType, "bearer") {
+		return tokenResponse{}, fmt.Errorf("unsupported token type \"%v\"", response.TokenType)
+	}
+
+	return response, nil
+}
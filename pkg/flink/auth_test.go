@@ -0,0 +1,231 @@
+package flink
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAuthenticatedRequest(t *testing.T, authenticator Authenticator) *http.Request {
+	req, _ := http.NewRequest("GET", "http://localhost:80/jobs", nil)
+	err := authenticator.Authenticate(req)
+	assert.Nil(t, err)
+	return req
+}
+
+// createTokenServer creates a stand-in for an OAuth2 token endpoint
+// which counts the number of issued tokens
+func createTokenServer(t *testing.T, expiresIn int, issued *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		clientID, clientSecret, ok := req.BasicAuth()
+		if !ok || clientID != "deployer" || clientSecret != "secret" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			rw.Write([]byte(`{"error": "invalid_client"}`))
+			return
+		}
+
+		assert.Equal(t, "POST", req.Method)
+		assert.Equal(t, "client_credentials", req.FormValue("grant_type"))
+		assert.Equal(t, "flink:read flink:write", req.FormValue("scope"))
+
+		*issued++
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Write([]byte(fmt.Sprintf(`{"access_token": "token-%v", "token_type": "Bearer", "expires_in": %v}`, *issued, expiresIn)))
+	}))
+}
+
+/*
+ * Static authenticators
+ */
+func TestBasicAuthenticatorAddsTheBasicAuthenticationHeader(t *testing.T) {
+	req := newAuthenticatedRequest(t, BasicAuthenticator{Username: "username", Password: "password"})
+
+	assert.Equal(t, "Basic dXNlcm5hbWU6cGFzc3dvcmQ=", req.Header.Get("Authorization"))
+}
+
+func TestBearerTokenAuthenticatorAddsTheBearerToken(t *testing.T) {
+	req := newAuthenticatedRequest(t, BearerTokenAuthenticator{Token: "token"})
+
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+}
+
+func TestHeaderAuthenticatorAddsTheHeaders(t *testing.T) {
+	req := newAuthenticatedRequest(t, HeaderAuthenticator{Headers: map[string]string{"X-Api-Key": "key", "X-Tenant": "team"}})
+
+	assert.Equal(t, "key", req.Header.Get("X-Api-Key"))
+	assert.Equal(t, "team", req.Header.Get("X-Tenant"))
+}
+
+func TestAuthenticatorsApplyAllAuthenticatorsInOrder(t *testing.T) {
+	req := newAuthenticatedRequest(t, Authenticators{
+		BasicAuthenticator{Username: "username", Password: "password"},
+		BearerTokenAuthenticator{Token: "token"},
+		HeaderAuthenticator{Headers: map[string]string{"X-Api-Key": "key"}},
+	})
+
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+	assert.Equal(t, "key", req.Header.Get("X-Api-Key"))
+}
+
+func TestNewRequestShouldApplyTheAuthenticator(t *testing.T) {
+	api := FlinkRestClient{
+		BaseURL:       "http://localhost:80",
+		Authenticator: BearerTokenAuthenticator{Token: "token"},
+		Client:        &retryablehttp.Client{},
+	}
+
+	req, err := api.newRequest(context.Background(), "GET", "jobs", nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+}
+
+/*
+ * Client credentials
+ */
+func TestClientCredentialsAuthenticatorCachesTheToken(t *testing.T) {
+	issued := 0
+	server := createTokenServer(t, 3600, &issued)
+	defer server.Close()
+
+	authenticator := NewClientCredentialsAuthenticator(server.URL, "deployer", "secret", []string{"flink:read", "flink:write"}, nil)
+	first := newAuthenticatedRequest(t, authenticator)
+	second := newAuthenticatedRequest(t, authenticator)
+
+	assert.Equal(t, "Bearer token-1", first.Header.Get("Authorization"))
+	assert.Equal(t, "Bearer token-1", second.Header.Get("Authorization"))
+	assert.Equal(t, 1, issued)
+}
+
+func TestClientCredentialsAuthenticatorRefreshesAnExpiringToken(t *testing.T) {
+	issued := 0
+	server := createTokenServer(t, 3600, &issued)
+	defer server.Close()
+
+	authenticator := NewClientCredentialsAuthenticator(server.URL, "deployer", "secret", []string{"flink:read", "flink:write"}, nil)
+	newAuthenticatedRequest(t, authenticator)
+	authenticator.expiry = time.Now().Add(-time.Second)
+	req := newAuthenticatedRequest(t, authenticator)
+
+	assert.Equal(t, "Bearer token-2", req.Header.Get("Authorization"))
+	assert.Equal(t, 2, issued)
+}
+
+func TestClientCredentialsAuthenticatorReusesAShortLivedToken(t *testing.T) {
+	issued := 0
+	server := createTokenServer(t, 10, &issued)
+	defer server.Close()
+
+	authenticator := NewClientCredentialsAuthenticator(server.URL, "deployer", "secret", []string{"flink:read", "flink:write"}, nil)
+	newAuthenticatedRequest(t, authenticator)
+	req := newAuthenticatedRequest(t, authenticator)
+
+	assert.Equal(t, "Bearer token-1", req.Header.Get("Authorization"))
+	assert.Equal(t, 1, issued)
+}
+
+func TestTokenExpiryShouldSubtractTheMargin(t *testing.T) {
+	now := time.Now()
+
+	assert.Equal(t, now.Add(time.Hour-tokenExpiryMargin), tokenExpiry(now, time.Hour))
+}
+
+func TestTokenExpiryShouldClampTheMarginToHalfTheLifetime(t *testing.T) {
+	now := time.Now()
+
+	assert.Equal(t, now.Add(5*time.Second), tokenExpiry(now, 10*time.Second))
+}
+
+func TestClientCredentialsAuthenticatorReturnsAnUnauthorizedErrorForInvalidCredentials(t *testing.T) {
+	issued := 0
+	server := createTokenServer(t, 3600, &issued)
+	defer server.Close()
+
+	authenticator := NewClientCredentialsAuthenticator(server.URL, "deployer", "wrong", nil, nil)
+	req, _ := http.NewRequest("GET", "http://localhost:80/jobs", nil)
+	err := authenticator.Authenticate(req)
+
+	assert.EqualError(t, err, "unable to request an access token: unexpected response status 401 with body {\"error\": \"invalid_client\"}")
+	assert.Equal(t, ErrUnauthorized, Category(err))
+}
+
+func TestClientCredentialsTokenIsRefreshedWhenTheClusterRejectsIt(t *testing.T) {
+	issued := 0
+	tokenServer := createTokenServer(t, 3600, &issued)
+	defer tokenServer.Close()
+
+	requests := 0
+	flinkServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		if req.Header.Get("Authorization") != "Bearer token-2" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rw.Write([]byte(`{"jobs": []}`))
+	}))
+	defer flinkServer.Close()
+
+	api := FlinkRestClient{
+		BaseURL:       flinkServer.URL,
+		Authenticator: NewClientCredentialsAuthenticator(tokenServer.URL, "deployer", "secret", []string{"flink:read", "flink:write"}, nil),
+		Client:        retryablehttp.NewClient(),
+	}
+
+	_, err := api.RetrieveJobs(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, issued)
+	assert.Equal(t, 2, requests)
+}
+
+func TestClientCredentialsTokenIsRefreshedOnlyOnceWhenTheClusterKeepsRejectingIt(t *testing.T) {
+	issued := 0
+	tokenServer := createTokenServer(t, 3600, &issued)
+	defer tokenServer.Close()
+
+	requests := 0
+	flinkServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		rw.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer flinkServer.Close()
+
+	api := FlinkRestClient{
+		BaseURL:       flinkServer.URL,
+		Authenticator: NewClientCredentialsAuthenticator(tokenServer.URL, "deployer", "secret", []string{"flink:read", "flink:write"}, nil),
+		Client:        retryablehttp.NewClient(),
+	}
+
+	_, err := api.RetrieveJobs(context.Background())
+	assert.Equal(t, ErrUnauthorized, Category(err))
+	assert.Equal(t, 2, issued)
+	assert.Equal(t, 2, requests)
+
+	_, err = api.RetrieveJobs(context.Background())
+	assert.Equal(t, ErrUnauthorized, Category(err))
+	assert.Equal(t, 4, issued)
+}
+
+func TestStaticCredentialsAreNotRetriedWhenTheClusterRejectsThem(t *testing.T) {
+	requests := 0
+	flinkServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		rw.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer flinkServer.Close()
+
+	api := FlinkRestClient{
+		BaseURL:       flinkServer.URL,
+		Authenticator: Authenticators{BearerTokenAuthenticator{Token: "token"}},
+		Client:        retryablehttp.NewClient(),
+	}
+
+	_, err := api.RetrieveJobs(context.Background())
+	assert.Equal(t, ErrUnauthorized, Category(err))
+	assert.Equal(t, 1, requests)
+}
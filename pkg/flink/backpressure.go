@@ -0,0 +1,59 @@
+package flink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// SubtaskBackPressure represents the back pressure
+// of a single subtask of a vertex
+type SubtaskBackPressure struct {
+	Subtask           int     `json:"subtask"`
+	BackPressureLevel string  `json:"backpressure-level"`
+	Ratio             float64 `json:"ratio"`
+}
+
+// VertexBackPressure represents the response body
+// used by the vertex back pressure API
+type VertexBackPressure struct {
+	Status            string                `json:"status"`
+	BackPressureLevel string                `json:"backpressure-level"`
+	EndTimestamp      int64                 `json:"end-timestamp"`
+	Subtasks          []SubtaskBackPressure `json:"subtasks"`
+}
+
+// RetrieveBackPressure returns the back pressure of a vertex of a job.
+// The first request triggers the sampling, so the status is only "ok"
+// once a sample has been taken.
+func (c FlinkRestClient) RetrieveBackPressure(ctx context.Context, jobID string, vertexID string) (VertexBackPressure, error) {
+	req, err := c.newRequest(ctx, "GET", c.constructURL(fmt.Sprintf("jobs/%v/vertices/%v/backpressure", jobID, vertexID)), nil)
+	if err != nil {
+		return VertexBackPressure{}, err
+	}
+
+	res, err := c.do(OperationRead, req)
+	if err != nil {
+		return VertexBackPressure{}, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return VertexBackPressure{}, err
+	}
+
+	if res.StatusCode != 200 {
+		return VertexBackPressure{}, newAPIError(res, body)
+	}
+
+	response := VertexBackPressure{}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return VertexBackPressure{}, fmt.Errorf("Unable to parse API response as valid JSON: %v", string(body[:]))
+	}
+
+	return response, nil
+}
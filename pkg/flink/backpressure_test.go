@@ -0,0 +1,38 @@
+package flink
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetrieveBackPressureReturnsAnErrorWhenItCannotDeserializeTheResponseAsJSON(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/1/vertices/v1/backpressure", "", http.StatusOK, `{"status: "ok"}`)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	_, err := api.RetrieveBackPressure(context.Background(), "1", "v1")
+
+	assert.EqualError(t, err, "Unable to parse API response as valid JSON: {\"status: \"ok\"}")
+}
+
+func TestRetrieveBackPressureCorrectlyReturnsTheBackPressure(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/1/vertices/v1/backpressure", "", http.StatusOK, `{"status":"ok","backpressure-level":"high","end-timestamp":1,"subtasks":[{"subtask":0,"backpressure-level":"high","ratio":0.8}]}`)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	backPressure, err := api.RetrieveBackPressure(context.Background(), "1", "v1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "high", backPressure.BackPressureLevel)
+	assert.Equal(t, 0.8, backPressure.Subtasks[0].Ratio)
+}
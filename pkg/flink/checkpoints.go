@@ -0,0 +1,92 @@
+package flink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// CheckpointCounts represents the number of
+// checkpoints of a job per status
+type CheckpointCounts struct {
+	Restored   int `json:"restored"`
+	Total      int `json:"total"`
+	InProgress int `json:"in_progress"`
+	Completed  int `json:"completed"`
+	Failed     int `json:"failed"`
+}
+
+// A Checkpoint is a representation of a single checkpoint
+type Checkpoint struct {
+	ID                 int64  `json:"id"`
+	Status             string `json:"status"`
+	IsSavepoint        bool   `json:"is_savepoint"`
+	TriggerTimestamp   int64  `json:"trigger_timestamp"`
+	LatestAckTimestamp int64  `json:"latest_ack_timestamp"`
+	EndToEndDuration   int64  `json:"end_to_end_duration"`
+	ExternalPath       string `json:"external_path"`
+	FailureMessage     string `json:"failure_message"`
+}
+
+// LatestCheckpoints represents the most recent
+// checkpoints of a job per status
+type LatestCheckpoints struct {
+	Completed *Checkpoint `json:"completed"`
+	Savepoint *Checkpoint `json:"savepoint"`
+	Failed    *Checkpoint `json:"failed"`
+}
+
+// CheckpointDurationSummary represents the minimum, maximum
+// and average of a checkpoint duration in milliseconds
+type CheckpointDurationSummary struct {
+	Min int64 `json:"min"`
+	Max int64 `json:"max"`
+	Avg int64 `json:"avg"`
+}
+
+// CheckpointSummary represents the statistics
+// of all completed checkpoints
+type CheckpointSummary struct {
+	EndToEndDuration CheckpointDurationSummary `json:"end_to_end_duration"`
+}
+
+// CheckpointStatistics represents the response body
+// used by the checkpoint statistics API
+type CheckpointStatistics struct {
+	Counts  CheckpointCounts  `json:"counts"`
+	Summary CheckpointSummary `json:"summary"`
+	Latest  LatestCheckpoints `json:"latest"`
+}
+
+// RetrieveCheckpoints returns the checkpoint statistics of a job specified by job ID
+func (c FlinkRestClient) RetrieveCheckpoints(ctx context.Context, jobID string) (CheckpointStatistics, error) {
+	req, err := c.newRequest(ctx, "GET", c.constructURL(fmt.Sprintf("jobs/%v/checkpoints", jobID)), nil)
+	if err != nil {
+		return CheckpointStatistics{}, err
+	}
+
+	res, err := c.do(OperationRead, req)
+	if err != nil {
+		return CheckpointStatistics{}, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return CheckpointStatistics{}, err
+	}
+
+	if res.StatusCode != 200 {
+		return CheckpointStatistics{}, newAPIError(res, body)
+	}
+
+	response := CheckpointStatistics{}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return CheckpointStatistics{}, fmt.Errorf("Unable to parse API response as valid JSON: %v", string(body[:]))
+	}
+
+	return response, nil
+}
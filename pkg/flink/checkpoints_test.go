@@ -0,0 +1,40 @@
+package flink
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetrieveCheckpointsReturnsAnErrorWhenTheStatusIsNot200(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/1/checkpoints", "", http.StatusNotFound, "{}")
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	_, err := api.RetrieveCheckpoints(context.Background(), "1")
+
+	assert.EqualError(t, err, "Unexpected response status 404 with body {}")
+}
+
+func TestRetrieveCheckpointsCorrectlyReturnsTheStatistics(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/1/checkpoints", "", http.StatusOK, `{"counts":{"restored":0,"total":3,"in_progress":0,"completed":2,"failed":1},"summary":{"end_to_end_duration":{"min":10,"max":30,"avg":20}},"latest":{"completed":{"id":2,"status":"COMPLETED","trigger_timestamp":2},"savepoint":null,"failed":{"id":3,"status":"FAILED","trigger_timestamp":3,"failure_message":"timeout"}}}`)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	statistics, err := api.RetrieveCheckpoints(context.Background(), "1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, statistics.Counts.Failed)
+	assert.Equal(t, int64(20), statistics.Summary.EndToEndDuration.Avg)
+	assert.Nil(t, statistics.Latest.Savepoint)
+	assert.Equal(t, "timeout", statistics.Latest.Failed.FailureMessage)
+}
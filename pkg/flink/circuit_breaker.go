@@ -0,0 +1,89 @@
+package flink
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// A CircuitBreaker stops sending requests to a cluster after a number of
+// consecutive failed requests, so a flapping cluster isn't hammered with
+// retries. After the reset timeout a single request is let through, which
+// closes the circuit again when it succeeds.
+type CircuitBreaker struct {
+	FailureThreshold int
+	ResetTimeout     time.Duration
+
+	mutex    *sync.Mutex
+	failures int
+	openedAt time.Time
+	now      func() time.Time
+}
+
+// NewCircuitBreaker creates a closed CircuitBreaker
+func NewCircuitBreaker(failureThreshold int, resetTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		FailureThreshold: failureThreshold,
+		ResetTimeout:     resetTimeout,
+		mutex:            &sync.Mutex{},
+		now:              time.Now,
+	}
+}
+
+// allow returns an error when the circuit is open. Once the reset timeout
+// has passed, the circuit is half-open and a single request is allowed.
+func (b *CircuitBreaker) allow() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.FailureThreshold <= 0 || b.failures < b.FailureThreshold {
+		return nil
+	}
+
+	remaining := b.ResetTimeout - b.now().Sub(b.openedAt)
+	if remaining > 0 {
+		return CategorizedErrorf(ErrClusterUnreachable, "the circuit breaker is open after %v consecutive failed requests, retrying in %v", b.failures, remaining.Round(time.Second))
+	}
+
+	// Let a single request through and reopen the circuit for the other ones
+	b.openedAt = b.now()
+	return nil
+}
+
+// isBreakerFailure returns whether the outcome of a request counts as a
+// failure of the cluster. Only transport errors and the gateway statuses
+// which signal an unavailable cluster do, other errors are the response
+// of a cluster that is up.
+func isBreakerFailure(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch res.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// record registers the outcome of a request and logs when the circuit opens or closes
+func (b *CircuitBreaker) record(failed bool, logger *log.Logger) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !failed {
+		if b.FailureThreshold > 0 && b.failures >= b.FailureThreshold {
+			logf(logger, "the circuit breaker is closed again")
+		}
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.FailureThreshold > 0 && b.failures == b.FailureThreshold {
+		logf(logger, "the circuit breaker is open after %v consecutive failed requests", b.failures)
+	}
+	if b.failures >= b.FailureThreshold {
+		b.openedAt = b.now()
+	}
+}
@@ -0,0 +1,117 @@
+package flink
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreakerShouldOpenAfterTheFailureThreshold(t *testing.T) {
+	breaker := NewCircuitBreaker(2, time.Minute)
+
+	breaker.record(true, nil)
+	assert.Nil(t, breaker.allow())
+	breaker.record(true, nil)
+
+	err := breaker.allow()
+	assert.EqualError(t, err, "the circuit breaker is open after 2 consecutive failed requests, retrying in 1m0s")
+	assert.Equal(t, ErrClusterUnreachable, Category(err))
+}
+
+func TestCircuitBreakerShouldResetTheFailuresAfterASuccess(t *testing.T) {
+	breaker := NewCircuitBreaker(2, time.Minute)
+
+	breaker.record(true, nil)
+	breaker.record(false, nil)
+	breaker.record(true, nil)
+
+	assert.Nil(t, breaker.allow())
+}
+
+func TestCircuitBreakerShouldAllowASingleRequestAfterTheResetTimeout(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(1, time.Minute)
+	breaker.now = func() time.Time { return now }
+	breaker.record(true, nil)
+
+	now = now.Add(time.Minute)
+	assert.Nil(t, breaker.allow())
+	assert.NotNil(t, breaker.allow())
+
+	breaker.record(false, nil)
+	assert.Nil(t, breaker.allow())
+}
+
+func TestCircuitBreakerShouldBeDisabledWithoutAFailureThreshold(t *testing.T) {
+	breaker := NewCircuitBreaker(0, time.Minute)
+
+	breaker.record(true, nil)
+
+	assert.Nil(t, breaker.allow())
+}
+
+func TestDoShouldStopSendingRequestsWhenTheCircuitIsOpen(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := retryablehttp.NewClient()
+	client.RetryMax = 0
+	api := FlinkRestClient{
+		BaseURL:        server.URL,
+		Client:         client,
+		CircuitBreaker: NewCircuitBreaker(1, time.Minute),
+	}
+
+	_, err := api.RetrieveJobs(context.Background())
+	assert.NotNil(t, err)
+	_, err = api.RetrieveJobs(context.Background())
+	assert.Contains(t, err.Error(), "the circuit breaker is open")
+	assert.Equal(t, 1, requests)
+}
+
+func TestDoShouldNotOpenTheCircuitOnErrorResponsesOfARunningCluster(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		if requests == 1 {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := retryablehttp.NewClient()
+	client.RetryMax = 0
+	api := FlinkRestClient{
+		BaseURL:        server.URL,
+		Client:         client,
+		CircuitBreaker: NewCircuitBreaker(1, time.Minute),
+	}
+
+	_, err := api.RetrieveJobs(context.Background())
+	assert.NotNil(t, err)
+	_, err = api.RetrieveJobs(context.Background())
+	assert.NotContains(t, err.Error(), "the circuit breaker is open")
+	assert.Equal(t, 2, requests)
+}
+
+func TestIsBreakerFailureShouldOnlyCountTransportErrorsAndUnavailableGateways(t *testing.T) {
+	assert.True(t, isBreakerFailure(nil, errors.New("connection refused")))
+	assert.True(t, isBreakerFailure(&http.Response{StatusCode: http.StatusBadGateway}, nil))
+	assert.True(t, isBreakerFailure(&http.Response{StatusCode: http.StatusServiceUnavailable}, nil))
+	assert.True(t, isBreakerFailure(&http.Response{StatusCode: http.StatusGatewayTimeout}, nil))
+	assert.False(t, isBreakerFailure(&http.Response{StatusCode: http.StatusInternalServerError}, nil))
+	assert.False(t, isBreakerFailure(&http.Response{StatusCode: http.StatusNotFound}, nil))
+	assert.False(t, isBreakerFailure(&http.Response{StatusCode: http.StatusOK}, nil))
+}
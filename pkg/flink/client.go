@@ -0,0 +1,143 @@
+package flink
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// A FlinkRestClient is a client to interface with
+// the Apache Flink REST API. The Authenticator, when set,
+// is applied to every request after the basic authentication.
+// The Endpoints, when set, replace the BaseURL for clusters
+// with multiple JobManagers in high-availability mode. The
+// Policies, when set, override the timeout and retries of the
+// Client per operation.
+type FlinkRestClient struct {
+	BaseURL           string
+	BasicAuthUsername string
+	BasicAuthPassword string
+	Authenticator     Authenticator
+	Endpoints         *Endpoints
+	Policies          Policies
+	CircuitBreaker    *CircuitBreaker
+	Client            *retryablehttp.Client
+}
+
+func (c FlinkRestClient) constructURL(path string) string {
+	if c.Endpoints != nil {
+		return fmt.Sprintf("%v/%v", c.Endpoints.Current(), path)
+	}
+	return fmt.Sprintf("%v/%v", c.BaseURL, path)
+}
+
+func basicAuthenticationCredentialsDefined(username, password string) bool {
+	return len(username) != 0 && len(password) != 0
+}
+
+func (c FlinkRestClient) newRequest(ctx context.Context, method, url string, rawBody interface{}) (*retryablehttp.Request, error) {
+	req, err := retryablehttp.NewRequest(method, url, rawBody)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	if basicAuthenticationCredentialsDefined(c.BasicAuthUsername, c.BasicAuthPassword) == true {
+		req.SetBasicAuth(c.BasicAuthUsername, c.BasicAuthPassword)
+	}
+
+	if c.Authenticator != nil {
+		err = c.Authenticator.Authenticate(req.Request)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return req, err
+}
+
+// logf logs to the logger of the client, unless it's nil
+func logf(logger *log.Logger, format string, v ...interface{}) {
+	if logger != nil {
+		logger.Printf(format, v...)
+	}
+}
+
+// do sends the request with the policy of the operation and
+// categorizes the errors which occur before a response is received
+func (c FlinkRestClient) do(operation string, req *retryablehttp.Request) (*http.Response, error) {
+	return c.send(c.clientFor(operation), req)
+}
+
+// doOnce sends the request like do, but never retries it, for requests
+// which aren't idempotent, like stopping a job
+func (c FlinkRestClient) doOnce(operation string, req *retryablehttp.Request) (*http.Response, error) {
+	client := c.clientFor(operation)
+	client.RetryMax = 0
+	return c.send(client, req)
+}
+
+// send sends the request with the client. When the cluster rejects cached
+// credentials, they are dropped and the request is sent once more with
+// fresh ones.
+func (c FlinkRestClient) send(client *retryablehttp.Client, req *retryablehttp.Request) (*http.Response, error) {
+	res, err := c.sendAttempts(client, req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized || !refreshable(c.Authenticator) {
+		return res, err
+	}
+
+	c.Authenticator.(invalidator).Invalidate()
+	drainBody(res.Body)
+	err = c.Authenticator.Authenticate(req.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err = c.sendAttempts(client, req)
+	if err == nil && res.StatusCode == http.StatusUnauthorized {
+		c.Authenticator.(invalidator).Invalidate()
+	}
+	return res, err
+}
+
+func (c FlinkRestClient) sendAttempts(client *retryablehttp.Client, req *retryablehttp.Request) (*http.Response, error) {
+	// The retries turn a retryable status into an error without the
+	// response, so the outcome of the last attempt is kept for the breaker
+	failed := false
+	if c.CircuitBreaker != nil {
+		err := c.CircuitBreaker.allow()
+		if err != nil {
+			return nil, err
+		}
+
+		tracked := *client
+		checkRetry := client.CheckRetry
+		tracked.CheckRetry = func(ctx context.Context, res *http.Response, err error) (bool, error) {
+			failed = isBreakerFailure(res, err)
+			return checkRetry(ctx, res, err)
+		}
+		client = &tracked
+	}
+
+	var res *http.Response
+	var err error
+	if c.Endpoints != nil {
+		res, err = c.doWithFailover(client, req)
+	} else {
+		res, err = client.Do(req)
+	}
+
+	ctx := req.Request.Context()
+	if c.CircuitBreaker != nil && ctx.Err() == nil {
+		c.CircuitBreaker.record(failed, client.Logger)
+	}
+
+	if err != nil {
+		return nil, transportError(ctx, err)
+	}
+
+	return res, nil
+}
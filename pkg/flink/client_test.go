@@ -2,6 +2,7 @@ package flink
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -24,9 +25,14 @@ func TestConstructUrlShouldProperlyFormTheCompleteURL(t *testing.T) {
 }
 
 func TestNewRequestShouldAddTheBasicAuthenticationHeadersWhenTheCredentialsAreSet(t *testing.T) {
-	api := FlinkRestClient{"http://localhost:80", "username", "password", &retryablehttp.Client{}}
+	api := FlinkRestClient{
+		BaseURL:           "http://localhost:80",
+		BasicAuthUsername: "username",
+		BasicAuthPassword: "password",
+		Client:            &retryablehttp.Client{},
+	}
 
-	req, err := api.newRequest("GET", "jobs", nil)
+	req, err := api.newRequest(context.Background(), "GET", "jobs", nil)
 
 	assert.Nil(t, err)
 	assert.Equal(t, "Basic dXNlcm5hbWU6cGFzc3dvcmQ=", req.Header.Get("Authorization"))
@@ -39,7 +45,7 @@ func TestNewRequestShouldNotAddTheBasicAuthenticationHeadersWhenTheUsernameIsUns
 		Client:            &retryablehttp.Client{},
 	}
 
-	req, err := api.newRequest("GET", "jobs", nil)
+	req, err := api.newRequest(context.Background(), "GET", "jobs", nil)
 
 	assert.Nil(t, err)
 	assert.Equal(t, "", req.Header.Get("Authorization"))
@@ -52,7 +58,7 @@ func TestNewRequestShouldNotAddTheBasicAuthenticationHeadersWhenThePassworsdIsUn
 		Client:            &retryablehttp.Client{},
 	}
 
-	req, err := api.newRequest("GET", "jobs", nil)
+	req, err := api.newRequest(context.Background(), "GET", "jobs", nil)
 
 	assert.Nil(t, err)
 	assert.Equal(t, "", req.Header.Get("Authorization"))
@@ -64,7 +70,7 @@ func TestNewRequestShouldNotAddTheBasicAuthenticationHeadersWhenBothCredentialsA
 		Client:  &retryablehttp.Client{},
 	}
 
-	req, err := api.newRequest("GET", "jobs", nil)
+	req, err := api.newRequest(context.Background(), "GET", "jobs", nil)
 
 	assert.Nil(t, err)
 	assert.Equal(t, "", req.Header.Get("Authorization"))
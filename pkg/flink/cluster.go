@@ -0,0 +1,93 @@
+package flink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// ClusterOverview is a representation of the
+// Flink cluster overview
+type ClusterOverview struct {
+	TaskManagers   int    `json:"taskmanagers"`
+	SlotsTotal     int    `json:"slots-total"`
+	SlotsAvailable int    `json:"slots-available"`
+	JobsRunning    int    `json:"jobs-running"`
+	FlinkVersion   string `json:"flink-version"`
+}
+
+// A TaskManager is a representation for a Flink TaskManager
+type TaskManager struct {
+	ID          string `json:"id"`
+	SlotsNumber int    `json:"slotsNumber"`
+	FreeSlots   int    `json:"freeSlots"`
+}
+
+type retrieveTaskManagersResponse struct {
+	TaskManagers []TaskManager `json:"taskmanagers"`
+}
+
+// RetrieveClusterOverview returns the overview of the Flink cluster
+func (c FlinkRestClient) RetrieveClusterOverview(ctx context.Context) (ClusterOverview, error) {
+	req, err := c.newRequest(ctx, "GET", c.constructURL("overview"), nil)
+	if err != nil {
+		return ClusterOverview{}, err
+	}
+
+	res, err := c.do(OperationRead, req)
+	if err != nil {
+		return ClusterOverview{}, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return ClusterOverview{}, err
+	}
+
+	if res.StatusCode != 200 {
+		return ClusterOverview{}, newAPIError(res, body)
+	}
+
+	response := ClusterOverview{}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return ClusterOverview{}, fmt.Errorf("Unable to parse API response as valid JSON: %v", string(body[:]))
+	}
+
+	return response, nil
+}
+
+// RetrieveTaskManagers returns all the task managers registered on the Flink cluster
+func (c FlinkRestClient) RetrieveTaskManagers(ctx context.Context) ([]TaskManager, error) {
+	req, err := c.newRequest(ctx, "GET", c.constructURL("taskmanagers"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.do(OperationRead, req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return []TaskManager{}, err
+	}
+
+	if res.StatusCode != 200 {
+		return []TaskManager{}, newAPIError(res, body)
+	}
+
+	response := retrieveTaskManagersResponse{}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return []TaskManager{}, fmt.Errorf("Unable to parse API response as valid JSON: %v", string(body[:]))
+	}
+
+	return response.TaskManagers, nil
+}
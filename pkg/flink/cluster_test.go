@@ -0,0 +1,70 @@
+package flink
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * Retrieve Cluster Overview
+ */
+func TestRetrieveClusterOverviewReturnsAnErrorWhenTheStatusIsNot200(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/overview", "", http.StatusNotFound, "{}")
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	_, err := api.RetrieveClusterOverview(context.Background())
+
+	assert.EqualError(t, err, "Unexpected response status 404 with body {}")
+}
+
+func TestRetrieveClusterOverviewCorrectlyReturnsTheOverview(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/overview", "", http.StatusOK, `{"taskmanagers":2,"slots-total":8,"slots-available":3,"jobs-running":1,"flink-version":"1.7.2"}`)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	overview, err := api.RetrieveClusterOverview(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, ClusterOverview{TaskManagers: 2, SlotsTotal: 8, SlotsAvailable: 3, JobsRunning: 1, FlinkVersion: "1.7.2"}, overview)
+}
+
+/*
+ * Retrieve Task Managers
+ */
+func TestRetrieveTaskManagersReturnsAnErrorWhenItCannotDeserializeTheResponseAsJSON(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/taskmanagers", "", http.StatusOK, `{"taskmanagers: []}`)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	_, err := api.RetrieveTaskManagers(context.Background())
+
+	assert.EqualError(t, err, "Unable to parse API response as valid JSON: {\"taskmanagers: []}")
+}
+
+func TestRetrieveTaskManagersCorrectlyReturnsAnArrayOfTaskManagers(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/taskmanagers", "", http.StatusOK, `{"taskmanagers":[{"id":"tm-1","slotsNumber":4,"freeSlots":1}]}`)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	taskManagers, err := api.RetrieveTaskManagers(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []TaskManager{TaskManager{ID: "tm-1", SlotsNumber: 4, FreeSlots: 1}}, taskManagers)
+}
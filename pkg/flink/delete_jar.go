@@ -0,0 +1,33 @@
+package flink
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+)
+
+// DeleteJar removes a previously uploaded JAR file from the Flink cluster
+func (c FlinkRestClient) DeleteJar(ctx context.Context, jarID string) error {
+	req, err := c.newRequest(ctx, "DELETE", c.constructURL(fmt.Sprintf("jars/%v", jarID)), nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.do(OperationRead, req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != 200 {
+		return newAPIError(res, body)
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package flink
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteJarReturnsAnErrorWhenTheStatusIsNot200(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jars/id", "", http.StatusNotFound, "not found")
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	err := api.DeleteJar(context.Background(), "id")
+
+	assert.EqualError(t, err, "Unexpected response status 404 with body not found")
+}
+
+func TestDeleteJarCorrectlyReturnsNilWhenTheCallSucceeds(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jars/id", "", http.StatusOK, "{}")
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	err := api.DeleteJar(context.Background(), "id")
+
+	assert.Nil(t, err)
+}
@@ -0,0 +1,199 @@
+package flink
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// maxRedirects is the maximum number of redirects followed for a single request
+const maxRedirects = 10
+
+// Endpoints are the REST endpoints of the JobManagers of a Flink cluster
+// running in high-availability mode. Requests are sent to the endpoint of the
+// current leader, which is remembered between requests and replaced when the
+// endpoint is unreachable, unavailable or redirects to another JobManager.
+type Endpoints struct {
+	mutex   *sync.Mutex
+	urls    []string
+	current int
+}
+
+// NewEndpoints creates the Endpoints for the base URLs,
+// starting with the first one as the presumed leader
+func NewEndpoints(baseURLs []string) *Endpoints {
+	urls := []string{}
+	for _, baseURL := range baseURLs {
+		urls = append(urls, strings.TrimRight(baseURL, "/"))
+	}
+
+	return &Endpoints{
+		mutex: &sync.Mutex{},
+		urls:  urls,
+	}
+}
+
+// Current returns the base URL of the endpoint currently used
+func (e *Endpoints) Current() string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.urls[e.current]
+}
+
+// ordered returns the base URLs starting with the endpoint currently used
+func (e *Endpoints) ordered() []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	urls := []string{}
+	for i := range e.urls {
+		urls = append(urls, e.urls[(e.current+i)%len(e.urls)])
+	}
+	return urls
+}
+
+// use remembers the base URL as the endpoint of the leader, adding it
+// when the leader isn't one of the known endpoints, and logs a new leader
+func (e *Endpoints) use(baseURL string, logger *log.Logger) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.urls[e.current] != baseURL {
+		logf(logger, "using Flink REST endpoint %v", baseURL)
+	}
+
+	for i, u := range e.urls {
+		if u == baseURL {
+			e.current = i
+			return
+		}
+	}
+	e.urls = append(e.urls, baseURL)
+	e.current = len(e.urls) - 1
+}
+
+// baseURLOf returns the known base URL the request URL starts with
+func (e *Endpoints) baseURLOf(requestURL string) (string, bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for _, u := range e.urls {
+		if strings.HasPrefix(requestURL, u+"/") {
+			return u, true
+		}
+	}
+	return "", false
+}
+
+// redirectBaseURL determines the base URL of the JobManager a
+// redirect response points to for the relative request path
+func redirectBaseURL(res *http.Response, relativePath string) (string, error) {
+	location, err := res.Location()
+	if err != nil {
+		return "", fmt.Errorf("redirect response without a valid location: %v", err)
+	}
+
+	target := location.String()
+	if strings.HasSuffix(target, relativePath) {
+		return strings.TrimRight(strings.TrimSuffix(target, relativePath), "/"), nil
+	}
+	return (&url.URL{Scheme: location.Scheme, Host: location.Host}).String(), nil
+}
+
+func drainBody(body io.ReadCloser) {
+	io.Copy(ioutil.Discard, io.LimitReader(body, 4096))
+	body.Close()
+}
+
+// doWithFailover sends the request to the endpoint of the leader. Redirects are
+// followed and the remaining endpoints are tried when the request fails in a way
+// the retry policy of the client considers retryable. Every round over all
+// endpoints counts as a single attempt of the retry policy.
+func (c FlinkRestClient) doWithFailover(client *retryablehttp.Client, req *retryablehttp.Request) (*http.Response, error) {
+	baseURL, ok := c.Endpoints.baseURLOf(req.URL.String())
+	if !ok {
+		return client.Do(req)
+	}
+	relativePath := strings.TrimPrefix(req.URL.String(), baseURL)
+
+	single := *client
+	single.RetryMax = 0
+	single.ErrorHandler = retryablehttp.PassthroughErrorHandler
+	httpClient := *client.HTTPClient
+	httpClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	single.HTTPClient = &httpClient
+
+	var res *http.Response
+	var err error
+	for attempt := 0; ; attempt++ {
+		for _, endpoint := range c.Endpoints.ordered() {
+			redirects := 0
+			for {
+				req.URL, err = url.Parse(endpoint + relativePath)
+				if err != nil {
+					return nil, err
+				}
+				req.Host = req.URL.Host
+
+				res, err = single.Do(req)
+				if err != nil || res.StatusCode < 300 || res.StatusCode >= 400 || res.Header.Get("Location") == "" {
+					break
+				}
+
+				redirects++
+				if redirects > maxRedirects {
+					return nil, fmt.Errorf("stopped after %v redirects", maxRedirects)
+				}
+				endpoint, err = redirectBaseURL(res, relativePath)
+				drainBody(res.Body)
+				if err != nil {
+					return nil, err
+				}
+				logf(client.Logger, "Flink REST endpoint redirected to %v", endpoint)
+			}
+
+			retry, checkErr := client.CheckRetry(req.Request.Context(), res, err)
+			if !retry {
+				if checkErr != nil {
+					return res, checkErr
+				}
+				if err == nil {
+					c.Endpoints.use(endpoint, client.Logger)
+				}
+				return res, err
+			}
+
+			if err != nil {
+				logf(client.Logger, "Flink REST endpoint %v failed: %v", endpoint, err)
+			} else {
+				logf(client.Logger, "Flink REST endpoint %v responded with status %v", endpoint, res.StatusCode)
+				drainBody(res.Body)
+			}
+		}
+
+		if attempt >= client.RetryMax {
+			break
+		}
+		select {
+		case <-req.Request.Context().Done():
+			return nil, req.Request.Context().Err()
+		case <-time.After(client.Backoff(client.RetryWaitMin, client.RetryWaitMax, attempt, nil)):
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%v %v giving up after %v attempts on all Flink REST endpoints", req.Method, relativePath, client.RetryMax+1)
+}
@@ -0,0 +1,146 @@
+package flink
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func createFailoverTestClient(urls ...string) FlinkRestClient {
+	client := retryablehttp.NewClient()
+	client.RetryMax = 1
+	client.RetryWaitMin = time.Millisecond
+	client.RetryWaitMax = time.Millisecond
+
+	return FlinkRestClient{
+		Endpoints: NewEndpoints(urls),
+		Client:    client,
+	}
+}
+
+func createLeaderTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/jobs/overview", req.URL.Path)
+		rw.Write([]byte(`{"jobs": [{"jid": "job-1", "name": "job", "state": "RUNNING"}]}`))
+	}))
+}
+
+func createStandbyTestServer(status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(status)
+		rw.Write([]byte(`{"errors": ["Service temporarily unavailable due to an ongoing leader election. Please refresh."]}`))
+	}))
+}
+
+func TestEndpointsCurrentStartsWithTheFirstEndpoint(t *testing.T) {
+	endpoints := NewEndpoints([]string{"http://jm-1:8081/", "http://jm-2:8081"})
+
+	assert.Equal(t, "http://jm-1:8081", endpoints.Current())
+	assert.Equal(t, []string{"http://jm-1:8081", "http://jm-2:8081"}, endpoints.ordered())
+}
+
+func TestEndpointsUseRemembersTheLeader(t *testing.T) {
+	endpoints := NewEndpoints([]string{"http://jm-1:8081", "http://jm-2:8081", "http://jm-3:8081"})
+
+	endpoints.use("http://jm-2:8081", nil)
+	assert.Equal(t, "http://jm-2:8081", endpoints.Current())
+	assert.Equal(t, []string{"http://jm-2:8081", "http://jm-3:8081", "http://jm-1:8081"}, endpoints.ordered())
+
+	endpoints.use("http://jm-4:8081", nil)
+	assert.Equal(t, "http://jm-4:8081", endpoints.Current())
+}
+
+func TestFailoverToTheNextEndpointOnConnectionErrors(t *testing.T) {
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	leader := createLeaderTestServer(t)
+	defer leader.Close()
+
+	api := createFailoverTestClient(unreachable.URL, leader.URL)
+	jobs, err := api.RetrieveJobs(context.Background())
+
+	assert.Nil(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, leader.URL, api.Endpoints.Current())
+}
+
+func TestFailoverToTheNextEndpointWhenAStandbyIsUnavailable(t *testing.T) {
+	standby := createStandbyTestServer(http.StatusServiceUnavailable)
+	defer standby.Close()
+	leader := createLeaderTestServer(t)
+	defer leader.Close()
+
+	api := createFailoverTestClient(standby.URL, leader.URL)
+	_, err := api.RetrieveJobs(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, leader.URL, api.Endpoints.Current())
+}
+
+func TestFailoverFollowsRedirectsToTheLeader(t *testing.T) {
+	leader := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		assert.Equal(t, "POST", req.Method)
+		assert.Equal(t, "/jars/id/run", req.URL.Path)
+		assert.Contains(t, string(body), `"entryClass":"MainClass"`)
+		rw.Write([]byte(`{"jobid": "job-1"}`))
+	}))
+	defer leader.Close()
+	standby := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Redirect(rw, req, leader.URL+req.URL.Path, http.StatusTemporaryRedirect)
+	}))
+	defer standby.Close()
+
+	api := createFailoverTestClient(standby.URL)
+	_, err := api.RunJar(context.Background(), "id", "MainClass", []string{}, 1, "", false, RunJarOptions{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, leader.URL, api.Endpoints.Current())
+}
+
+func TestFailoverDoesNotRetryNonRetryableResponses(t *testing.T) {
+	notFound := createStandbyTestServer(http.StatusNotFound)
+	defer notFound.Close()
+	leader := createLeaderTestServer(t)
+	defer leader.Close()
+
+	api := createFailoverTestClient(notFound.URL, leader.URL)
+	_, err := api.RetrieveJobs(context.Background())
+
+	assert.Equal(t, ErrNotFound, Category(err))
+	assert.Equal(t, notFound.URL, api.Endpoints.Current())
+}
+
+func TestFailoverReturnsAnUnreachableErrorWhenAllEndpointsFail(t *testing.T) {
+	first := createStandbyTestServer(http.StatusServiceUnavailable)
+	defer first.Close()
+	second := httptest.NewServer(http.NotFoundHandler())
+	second.Close()
+
+	api := createFailoverTestClient(first.URL, second.URL)
+	_, err := api.RetrieveJobs(context.Background())
+
+	assert.Equal(t, ErrClusterUnreachable, Category(err))
+}
+
+func TestDoWithFailoverShouldStopRetryingWhenTheContextIsCanceled(t *testing.T) {
+	standby := createStandbyTestServer(http.StatusServiceUnavailable)
+	defer standby.Close()
+
+	api := createFailoverTestClient(standby.URL)
+	api.Client.RetryMax = 10
+	api.Client.RetryWaitMin = time.Hour
+	api.Client.RetryWaitMax = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err := api.RetrieveJobs(ctx)
+
+	assert.Equal(t, ErrCanceled, Category(err))
+}
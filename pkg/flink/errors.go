@@ -0,0 +1,177 @@
+package flink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// The categories of errors returned by the Flink REST API. Use Category
+// to determine the category of an error returned by this package.
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrTimeout            = errors.New("timeout")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrClusterUnreachable = errors.New("cluster unreachable")
+	ErrCanceled           = errors.New("canceled")
+)
+
+// An APIError is returned when the Flink REST API
+// responds with an unexpected status code
+type APIError struct {
+	Method     string
+	Endpoint   string
+	StatusCode int
+	Errors     []string
+	Body       string
+}
+
+type apiErrorResponse struct {
+	Errors []string `json:"errors"`
+}
+
+func newAPIError(res *http.Response, body []byte) *APIError {
+	response := apiErrorResponse{}
+	json.Unmarshal(body, &response)
+
+	apiError := &APIError{
+		StatusCode: res.StatusCode,
+		Errors:     response.Errors,
+		Body:       string(body[:]),
+	}
+	if res.Request != nil {
+		apiError.Method = res.Request.Method
+		apiError.Endpoint = res.Request.URL.Path
+	}
+	return apiError
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Unexpected response status %v with body %v", e.StatusCode, e.Body)
+}
+
+// Cause returns the category of the API error based on its status code
+func (e *APIError) Cause() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return ErrTimeout
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return ErrClusterUnreachable
+	}
+	return nil
+}
+
+// Unwrap allows errors.Is to match the category of the API error
+func (e *APIError) Unwrap() error {
+	return e.Cause()
+}
+
+// A wrappedError adds context to an error while keeping the original error as its cause
+type wrappedError struct {
+	message string
+	cause   error
+}
+
+func (e *wrappedError) Error() string {
+	return e.message
+}
+
+// Cause returns the wrapped error
+func (e *wrappedError) Cause() error {
+	return e.cause
+}
+
+// Unwrap allows errors.Is to match the wrapped error
+func (e *wrappedError) Unwrap() error {
+	return e.cause
+}
+
+// Errorf formats an error like fmt.Errorf and keeps the last error argument as
+// its cause, so the category of a Flink API error survives adding context to it
+func Errorf(format string, args ...interface{}) error {
+	var cause error
+	for _, arg := range args {
+		if err, ok := arg.(error); ok {
+			cause = err
+		}
+	}
+
+	return &wrappedError{
+		message: fmt.Sprintf(format, args...),
+		cause:   cause,
+	}
+}
+
+// CategorizedErrorf formats an error like fmt.Errorf which belongs to the category
+func CategorizedErrorf(category error, format string, args ...interface{}) error {
+	return &wrappedError{
+		message: fmt.Sprintf(format, args...),
+		cause:   category,
+	}
+}
+
+// transportError categorizes an error returned by the HTTP client before
+// a response was received, which means the request was canceled through
+// its context or the cluster couldn't be reached
+func transportError(ctx context.Context, err error) error {
+	switch ctx.Err() {
+	case context.Canceled:
+		return &wrappedError{message: err.Error(), cause: ErrCanceled}
+	case context.DeadlineExceeded:
+		return &wrappedError{message: err.Error(), cause: ErrTimeout}
+	}
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return &wrappedError{message: err.Error(), cause: ErrTimeout}
+	}
+	return &wrappedError{message: err.Error(), cause: ErrClusterUnreachable}
+}
+
+type causer interface {
+	Cause() error
+}
+
+// APIErrorOf returns the API error which caused the error, or nil when
+// the error wasn't caused by an unexpected response of the cluster
+func APIErrorOf(err error) *APIError {
+	for err != nil {
+		if apiErr, ok := err.(*APIError); ok {
+			return apiErr
+		}
+
+		c, ok := err.(causer)
+		if !ok {
+			return nil
+		}
+		err = c.Cause()
+	}
+	return nil
+}
+
+// Category returns the category of an error, one of ErrNotFound, ErrConflict,
+// ErrTimeout, ErrUnauthorized, ErrClusterUnreachable or ErrCanceled, or nil
+// when it is unknown
+func Category(err error) error {
+	for err != nil {
+		switch err {
+		case ErrNotFound, ErrConflict, ErrTimeout, ErrUnauthorized, ErrClusterUnreachable, ErrCanceled:
+			return err
+		}
+
+		c, ok := err.(causer)
+		if !ok {
+			return nil
+		}
+		err = c.Cause()
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package flink
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIErrorContainsTheStatusEndpointAndFlinkErrors(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/id/exceptions", "", http.StatusNotFound, `{"errors": ["Job id not found"]}`)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	_, err := api.RetrieveJobExceptions(context.Background(), "id")
+
+	apiError, ok := err.(*APIError)
+	assert.True(t, ok)
+	assert.Equal(t, "GET", apiError.Method)
+	assert.Equal(t, "/jobs/id/exceptions", apiError.Endpoint)
+	assert.Equal(t, http.StatusNotFound, apiError.StatusCode)
+	assert.Equal(t, []string{"Job id not found"}, apiError.Errors)
+	assert.Equal(t, ErrNotFound, Category(err))
+}
+
+func TestAPIErrorIsCategorizedByStatusCode(t *testing.T) {
+	categories := map[int]error{
+		http.StatusNotFound:            ErrNotFound,
+		http.StatusConflict:            ErrConflict,
+		http.StatusUnauthorized:        ErrUnauthorized,
+		http.StatusForbidden:           ErrUnauthorized,
+		http.StatusRequestTimeout:      ErrTimeout,
+		http.StatusGatewayTimeout:      ErrTimeout,
+		http.StatusBadGateway:          ErrClusterUnreachable,
+		http.StatusServiceUnavailable:  ErrClusterUnreachable,
+		http.StatusBadRequest:          nil,
+		http.StatusInternalServerError: nil,
+	}
+
+	for status, category := range categories {
+		assert.Equal(t, category, Category(&APIError{StatusCode: status}), "status %v", status)
+	}
+}
+
+func TestCategoryIsKeptWhenAddingContextWithErrorf(t *testing.T) {
+	err := Errorf("retrieving jobs failed: %v", &APIError{StatusCode: http.StatusConflict, Body: "conflict"})
+
+	assert.EqualError(t, err, "retrieving jobs failed: Unexpected response status 409 with body conflict")
+	assert.Equal(t, ErrConflict, Category(err))
+}
+
+func TestCategorizedErrorfReturnsTheCategory(t *testing.T) {
+	err := CategorizedErrorf(ErrTimeout, "failed within %v seconds", 60)
+
+	assert.EqualError(t, err, "failed within 60 seconds")
+	assert.Equal(t, ErrTimeout, Category(err))
+}
+
+func TestCategoryReturnsNilForUncategorizedErrors(t *testing.T) {
+	assert.Nil(t, Category(errors.New("failure")))
+	assert.Nil(t, Category(Errorf("failure without cause")))
+	assert.Nil(t, Category(nil))
+}
+
+func TestAPIErrorOfReturnsTheWrappedAPIError(t *testing.T) {
+	apiErr := &APIError{StatusCode: 500, Errors: []string{"The main method caused an error"}}
+	err := Errorf("running the job failed: %v", apiErr)
+
+	assert.Equal(t, apiErr, APIErrorOf(err))
+	assert.Nil(t, APIErrorOf(errors.New("failure")))
+}
+
+func TestUnreachableClusterIsCategorized(t *testing.T) {
+	client := retryablehttp.NewClient()
+	client.RetryMax = 0
+	api := FlinkRestClient{
+		BaseURL: "http://127.0.0.1:1",
+		Client:  client,
+	}
+	_, err := api.RetrieveJobs(context.Background())
+
+	assert.Equal(t, ErrClusterUnreachable, Category(err))
+}
+
+func TestCanceledRequestIsCategorized(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/overview", "", http.StatusOK, `{"jobs": []}`)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	api := FlinkRestClient{
+		BaseURL:        server.URL,
+		Client:         retryablehttp.NewClient(),
+		CircuitBreaker: NewCircuitBreaker(1, time.Minute),
+	}
+	_, err := api.RetrieveJobs(ctx)
+
+	assert.Equal(t, ErrCanceled, Category(err))
+	assert.Nil(t, api.CircuitBreaker.allow())
+}
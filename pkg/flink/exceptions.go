@@ -0,0 +1,58 @@
+package flink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// A JobException is a representation of an exception
+// thrown by one of the tasks of a Flink job
+type JobException struct {
+	Exception string `json:"exception"`
+	Task      string `json:"task"`
+	Location  string `json:"location"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// JobExceptions represents the response body
+// used by the job exceptions API
+type JobExceptions struct {
+	RootException string         `json:"root-exception"`
+	Timestamp     int64          `json:"timestamp"`
+	AllExceptions []JobException `json:"all-exceptions"`
+	Truncated     bool           `json:"truncated"`
+}
+
+// RetrieveJobExceptions returns the exceptions of a job specified by job ID
+func (c FlinkRestClient) RetrieveJobExceptions(ctx context.Context, jobID string) (JobExceptions, error) {
+	req, err := c.newRequest(ctx, "GET", c.constructURL(fmt.Sprintf("jobs/%v/exceptions", jobID)), nil)
+	if err != nil {
+		return JobExceptions{}, err
+	}
+
+	res, err := c.do(OperationRead, req)
+	if err != nil {
+		return JobExceptions{}, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return JobExceptions{}, err
+	}
+
+	if res.StatusCode != 200 {
+		return JobExceptions{}, newAPIError(res, body)
+	}
+
+	response := JobExceptions{}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return JobExceptions{}, fmt.Errorf("Unable to parse API response as valid JSON: %v", string(body[:]))
+	}
+
+	return response, nil
+}
@@ -0,0 +1,52 @@
+package flink
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetrieveJobExceptionsReturnsAnErrorWhenTheStatusIsNot200(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/1/exceptions", "", http.StatusNotFound, "{}")
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	_, err := api.RetrieveJobExceptions(context.Background(), "1")
+
+	assert.EqualError(t, err, "Unexpected response status 404 with body {}")
+}
+
+func TestRetrieveJobExceptionsReturnsAnErrorWhenItCannotDeserializeTheResponseAsJSON(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/1/exceptions", "", http.StatusOK, `{"root-exception: ""}`)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	_, err := api.RetrieveJobExceptions(context.Background(), "1")
+
+	assert.EqualError(t, err, "Unable to parse API response as valid JSON: {\"root-exception: \"\"}")
+}
+
+func TestRetrieveJobExceptionsCorrectlyReturnsTheExceptions(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/1/exceptions", "", http.StatusOK, `{"root-exception":"java.lang.RuntimeException: boom","timestamp":1,"all-exceptions":[{"exception":"java.lang.RuntimeException: boom","task":"Source: Custom Source (1/1)","location":"taskmanager:41000","timestamp":1}],"truncated":false}`)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	exceptions, err := api.RetrieveJobExceptions(context.Background(), "1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "java.lang.RuntimeException: boom", exceptions.RootException)
+	assert.Len(t, exceptions.AllExceptions, 1)
+	assert.Equal(t, "Source: Custom Source (1/1)", exceptions.AllExceptions[0].Task)
+}
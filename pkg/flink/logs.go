@@ -0,0 +1,83 @@
+package flink
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// tailLines reads the reader to the end and returns its last lines, or
+// everything when lines is 0. Only the returned lines are kept in memory,
+// since the logs of a long running cluster are often hundreds of megabytes.
+func tailLines(r io.Reader, lines int) (string, error) {
+	if lines <= 0 {
+		content, err := ioutil.ReadAll(r)
+		return string(content[:]), err
+	}
+
+	ring := make([]string, lines)
+	count := 0
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			ring[count%lines] = line
+			count++
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	tail := strings.Builder{}
+	start := 0
+	if count > lines {
+		start = count - lines
+	}
+	for i := start; i < count; i++ {
+		tail.WriteString(ring[i%lines])
+	}
+	return tail.String(), nil
+}
+
+func (c FlinkRestClient) retrieveLog(ctx context.Context, path string, lines int) (string, error) {
+	req, err := c.newRequest(ctx, "GET", c.constructURL(path), nil)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := c.do(OperationRead, req)
+	if err != nil {
+		return "", err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return "", err
+		}
+		return "", newAPIError(res, body)
+	}
+
+	return tailLines(res.Body, lines)
+}
+
+// RetrieveJobManagerLog returns the last lines of the log file of
+// the job manager, or the complete log file when lines is 0
+func (c FlinkRestClient) RetrieveJobManagerLog(ctx context.Context, lines int) (string, error) {
+	return c.retrieveLog(ctx, "jobmanager/log", lines)
+}
+
+// RetrieveTaskManagerLog returns the last lines of the log file of a task
+// manager specified by ID, or the complete log file when lines is 0
+func (c FlinkRestClient) RetrieveTaskManagerLog(ctx context.Context, taskManagerID string, lines int) (string, error) {
+	return c.retrieveLog(ctx, fmt.Sprintf("taskmanagers/%v/log", taskManagerID), lines)
+}
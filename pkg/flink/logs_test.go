@@ -0,0 +1,90 @@
+package flink
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetrieveJobManagerLogReturnsAnErrorWhenTheStatusIsNot200(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobmanager/log", "", http.StatusNotFound, "not found")
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	_, err := api.RetrieveJobManagerLog(context.Background(), 0)
+
+	assert.EqualError(t, err, "Unexpected response status 404 with body not found")
+}
+
+func TestRetrieveJobManagerLogCorrectlyReturnsTheLog(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobmanager/log", "", http.StatusOK, "line 1\nline 2\n")
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	log, err := api.RetrieveJobManagerLog(context.Background(), 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "line 1\nline 2\n", log)
+}
+
+func TestRetrieveTaskManagerLogCorrectlyReturnsTheLog(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/taskmanagers/tm-1/log", "", http.StatusOK, "line 1\n")
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	log, err := api.RetrieveTaskManagerLog(context.Background(), "tm-1", 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "line 1\n", log)
+}
+
+func TestRetrieveJobManagerLogReturnsTheLastLines(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobmanager/log", "", http.StatusOK, "line 1\nline 2\nline 3\nline 4")
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	log, err := api.RetrieveJobManagerLog(context.Background(), 2)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "line 3\nline 4", log)
+}
+
+/*
+ * tailLines
+ */
+func TestTailLinesShouldReturnEverythingWhenLinesIsZero(t *testing.T) {
+	tail, err := tailLines(strings.NewReader("a\nb\n"), 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "a\nb\n", tail)
+}
+
+func TestTailLinesShouldReturnTheLastLines(t *testing.T) {
+	tail, err := tailLines(strings.NewReader("a\nb\nc\nd\n"), 2)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "c\nd\n", tail)
+}
+
+func TestTailLinesShouldReturnAllLinesWhenThereAreFewer(t *testing.T) {
+	tail, err := tailLines(strings.NewReader("a\nb\n"), 5)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "a\nb\n", tail)
+}
@@ -0,0 +1,104 @@
+package flink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+)
+
+// PlanInput represents an edge in the job graph connecting
+// a node to one of its upstream nodes
+type PlanInput struct {
+	Num          int    `json:"num"`
+	ID           string `json:"id"`
+	ShipStrategy string `json:"ship_strategy"`
+	Exchange     string `json:"exchange"`
+}
+
+// PlanNode represents a single vertex in the job graph
+type PlanNode struct {
+	ID               string      `json:"id"`
+	Parallelism      int         `json:"parallelism"`
+	Operator         string      `json:"operator"`
+	OperatorStrategy string      `json:"operator_strategy"`
+	Description      string      `json:"description"`
+	Inputs           []PlanInput `json:"inputs"`
+}
+
+// A Plan is a representation of the job graph
+// of a Flink job as returned by the plan API
+type Plan struct {
+	JobID string     `json:"jid"`
+	Name  string     `json:"name"`
+	Nodes []PlanNode `json:"nodes"`
+}
+
+type planResponse struct {
+	Plan Plan `json:"plan"`
+}
+
+func (c FlinkRestClient) retrievePlan(ctx context.Context, path string) (Plan, error) {
+	req, err := c.newRequest(ctx, "GET", c.constructURL(path), nil)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	res, err := c.do(OperationRead, req)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	if res.StatusCode != 200 {
+		return Plan{}, newAPIError(res, body)
+	}
+
+	response := planResponse{}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return Plan{}, fmt.Errorf("Unable to parse API response as valid JSON: %v", string(body[:]))
+	}
+
+	return response.Plan, nil
+}
+
+// RetrieveJobPlan returns the plan of a job specified by job ID
+func (c FlinkRestClient) RetrieveJobPlan(ctx context.Context, jobID string) (Plan, error) {
+	return c.retrievePlan(ctx, fmt.Sprintf("jobs/%v/plan", jobID))
+}
+
+// RetrieveJarPlan returns the plan a previously uploaded JAR file
+// would produce when run with the supplied parameters. The program
+// arguments are passed as a single string, like RunJar does.
+func (c FlinkRestClient) RetrieveJarPlan(ctx context.Context, jarID string, entryClass string, jarArgs []string, parallelism int) (Plan, error) {
+	query := url.Values{}
+	if len(entryClass) > 0 {
+		query.Set("entry-class", entryClass)
+	}
+	if len(jarArgs) > 0 {
+		programArgs, err := joinProgramArgs(jarArgs)
+		if err != nil {
+			return Plan{}, err
+		}
+		query.Set("program-args", programArgs)
+	}
+	if parallelism > 0 {
+		query.Set("parallelism", strconv.Itoa(parallelism))
+	}
+
+	path := fmt.Sprintf("jars/%v/plan", jarID)
+	if len(query) > 0 {
+		path = path + "?" + query.Encode()
+	}
+
+	return c.retrievePlan(ctx, path)
+}
@@ -0,0 +1,90 @@
+package flink
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+)
+
+const samplePlan = `{"plan":{"jid":"job-1","name":"Windowed WordCount","nodes":[` +
+	`{"id":"sink","parallelism":2,"operator":"","operator_strategy":"","description":"Sink: Print to Std. Out","inputs":[{"num":0,"id":"source","ship_strategy":"HASH","exchange":"pipelined_bounded"}]},` +
+	`{"id":"source","parallelism":1,"operator":"","operator_strategy":"","description":"Source: Custom Source"}]}}`
+
+/*
+ * Retrieve Job Plan
+ */
+func TestRetrieveJobPlanReturnsAnErrorWhenTheStatusIsNot200(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/job-1/plan", "", http.StatusNotFound, "{}")
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	_, err := api.RetrieveJobPlan(context.Background(), "job-1")
+
+	assert.EqualError(t, err, "Unexpected response status 404 with body {}")
+}
+
+func TestRetrieveJobPlanReturnsAnErrorWhenItCannotDeserializeTheResponseAsJSON(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/job-1/plan", "", http.StatusOK, `{"plan: {}}`)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	_, err := api.RetrieveJobPlan(context.Background(), "job-1")
+
+	assert.EqualError(t, err, "Unable to parse API response as valid JSON: {\"plan: {}}")
+}
+
+func TestRetrieveJobPlanCorrectlyReturnsThePlan(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/job-1/plan", "", http.StatusOK, samplePlan)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	plan, err := api.RetrieveJobPlan(context.Background(), "job-1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Windowed WordCount", plan.Name)
+	assert.Len(t, plan.Nodes, 2)
+	assert.Equal(t, "HASH", plan.Nodes[0].Inputs[0].ShipStrategy)
+	assert.Equal(t, "source", plan.Nodes[0].Inputs[0].ID)
+}
+
+/*
+ * Retrieve Jar Plan
+ */
+func TestRetrieveJarPlanPassesTheParametersAsQueryString(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jars/id/plan?entry-class=MainClass&parallelism=2&program-args=--intervalMs+1000", "", http.StatusOK, samplePlan)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	plan, err := api.RetrieveJarPlan(context.Background(), "id", "MainClass", []string{"--intervalMs", "1000"}, 2)
+
+	assert.Nil(t, err)
+	assert.Len(t, plan.Nodes, 2)
+}
+
+func TestRetrieveJarPlanOmitsTheQueryStringWhenNoParametersAreSet(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jars/id/plan", "", http.StatusOK, samplePlan)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	_, err := api.RetrieveJarPlan(context.Background(), "id", "", []string{}, 0)
+
+	assert.Nil(t, err)
+}
@@ -0,0 +1,175 @@
+package flink
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"gopkg.in/yaml.v2"
+)
+
+// The operations which have their own policy. Requests that
+// don't belong to any of the other operations are reads.
+const (
+	OperationRead      = "read"
+	OperationUpload    = "upload"
+	OperationSavepoint = "savepoint"
+	OperationStart     = "start"
+	OperationStop      = "stop"
+)
+
+// A Policy configures the timeout, retries and backoff of the requests of an
+// operation. The wait timeout and poll intervals apply to waiting for an
+// asynchronous operation, like a savepoint, to complete.
+type Policy struct {
+	Timeout         time.Duration
+	RetryMax        int
+	RetryWaitMin    time.Duration
+	RetryWaitMax    time.Duration
+	WaitTimeout     time.Duration
+	PollInterval    time.Duration
+	PollMaxInterval time.Duration
+}
+
+// DefaultWaitTimeout is the time to wait for an asynchronous
+// operation to complete when the policy doesn't set one
+const DefaultWaitTimeout = 60 * time.Second
+
+// Policies are the policies by operation
+type Policies map[string]Policy
+
+// DefaultPolicies returns the default policies, which use the request
+// timeout for all operations except uploading a JAR file
+func DefaultPolicies(requestTimeout time.Duration) Policies {
+	policy := Policy{
+		Timeout:         requestTimeout,
+		RetryMax:        4,
+		RetryWaitMin:    1 * time.Second,
+		RetryWaitMax:    30 * time.Second,
+		WaitTimeout:     DefaultWaitTimeout,
+		PollInterval:    500 * time.Millisecond,
+		PollMaxInterval: 60 * time.Second,
+	}
+
+	upload := policy
+	upload.Timeout = 5 * time.Minute
+	upload.RetryMax = 2
+
+	return Policies{
+		OperationRead:      policy,
+		OperationUpload:    upload,
+		OperationSavepoint: policy,
+		OperationStart:     policy,
+		OperationStop:      policy,
+	}
+}
+
+// Get returns the policy of the operation, or the
+// default policy for reads when it is unknown
+func (p Policies) Get(operation string) Policy {
+	if policy, ok := p[operation]; ok {
+		return policy
+	}
+	if policy, ok := p[OperationRead]; ok {
+		return policy
+	}
+	return DefaultPolicies(10 * time.Second)[OperationRead]
+}
+
+type policyOverride struct {
+	Timeout         *time.Duration `yaml:"timeout"`
+	Retries         *int           `yaml:"retries"`
+	RetryWaitMin    *time.Duration `yaml:"retryWaitMin"`
+	RetryWaitMax    *time.Duration `yaml:"retryWaitMax"`
+	WaitTimeout     *time.Duration `yaml:"waitTimeout"`
+	PollInterval    *time.Duration `yaml:"pollInterval"`
+	PollMaxInterval *time.Duration `yaml:"pollMaxInterval"`
+}
+
+type policyConfig struct {
+	Operations     map[string]policyOverride `yaml:"operations"`
+	CircuitBreaker *struct {
+		FailureThreshold int           `yaml:"failureThreshold"`
+		ResetTimeout     time.Duration `yaml:"resetTimeout"`
+	} `yaml:"circuitBreaker"`
+}
+
+func (o policyOverride) apply(policy Policy) Policy {
+	if o.Timeout != nil {
+		policy.Timeout = *o.Timeout
+	}
+	if o.Retries != nil {
+		policy.RetryMax = *o.Retries
+	}
+	if o.RetryWaitMin != nil {
+		policy.RetryWaitMin = *o.RetryWaitMin
+	}
+	if o.RetryWaitMax != nil {
+		policy.RetryWaitMax = *o.RetryWaitMax
+	}
+	if o.WaitTimeout != nil {
+		policy.WaitTimeout = *o.WaitTimeout
+	}
+	if o.PollInterval != nil {
+		policy.PollInterval = *o.PollInterval
+	}
+	if o.PollMaxInterval != nil {
+		policy.PollMaxInterval = *o.PollMaxInterval
+	}
+	return policy
+}
+
+// ParsePolicyConfig parses a YAML policy configuration, which overrides
+// the default policies per operation and configures the circuit breaker.
+// The circuit breaker is nil when it isn't configured.
+func ParsePolicyConfig(content []byte, defaults Policies) (Policies, *CircuitBreaker, error) {
+	config := policyConfig{}
+	err := yaml.UnmarshalStrict(content, &config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to parse the policy configuration: %v", err)
+	}
+
+	policies := Policies{}
+	for operation, policy := range defaults {
+		policies[operation] = policy
+	}
+	for operation, override := range config.Operations {
+		policy, ok := policies[operation]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown operation \"%v\" in the policy configuration, only '%v', '%v', '%v', '%v' and '%v' are supported", operation, OperationRead, OperationUpload, OperationSavepoint, OperationStart, OperationStop)
+		}
+		policies[operation] = override.apply(policy)
+	}
+
+	var breaker *CircuitBreaker
+	if config.CircuitBreaker != nil {
+		breaker = NewCircuitBreaker(config.CircuitBreaker.FailureThreshold, config.CircuitBreaker.ResetTimeout)
+	}
+
+	return policies, breaker, nil
+}
+
+// clientFor returns a copy of the HTTP client configured with the policy
+// of the operation, so the shared client is never modified
+func (c FlinkRestClient) clientFor(operation string) *retryablehttp.Client {
+	client := *c.Client
+	if operation == OperationStop {
+		client.CheckRetry = RetryPolicy
+	}
+
+	if c.Policies == nil {
+		return &client
+	}
+
+	policy := c.Policies.Get(operation)
+	client.RetryMax = policy.RetryMax
+	client.RetryWaitMin = policy.RetryWaitMin
+	client.RetryWaitMax = policy.RetryWaitMax
+	if client.HTTPClient != nil {
+		httpClient := *client.HTTPClient
+		httpClient.Timeout = policy.Timeout
+		client.HTTPClient = &httpClient
+	}
+
+	return &client
+}
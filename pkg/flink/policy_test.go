@@ -0,0 +1,98 @@
+package flink
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoliciesGetShouldFallBackToTheReadPolicy(t *testing.T) {
+	policies := Policies{OperationRead: Policy{RetryMax: 7}}
+
+	assert.Equal(t, 7, policies.Get(OperationUpload).RetryMax)
+}
+
+func TestPoliciesGetShouldFallBackToTheDefaultsWithoutPolicies(t *testing.T) {
+	var policies Policies
+
+	assert.Equal(t, DefaultPolicies(10 * time.Second)[OperationRead], policies.Get(OperationSavepoint))
+}
+
+func TestParsePolicyConfigShouldOverrideTheDefaults(t *testing.T) {
+	content := []byte(`
+operations:
+  savepoint:
+    timeout: 20s
+    waitTimeout: 15m
+  upload:
+    retries: 0
+circuitBreaker:
+  failureThreshold: 5
+  resetTimeout: 1m
+`)
+	defaults := DefaultPolicies(10 * time.Second)
+
+	policies, breaker, err := ParsePolicyConfig(content, defaults)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 20*time.Second, policies[OperationSavepoint].Timeout)
+	assert.Equal(t, 15*time.Minute, policies[OperationSavepoint].WaitTimeout)
+	assert.Equal(t, defaults[OperationSavepoint].RetryMax, policies[OperationSavepoint].RetryMax)
+	assert.Equal(t, 0, policies[OperationUpload].RetryMax)
+	assert.Equal(t, defaults[OperationUpload].Timeout, policies[OperationUpload].Timeout)
+	assert.Equal(t, defaults[OperationRead], policies[OperationRead])
+	assert.Equal(t, 5, breaker.FailureThreshold)
+	assert.Equal(t, time.Minute, breaker.ResetTimeout)
+}
+
+func TestParsePolicyConfigShouldReturnNoCircuitBreakerWhenUnconfigured(t *testing.T) {
+	_, breaker, err := ParsePolicyConfig([]byte("operations: {}"), DefaultPolicies(10*time.Second))
+
+	assert.Nil(t, err)
+	assert.Nil(t, breaker)
+}
+
+func TestParsePolicyConfigShouldRejectAnUnknownOperation(t *testing.T) {
+	_, _, err := ParsePolicyConfig([]byte("operations:\n  deploy:\n    retries: 1"), DefaultPolicies(10*time.Second))
+
+	assert.EqualError(t, err, "unknown operation \"deploy\" in the policy configuration, only 'read', 'upload', 'savepoint', 'start' and 'stop' are supported")
+}
+
+func TestParsePolicyConfigShouldRejectAnUnknownField(t *testing.T) {
+	_, _, err := ParsePolicyConfig([]byte("operations:\n  read:\n    retry: 1"), DefaultPolicies(10*time.Second))
+
+	assert.Contains(t, err.Error(), "unable to parse the policy configuration")
+}
+
+func TestClientForShouldApplyThePolicyOfTheOperation(t *testing.T) {
+	client := retryablehttp.NewClient()
+	client.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	policies := DefaultPolicies(10 * time.Second)
+	api := FlinkRestClient{Client: client, Policies: policies}
+
+	upload := api.clientFor(OperationUpload)
+
+	assert.Equal(t, policies[OperationUpload].RetryMax, upload.RetryMax)
+	assert.Equal(t, 5*time.Minute, upload.HTTPClient.Timeout)
+	assert.Equal(t, 10*time.Second, client.HTTPClient.Timeout)
+}
+
+func TestClientForShouldNotModifyTheRetryPolicyOfTheSharedClient(t *testing.T) {
+	client := retryablehttp.NewClient()
+	api := FlinkRestClient{Client: client}
+	res := &http.Response{StatusCode: http.StatusInternalServerError}
+
+	stop := api.clientFor(OperationStop)
+	read := api.clientFor(OperationRead)
+
+	retry, _ := stop.CheckRetry(context.Background(), res, nil)
+	assert.False(t, retry)
+	retry, _ = read.CheckRetry(context.Background(), res, nil)
+	assert.True(t, retry)
+	retry, _ = client.CheckRetry(context.Background(), res, nil)
+	assert.True(t, retry)
+}
@@ -0,0 +1,116 @@
+package flink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// ErrRescalingUnsupported is returned when the Flink cluster
+// does not support rescaling a running job in place
+var ErrRescalingUnsupported = errors.New("rescaling is not supported by the Flink cluster")
+
+// RescaleResponse represents the response body
+// used by the rescaling API
+type RescaleResponse struct {
+	RequestID string `json:"request-id"`
+}
+
+// FailureCause represents the cause of a
+// failed asynchronous operation
+type FailureCause struct {
+	Class      string `json:"class"`
+	StackTrace string `json:"stack-trace"`
+}
+
+// RescalingStatus represents the
+// rescaling status used by the API
+type RescalingStatus struct {
+	Id string `json:"id"`
+}
+
+// RescalingOperation represents the result of
+// a completed rescaling operation
+type RescalingOperation struct {
+	FailureCause *FailureCause `json:"failure-cause"`
+}
+
+// MonitorRescalingResponse represents the response body
+// used by the rescaling monitoring API
+type MonitorRescalingResponse struct {
+	Status    RescalingStatus     `json:"status"`
+	Operation *RescalingOperation `json:"operation"`
+}
+
+// Rescale changes the parallelism of a running job specified by job ID
+func (c FlinkRestClient) Rescale(ctx context.Context, jobID string, parallelism int) (RescaleResponse, error) {
+	req, err := c.newRequest(ctx, "PATCH", c.constructURL(fmt.Sprintf("jobs/%v/rescaling?parallelism=%v", jobID, parallelism)), nil)
+	if err != nil {
+		return RescaleResponse{}, err
+	}
+
+	res, err := c.do(OperationStart, req)
+	if err != nil {
+		return RescaleResponse{}, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return RescaleResponse{}, err
+	}
+
+	// Newer Flink versions keep the endpoint but reject every request
+	if res.StatusCode == 405 || strings.Contains(string(body[:]), "Rescaling is temporarily disabled") {
+		return RescaleResponse{}, ErrRescalingUnsupported
+	}
+
+	if res.StatusCode != 200 {
+		return RescaleResponse{}, newAPIError(res, body)
+	}
+
+	response := RescaleResponse{}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return RescaleResponse{}, fmt.Errorf("Unable to parse API response as valid JSON: %v", string(body[:]))
+	}
+
+	return response, nil
+}
+
+// MonitorRescaling allows for monitoring the status of a rescaling operation
+// identified by the job ID and request ID
+func (c FlinkRestClient) MonitorRescaling(ctx context.Context, jobID string, requestID string) (MonitorRescalingResponse, error) {
+	req, err := c.newRequest(ctx, "GET", c.constructURL(fmt.Sprintf("jobs/%v/rescaling/%v", jobID, requestID)), nil)
+	if err != nil {
+		return MonitorRescalingResponse{}, err
+	}
+
+	res, err := c.do(OperationStart, req)
+	if err != nil {
+		return MonitorRescalingResponse{}, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return MonitorRescalingResponse{}, err
+	}
+
+	if res.StatusCode != 200 {
+		return MonitorRescalingResponse{}, newAPIError(res, body)
+	}
+
+	response := MonitorRescalingResponse{}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return MonitorRescalingResponse{}, fmt.Errorf("Unable to parse API response as valid JSON: %v", string(body[:]))
+	}
+
+	return response, nil
+}
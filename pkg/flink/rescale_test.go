@@ -0,0 +1,84 @@
+package flink
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * Rescale
+ */
+func TestRescaleReturnsAnErrorWhenTheStatusIsNot200(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/1/rescaling?parallelism=4", "", http.StatusNotFound, "{}")
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	_, err := api.Rescale(context.Background(), "1", 4)
+
+	assert.EqualError(t, err, "Unexpected response status 404 with body {}")
+}
+
+func TestRescaleReturnsErrRescalingUnsupportedWhenRescalingIsDisabled(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/1/rescaling?parallelism=4", "", http.StatusBadRequest, `{"errors":["Rescaling is temporarily disabled. See FLINK-12312."]}`)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	_, err := api.Rescale(context.Background(), "1", 4)
+
+	assert.Equal(t, ErrRescalingUnsupported, err)
+}
+
+func TestRescaleCorrectlyReturnsARequestID(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/1/rescaling?parallelism=4", "", http.StatusOK, `{"request-id": "2"}`)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	res, err := api.Rescale(context.Background(), "1", 4)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "2", res.RequestID)
+}
+
+/*
+ * Monitor Rescaling
+ */
+func TestMonitorRescalingReturnsAnErrorWhenItCannotDeserializeTheResponseAsJSON(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/1/rescaling/2", "", http.StatusOK, `{"status: {}}`)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	_, err := api.MonitorRescaling(context.Background(), "1", "2")
+
+	assert.EqualError(t, err, "Unable to parse API response as valid JSON: {\"status: {}}")
+}
+
+func TestMonitorRescalingCorrectlyReturnsTheFailureCause(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/1/rescaling/2", "", http.StatusOK, `{"status":{"id":"COMPLETED"},"operation":{"failure-cause":{"class":"java.lang.Exception","stack-trace":"trace"}}}`)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	res, err := api.MonitorRescaling(context.Background(), "1", "2")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "COMPLETED", res.Status.Id)
+	assert.Equal(t, "java.lang.Exception", res.Operation.FailureCause.Class)
+}
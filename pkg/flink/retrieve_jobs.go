@@ -1,6 +1,7 @@
 package flink
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -8,9 +9,10 @@ import (
 
 // A Job is a representation for a Flink Job
 type Job struct {
-	ID     string `json:"jid"`
-	Name   string `json:"name"`
-	Status string `json:"state"`
+	ID        string `json:"jid"`
+	Name      string `json:"name"`
+	Status    string `json:"state"`
+	StartTime int64  `json:"start-time"`
 }
 
 type retrieveJobsResponse struct {
@@ -18,13 +20,13 @@ type retrieveJobsResponse struct {
 }
 
 // RetrieveJobs returns all the jobs on the Flink cluster
-func (c FlinkRestClient) RetrieveJobs() ([]Job, error) {
-	req, err := c.newRequest("GET", c.constructURL("jobs/overview"), nil)
+func (c FlinkRestClient) RetrieveJobs(ctx context.Context) ([]Job, error) {
+	req, err := c.newRequest(ctx, "GET", c.constructURL("jobs/overview"), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := c.Client.Do(req)
+	res, err := c.do(OperationRead, req)
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +39,7 @@ func (c FlinkRestClient) RetrieveJobs() ([]Job, error) {
 	}
 
 	if res.StatusCode != 200 {
-		return []Job{}, fmt.Errorf("Unexpected response status %v with body %v", res.StatusCode, string(body[:]))
+		return []Job{}, newAPIError(res, body)
 	}
 
 	retrieveJobsResponse := retrieveJobsResponse{}
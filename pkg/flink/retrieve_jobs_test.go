@@ -1,6 +1,7 @@
 package flink
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
@@ -16,7 +17,7 @@ func TestRetrieveJobsReturnsAnErrorWhenTheStatusIsNot200(t *testing.T) {
 		BaseURL: server.URL,
 		Client:  retryablehttp.NewClient(),
 	}
-	_, err := api.RetrieveJobs()
+	_, err := api.RetrieveJobs(context.Background())
 
 	assert.EqualError(t, err, "Unexpected response status 202 with body {}")
 }
@@ -29,7 +30,7 @@ func TestRetrieveJobsReturnsAnErrorWhenItCannotDeserializeTheResponseAsJSON(t *t
 		BaseURL: server.URL,
 		Client:  retryablehttp.NewClient(),
 	}
-	_, err := api.RetrieveJobs()
+	_, err := api.RetrieveJobs(context.Background())
 
 	assert.EqualError(t, err, "Unable to parse API response as valid JSON: {\"jobs: []}")
 }
@@ -42,7 +43,7 @@ func TestRetrieveJobsCorrectlyReturnsAnArrayOfJobs(t *testing.T) {
 		BaseURL: server.URL,
 		Client:  retryablehttp.NewClient(),
 	}
-	jobs, err := api.RetrieveJobs()
+	jobs, err := api.RetrieveJobs(context.Background())
 
 	assert.Len(t, jobs, 1)
 	assert.Nil(t, err)
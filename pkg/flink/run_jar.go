@@ -0,0 +1,204 @@
+package flink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"unicode"
+)
+
+type runJarRequest struct {
+	EntryClass            string `json:"entryClass"`
+	ProgramArgs           string `json:"programArgs"`
+	Parallelism           int    `json:"parallelism"`
+	AllowNonRestoredState bool   `json:"allowNonRestoredState"`
+	SavepointPath         string `json:"savepointPath"`
+}
+
+// runJarWithProgramArgsListRequest is the run request used by Flink 1.8
+// and newer, which accept the program arguments as a list. The options
+// are only sent when they're set, as older versions ignore them.
+type runJarWithProgramArgsListRequest struct {
+	EntryClass            string            `json:"entryClass"`
+	ProgramArgsList       []string          `json:"programArgsList"`
+	Parallelism           int               `json:"parallelism"`
+	AllowNonRestoredState bool              `json:"allowNonRestoredState"`
+	SavepointPath         string            `json:"savepointPath"`
+	JobID                 string            `json:"jobId,omitempty"`
+	RestoreMode           string            `json:"restoreMode,omitempty"`
+	FlinkConfiguration    map[string]string `json:"flinkConfiguration,omitempty"`
+}
+
+// The restore modes of Flink 1.15 and newer, which determine whether the
+// job takes ownership of the savepoint it's restored from
+const (
+	RestoreModeClaim   = "CLAIM"
+	RestoreModeNoClaim = "NO_CLAIM"
+	RestoreModeLegacy  = "LEGACY"
+)
+
+// RunJarOptions are the options of the run request which only newer
+// Flink versions support. Options that are unset aren't sent.
+type RunJarOptions struct {
+	// JobID is the ID of the job, generated by Flink when unset. Flink 1.9 and newer.
+	JobID string
+	// RestoreMode is the restore mode of the savepoint. Flink 1.15 and newer.
+	RestoreMode string
+	// FlinkConfiguration overrides the configuration of the cluster for the job. Flink 1.17 and newer.
+	FlinkConfiguration map[string]string
+}
+
+// unsupportedOption returns the first option which requires a newer
+// Flink version than the version, and the Flink version it requires
+func (o RunJarOptions) unsupportedOption(version Version) (string, string) {
+	switch {
+	case len(o.JobID) > 0 && !version.AtLeast(1, 9):
+		return "the job ID", "1.9"
+	case len(o.RestoreMode) > 0 && !version.AtLeast(1, 15):
+		return "the restore mode", "1.15"
+	case len(o.FlinkConfiguration) > 0 && !version.AtLeast(1, 17):
+		return "the Flink configuration", "1.17"
+	}
+	return "", ""
+}
+
+// IsEmpty returns whether none of the options are set
+func (o RunJarOptions) IsEmpty() bool {
+	return len(o.JobID) == 0 && len(o.RestoreMode) == 0 && len(o.FlinkConfiguration) == 0
+}
+
+// Validate checks the values of the options
+func (o RunJarOptions) Validate() error {
+	switch o.RestoreMode {
+	case "", RestoreModeClaim, RestoreModeNoClaim, RestoreModeLegacy:
+		return nil
+	}
+	return fmt.Errorf("unknown restore mode %v, only %v, %v and %v are supported", o.RestoreMode, RestoreModeClaim, RestoreModeNoClaim, RestoreModeLegacy)
+}
+
+// SupportedBy returns an error when the Flink version doesn't support one
+// of the options. Flink ignores unknown fields of the run request, so the
+// options would otherwise be dropped silently.
+func (o RunJarOptions) SupportedBy(version Version) error {
+	option, required := o.unsupportedOption(version)
+	if len(option) > 0 {
+		return fmt.Errorf("%v requires Flink %v or newer, but the cluster runs Flink %v", option, required, version)
+	}
+	return nil
+}
+
+// RunJarResponse represents the response body
+// used by the run JAR API
+type RunJarResponse struct {
+	JobID string `json:"jobid"`
+}
+
+// joinProgramArgs joins the program arguments into a single string, which
+// Flink splits on whitespace outside of quotes before it removes all quotes.
+// Arguments containing whitespace are quoted, arguments that can't survive
+// the round trip are rejected.
+func joinProgramArgs(jarArgs []string) (string, error) {
+	quoted := make([]string, len(jarArgs))
+	for i, arg := range jarArgs {
+		switch {
+		case len(arg) == 0 || strings.ContainsAny(arg, `"'`):
+			return "", fmt.Errorf("the program argument %q can't be passed to Flink as a string of arguments, which doesn't support empty arguments and quotes", arg)
+		case strings.IndexFunc(arg, unicode.IsSpace) >= 0:
+			quoted[i] = `"` + arg + `"`
+		default:
+			quoted[i] = arg
+		}
+	}
+	return strings.Join(quoted, " "), nil
+}
+
+// CheckRunJarOptions returns the error RunJar returns for the options,
+// which aren't supported by the Flink 1.7 REST API
+func (c FlinkRestClient) CheckRunJarOptions(ctx context.Context, options RunJarOptions) error {
+	option, required := options.unsupportedOption(Version{Major: 1, Minor: 7})
+	if len(option) > 0 {
+		return fmt.Errorf("%v requires Flink %v or newer, which the Flink 1.7 REST API doesn't support", option, required)
+	}
+	return nil
+}
+
+// RunJar executes a specific JAR file with the supplied parameters on the Flink cluster.
+// The program arguments are passed as a single string, in which arguments containing
+// whitespace are quoted. The options aren't supported by the Flink 1.7 REST API.
+func (c FlinkRestClient) RunJar(ctx context.Context, jarID string, entryClass string, jarArgs []string, parallelism int, savepointPath string, allowNonRestoredState bool, options RunJarOptions) (RunJarResponse, error) {
+	err := c.CheckRunJarOptions(ctx, options)
+	if err != nil {
+		return RunJarResponse{}, err
+	}
+
+	programArgs, err := joinProgramArgs(jarArgs)
+	if err != nil {
+		return RunJarResponse{}, err
+	}
+
+	return c.runJar(ctx, jarID, runJarRequest{
+		EntryClass:            entryClass,
+		ProgramArgs:           programArgs,
+		Parallelism:           parallelism,
+		AllowNonRestoredState: allowNonRestoredState,
+		SavepointPath:         savepointPath,
+	})
+}
+
+func (c FlinkRestClient) runJarWithProgramArgsList(ctx context.Context, jarID string, entryClass string, jarArgs []string, parallelism int, savepointPath string, allowNonRestoredState bool, options RunJarOptions) (RunJarResponse, error) {
+	if jarArgs == nil {
+		jarArgs = []string{}
+	}
+	return c.runJar(ctx, jarID, runJarWithProgramArgsListRequest{
+		EntryClass:            entryClass,
+		ProgramArgsList:       jarArgs,
+		Parallelism:           parallelism,
+		AllowNonRestoredState: allowNonRestoredState,
+		SavepointPath:         savepointPath,
+		JobID:                 options.JobID,
+		RestoreMode:           options.RestoreMode,
+		FlinkConfiguration:    options.FlinkConfiguration,
+	})
+}
+
+func (c FlinkRestClient) runJar(ctx context.Context, jarID string, runJarRequest interface{}) (RunJarResponse, error) {
+	reqBody := new(bytes.Buffer)
+	json.NewEncoder(reqBody).Encode(runJarRequest)
+
+	req, err := c.newRequest(ctx, "POST", c.constructURL(fmt.Sprintf("jars/%v/run", jarID)), reqBody)
+	if err != nil {
+		return RunJarResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// A failing job is reported with status 500, which isn't retried
+	// so the exception in the body of the response is returned
+	client := c.clientFor(OperationStart)
+	client.CheckRetry = RetryPolicy
+	res, err := c.send(client, req)
+	if err != nil {
+		return RunJarResponse{}, err
+	}
+
+	defer res.Body.Close()
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return RunJarResponse{}, err
+	}
+
+	if res.StatusCode != 200 {
+		return RunJarResponse{}, newAPIError(res, resBody)
+	}
+
+	response := RunJarResponse{}
+	err = json.Unmarshal(resBody, &response)
+	if err != nil {
+		return RunJarResponse{}, fmt.Errorf("Unable to parse API response as valid JSON: %v", string(resBody[:]))
+	}
+
+	return response, nil
+}
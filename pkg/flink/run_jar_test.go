@@ -0,0 +1,66 @@
+package flink
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunJarReturnsAnErrorWhenTheStatusIsNot200(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jars/id/run", `{"entryClass":"MainClass","programArgs":"","parallelism":1,"allowNonRestoredState":false,"savepointPath":"/data/flink"}`, http.StatusAccepted, "{}")
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	_, err := api.RunJar(context.Background(), "id", "MainClass", []string{}, 1, "/data/flink", false, RunJarOptions{})
+
+	assert.EqualError(t, err, "Unexpected response status 202 with body {}")
+}
+
+func TestRunJarReturnsAnErrorWhenTheResponseIsInvalidJSON(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jars/id/run", `{"entryClass":"MainClass","programArgs":"","parallelism":1,"allowNonRestoredState":false,"savepointPath":"/data/flink"}`, http.StatusOK, "")
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	_, err := api.RunJar(context.Background(), "id", "MainClass", []string{}, 1, "/data/flink", false, RunJarOptions{})
+
+	assert.EqualError(t, err, "Unable to parse API response as valid JSON: ")
+}
+
+func TestRunJarCorrectlyReturnsTheJobIDWhenTheCallSucceeds(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jars/id/run", `{"entryClass":"MainClass","programArgs":"","parallelism":1,"allowNonRestoredState":false,"savepointPath":"/data/flink"}`, http.StatusOK, `{"jobid": "job-1"}`)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	response, err := api.RunJar(context.Background(), "id", "MainClass", []string{}, 1, "/data/flink", false, RunJarOptions{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "job-1", response.JobID)
+}
+
+func TestRunJarShouldRejectProgramArgsWithQuotes(t *testing.T) {
+	api := FlinkRestClient{BaseURL: "http://localhost:8081"}
+
+	_, err := api.RunJar(context.Background(), "id", "MainClass", []string{"--name", `"a"`}, 1, "", false, RunJarOptions{})
+
+	assert.EqualError(t, err, `the program argument "\"a\"" can't be passed to Flink as a string of arguments, which doesn't support empty arguments and quotes`)
+}
+
+func TestRunJarShouldRejectTheOptions(t *testing.T) {
+	api := FlinkRestClient{BaseURL: "http://localhost:8081"}
+
+	_, err := api.RunJar(context.Background(), "id", "MainClass", nil, 1, "", false, RunJarOptions{JobID: "a8c2a1e0a2b94e5f8a6b7c3d4e5f6a7b"})
+
+	assert.EqualError(t, err, "the job ID requires Flink 1.9 or newer, which the Flink 1.7 REST API doesn't support")
+}
@@ -0,0 +1,152 @@
+package flink
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+type createSavepointRequest struct {
+	TargetDirectory string `json:"target-directory"`
+	CancelJob       bool   `json:"cancel-job"`
+}
+
+// CreateSavepointResponse represents the response body
+// used by the create savepoint API
+type CreateSavepointResponse struct {
+	RequestID string `json:"request-id"`
+}
+
+// createSavepointWithTriggerIDRequest is the savepoint request used by Flink 1.16
+// and newer, which deduplicate requests carrying the same trigger ID
+type createSavepointWithTriggerIDRequest struct {
+	TargetDirectory string `json:"target-directory"`
+	CancelJob       bool   `json:"cancel-job"`
+	TriggerID       string `json:"triggerId"`
+}
+
+// CreateSavepoint creates a savepoint for a job specified by job ID
+func (c FlinkRestClient) CreateSavepoint(ctx context.Context, jobID string, savepointPath string) (CreateSavepointResponse, error) {
+	return c.createSavepoint(ctx, c.do, OperationSavepoint, fmt.Sprintf("jobs/%v/savepoints", jobID), createSavepointRequest{
+		TargetDirectory: savepointPath,
+		CancelJob:       false,
+	})
+}
+
+func (c FlinkRestClient) createSavepointWithTriggerID(ctx context.Context, jobID string, savepointPath string) (CreateSavepointResponse, error) {
+	triggerID, err := newTriggerID()
+	if err != nil {
+		return CreateSavepointResponse{}, err
+	}
+
+	return c.createSavepoint(ctx, c.do, OperationSavepoint, fmt.Sprintf("jobs/%v/savepoints", jobID), createSavepointWithTriggerIDRequest{
+		TargetDirectory: savepointPath,
+		CancelJob:       false,
+		TriggerID:       triggerID,
+	})
+}
+
+// newTriggerID generates a random ID in the hexadecimal format Flink uses for trigger IDs
+func newTriggerID() (string, error) {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+func (c FlinkRestClient) createSavepoint(ctx context.Context, send func(string, *retryablehttp.Request) (*http.Response, error), operation string, path string, createSavepointRequest interface{}) (CreateSavepointResponse, error) {
+	reqBody := new(bytes.Buffer)
+	json.NewEncoder(reqBody).Encode(createSavepointRequest)
+
+	req, err := c.newRequest(ctx, "POST", c.constructURL(path), reqBody)
+	if err != nil {
+		return CreateSavepointResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := send(operation, req)
+	if err != nil {
+		return CreateSavepointResponse{}, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return CreateSavepointResponse{}, err
+	}
+
+	if res.StatusCode != 202 {
+		return CreateSavepointResponse{}, newAPIError(res, body)
+	}
+
+	response := CreateSavepointResponse{}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return CreateSavepointResponse{}, fmt.Errorf("Unable to parse API response as valid JSON: %v", string(body[:]))
+	}
+
+	return response, nil
+}
+
+// SavepointCreationStatus represents the
+// savepoint creation status used by the API
+type SavepointCreationStatus struct {
+	Id string `json:"id"`
+}
+
+// SavepointOperation represents the result of
+// a completed savepoint creation
+type SavepointOperation struct {
+	Location     string        `json:"location"`
+	FailureCause *FailureCause `json:"failure-cause"`
+}
+
+// MonitorSavepointCreationResponse represents the response body
+// used by the savepoint monitoring API
+type MonitorSavepointCreationResponse struct {
+	Status    SavepointCreationStatus `json:"status"`
+	Operation *SavepointOperation     `json:"operation"`
+}
+
+// MonitorSavepointCreation allows for monitoring the status of a savepoint creation
+// identified by the job ID and request ID
+func (c FlinkRestClient) MonitorSavepointCreation(ctx context.Context, jobID string, requestID string) (MonitorSavepointCreationResponse, error) {
+	req, err := c.newRequest(ctx, "GET", c.constructURL(fmt.Sprintf("jobs/%v/savepoints/%v", jobID, requestID)), nil)
+	if err != nil {
+		return MonitorSavepointCreationResponse{}, err
+	}
+
+	res, err := c.do(OperationSavepoint, req)
+	if err != nil {
+		return MonitorSavepointCreationResponse{}, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return MonitorSavepointCreationResponse{}, err
+	}
+
+	if res.StatusCode != 200 {
+		return MonitorSavepointCreationResponse{}, newAPIError(res, body)
+	}
+
+	response := MonitorSavepointCreationResponse{}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return MonitorSavepointCreationResponse{}, fmt.Errorf("Unable to parse API response as valid JSON: %v", string(body[:]))
+	}
+
+	return response, nil
+}
@@ -1,6 +1,7 @@
 package flink
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
@@ -19,7 +20,7 @@ func TestCreateSavepointReturnsAnErrorWhenTheStatusIsNot202(t *testing.T) {
 		BaseURL: server.URL,
 		Client:  retryablehttp.NewClient(),
 	}
-	_, err := api.CreateSavepoint("1", "/data/flink")
+	_, err := api.CreateSavepoint(context.Background(), "1", "/data/flink")
 
 	assert.EqualError(t, err, "Unexpected response status 200 with body {}")
 }
@@ -32,7 +33,7 @@ func TestCreateSavepointReturnsAnErrorWhenItCannotDeserializeTheResponseAsJSON(t
 		BaseURL: server.URL,
 		Client:  retryablehttp.NewClient(),
 	}
-	_, err := api.CreateSavepoint("1", "/data/flink")
+	_, err := api.CreateSavepoint(context.Background(), "1", "/data/flink")
 
 	assert.EqualError(t, err, "Unable to parse API response as valid JSON: {\"jobs: []}")
 }
@@ -45,7 +46,7 @@ func TestCreateSavepointCorrectlyReturnsARequestID(t *testing.T) {
 		BaseURL: server.URL,
 		Client:  retryablehttp.NewClient(),
 	}
-	res, err := api.CreateSavepoint("1", "/data/flink")
+	res, err := api.CreateSavepoint(context.Background(), "1", "/data/flink")
 
 	assert.Equal(t, res.RequestID, "1")
 	assert.Nil(t, err)
@@ -62,7 +63,7 @@ func TestMonitorSavepointCreationReturnsAnErrorWhenTheStatusIsNot200(t *testing.
 		BaseURL: server.URL,
 		Client:  retryablehttp.NewClient(),
 	}
-	_, err := api.MonitorSavepointCreation("id-1", "request-id-1")
+	_, err := api.MonitorSavepointCreation(context.Background(), "id-1", "request-id-1")
 
 	assert.EqualError(t, err, "Unexpected response status 202 with body {}")
 }
@@ -75,7 +76,7 @@ func TestMonitorSavepointCreationReturnsAnErrorWhenItCannotDeserializeTheRespons
 		BaseURL: server.URL,
 		Client:  retryablehttp.NewClient(),
 	}
-	_, err := api.MonitorSavepointCreation("id-1", "request-id-1")
+	_, err := api.MonitorSavepointCreation(context.Background(), "id-1", "request-id-1")
 
 	assert.EqualError(t, err, "Unable to parse API response as valid JSON: {\"jobs: []}")
 }
@@ -88,8 +89,22 @@ func TestMonitorSavepointCreationCorrectlyReturnsARequestID(t *testing.T) {
 		BaseURL: server.URL,
 		Client:  retryablehttp.NewClient(),
 	}
-	res, err := api.MonitorSavepointCreation("id-1", "request-id-1")
+	res, err := api.MonitorSavepointCreation(context.Background(), "id-1", "request-id-1")
 
 	assert.Equal(t, res.Status.Id, "PENDING")
 	assert.Nil(t, err)
 }
+
+func TestMonitorSavepointCreationReturnsTheLocationOfACompletedSavepoint(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/id-1/savepoints/request-id-1", "", http.StatusOK, `{"status":{"id":"COMPLETED"},"operation":{"location":"file:/data/flink/savepoint-1"}}`)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	res, err := api.MonitorSavepointCreation(context.Background(), "id-1", "request-id-1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "file:/data/flink/savepoint-1", res.Operation.Location)
+}
@@ -0,0 +1,80 @@
+package flink
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+type TerminateJobErrorResponse struct {
+	ErrInfo string `json:"error"`
+}
+
+// Terminate terminates a running job specified by job ID
+func (c FlinkRestClient) Terminate(ctx context.Context, jobID string, mode string) error {
+	var path string
+	if len(mode) > 0 {
+		path = fmt.Sprintf("jobs/%v?mode=%v", jobID, mode)
+	} else {
+		path = fmt.Sprintf("jobs/%v", jobID)
+	}
+
+	req, err := c.newRequest(ctx, "PATCH", c.constructURL(path), nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.do(OperationStop, req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != 202 {
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+
+		return newAPIError(res, body)
+	}
+
+	return nil
+}
+
+type stopJobRequest struct {
+	TargetDirectory string `json:"targetDirectory,omitempty"`
+	Drain           bool   `json:"drain"`
+}
+
+// StopJob stops a job specified by job ID using the stop API of Flink 1.9 and newer.
+// The job is stopped after taking a savepoint in the target directory, or in the
+// default savepoint directory of the cluster when it's empty. The returned request
+// ID identifies the savepoint, which is monitored like any other savepoint. The
+// request is sent only once, as a retried request would fail because the job is
+// already stopping, or take a second savepoint.
+func (c FlinkRestClient) StopJob(ctx context.Context, jobID string, targetDirectory string) (CreateSavepointResponse, error) {
+	return c.createSavepoint(ctx, c.doOnce, OperationStop, fmt.Sprintf("jobs/%v/stop", jobID), stopJobRequest{
+		TargetDirectory: targetDirectory,
+		Drain:           false,
+	})
+}
+
+// Do not retry when status code is 500. (indicating the job is not stoppable)
+func RetryPolicy(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+
+	if err != nil {
+		return true, err
+	}
+
+	if resp.StatusCode == 0 || resp.StatusCode > 500 {
+		return true, nil
+	}
+
+	return false, nil
+}
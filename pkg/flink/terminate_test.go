@@ -1,6 +1,7 @@
 package flink
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
@@ -17,7 +18,7 @@ func TestTerminateWithModeCancelAndStatusSuccess(t *testing.T) {
 		Client:  retryablehttp.NewClient(),
 	}
 
-	err := api.Terminate("id", "cancel")
+	err := api.Terminate(context.Background(), "id", "cancel")
 
 	assert.Nil(t, err)
 }
@@ -31,7 +32,7 @@ func TestTerminateWithModeCancelAndStatus404(t *testing.T) {
 		Client:  retryablehttp.NewClient(),
 	}
 
-	err := api.Terminate("id", "cancel")
+	err := api.Terminate(context.Background(), "id", "cancel")
 
 	assert.EqualError(t, err, "Unexpected response status 404 with body not found")
 }
@@ -45,7 +46,7 @@ func TestTerminateWithModeStopAndStatusSuccess(t *testing.T) {
 		Client:  retryablehttp.NewClient(),
 	}
 
-	err := api.Terminate("id", "stop")
+	err := api.Terminate(context.Background(), "id", "stop")
 
 	assert.Nil(t, err)
 }
@@ -59,7 +60,7 @@ func TestTerminateWithModeStopAndStatusFailure(t *testing.T) {
 		Client:  retryablehttp.NewClient(),
 	}
 
-	err := api.Terminate("id", "stop")
+	err := api.Terminate(context.Background(), "id", "stop")
 
 	assert.EqualError(t, err, "Unexpected response status 500 with body error")
 }
@@ -0,0 +1,61 @@
+package flink
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// TLSOptions configures how the connection to a Flink REST
+// endpoint served over HTTPS is secured
+type TLSOptions struct {
+	CACertFile         string
+	ClientCertFile     string
+	ClientKeyFile      string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// Enabled returns whether any of the options differ from the defaults
+func (o TLSOptions) Enabled() bool {
+	return len(o.CACertFile) > 0 || len(o.ClientCertFile) > 0 || len(o.ClientKeyFile) > 0 || len(o.ServerName) > 0 || o.InsecureSkipVerify
+}
+
+// NewTLSConfig creates the TLS configuration matching the options. The CA
+// bundle is used instead of the system roots, and the client certificate
+// is presented to clusters that require mutual TLS.
+func NewTLSConfig(o TLSOptions) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if len(o.CACertFile) > 0 {
+		caCert, err := ioutil.ReadFile(o.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the CA certificate: %v", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no PEM encoded certificates found in %v", o.CACertFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if len(o.ClientCertFile) > 0 || len(o.ClientKeyFile) > 0 {
+		if len(o.ClientCertFile) == 0 || len(o.ClientKeyFile) == 0 {
+			return nil, errors.New("both the client certificate and the client key must be specified")
+		}
+
+		certificate, err := tls.LoadX509KeyPair(o.ClientCertFile, o.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,197 @@
+package flink
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePEMFile(t *testing.T, dir string, name string, blockType string, bytes []byte) string {
+	path := filepath.Join(dir, name)
+	err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: bytes}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// createClientCertificate creates a self-signed client certificate and
+// returns the paths of the certificate and key files
+func createClientCertificate(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "flink-deployer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return certificate, writePEMFile(t, dir, "client.crt", "CERTIFICATE", der), writePEMFile(t, dir, "client.key", "EC PRIVATE KEY", keyBytes)
+}
+
+func createTLSTestServer(clientCA *x509.Certificate) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jobs": []}`))
+	}))
+	if clientCA != nil {
+		pool := x509.NewCertPool()
+		pool.AddCert(clientCA)
+		server.TLS = &tls.Config{
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  pool,
+		}
+	}
+	server.StartTLS()
+	return server
+}
+
+func createTLSClient(t *testing.T, options TLSOptions) *retryablehttp.Client {
+	config, err := NewTLSConfig(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := retryablehttp.NewClient()
+	client.RetryMax = 0
+	client.HTTPClient = &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+	return client
+}
+
+func TestTLSOptionsEnabledIsFalseForTheDefaults(t *testing.T) {
+	assert.False(t, TLSOptions{}.Enabled())
+	assert.True(t, TLSOptions{InsecureSkipVerify: true}.Enabled())
+	assert.True(t, TLSOptions{CACertFile: "ca.crt"}.Enabled())
+}
+
+func TestNewTLSConfigReturnsAnErrorWhenTheCACertificateIsMissing(t *testing.T) {
+	_, err := NewTLSConfig(TLSOptions{CACertFile: "/non-existing/ca.crt"})
+
+	assert.EqualError(t, err, "unable to read the CA certificate: open /non-existing/ca.crt: no such file or directory")
+}
+
+func TestNewTLSConfigReturnsAnErrorWhenTheClientKeyIsMissing(t *testing.T) {
+	_, err := NewTLSConfig(TLSOptions{ClientCertFile: "client.crt"})
+
+	assert.EqualError(t, err, "both the client certificate and the client key must be specified")
+}
+
+func TestTLSConnectionIsVerifiedWithTheCACertificate(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "tls")
+	defer os.RemoveAll(dir)
+
+	server := createTLSTestServer(nil)
+	defer server.Close()
+	caCertFile := writePEMFile(t, dir, "ca.crt", "CERTIFICATE", server.Certificate().Raw)
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  createTLSClient(t, TLSOptions{CACertFile: caCertFile}),
+	}
+	jobs, err := api.RetrieveJobs(context.Background())
+
+	assert.Nil(t, err)
+	assert.Len(t, jobs, 0)
+}
+
+func TestTLSConnectionFailsWithoutTheCACertificate(t *testing.T) {
+	server := createTLSTestServer(nil)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  createTLSClient(t, TLSOptions{}),
+	}
+	_, err := api.RetrieveJobs(context.Background())
+
+	assert.NotNil(t, err)
+}
+
+func TestTLSConnectionUsesTheServerNameOverride(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "tls")
+	defer os.RemoveAll(dir)
+
+	server := createTLSTestServer(nil)
+	defer server.Close()
+	caCertFile := writePEMFile(t, dir, "ca.crt", "CERTIFICATE", server.Certificate().Raw)
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  createTLSClient(t, TLSOptions{CACertFile: caCertFile, ServerName: "unknown.example.org"}),
+	}
+	_, err := api.RetrieveJobs(context.Background())
+
+	assert.NotNil(t, err)
+}
+
+func TestTLSConnectionSkipsVerificationWhenInsecure(t *testing.T) {
+	server := createTLSTestServer(nil)
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  createTLSClient(t, TLSOptions{InsecureSkipVerify: true}),
+	}
+	_, err := api.RetrieveJobs(context.Background())
+
+	assert.Nil(t, err)
+}
+
+func TestMutualTLSConnectionPresentsTheClientCertificate(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "tls")
+	defer os.RemoveAll(dir)
+
+	clientCertificate, clientCertFile, clientKeyFile := createClientCertificate(t, dir)
+	server := createTLSTestServer(clientCertificate)
+	defer server.Close()
+	caCertFile := writePEMFile(t, dir, "ca.crt", "CERTIFICATE", server.Certificate().Raw)
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client: createTLSClient(t, TLSOptions{
+			CACertFile:     caCertFile,
+			ClientCertFile: clientCertFile,
+			ClientKeyFile:  clientKeyFile,
+		}),
+	}
+	_, err := api.RetrieveJobs(context.Background())
+	assert.Nil(t, err)
+
+	api.Client = createTLSClient(t, TLSOptions{CACertFile: caCertFile})
+	_, err = api.RetrieveJobs(context.Background())
+	assert.NotNil(t, err)
+}
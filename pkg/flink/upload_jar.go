@@ -2,6 +2,7 @@ package flink
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -19,7 +20,7 @@ type UploadJarResponse struct {
 	Status   string `json:"status"`
 }
 
-func (c FlinkRestClient) constructUploadJarRequest(filename string, url string) (*http.Response, error) {
+func (c FlinkRestClient) constructUploadJarRequest(ctx context.Context, filename string, url string) (*http.Response, error) {
 	buffer := &bytes.Buffer{}
 	writer := multipart.NewWriter(buffer)
 
@@ -42,18 +43,18 @@ func (c FlinkRestClient) constructUploadJarRequest(filename string, url string)
 	contentType := writer.FormDataContentType()
 	writer.Close()
 
-	req, err := c.newRequest("POST", url, buffer)
+	req, err := c.newRequest(ctx, "POST", url, buffer)
 	if err != nil {
 		return &http.Response{}, err
 	}
 	req.Header.Set("Content-Type", contentType)
 
-	return c.Client.Do(req)
+	return c.do(OperationUpload, req)
 }
 
 // UploadJar allows for uploading a JAR file to the Flink cluster
-func (c FlinkRestClient) UploadJar(filename string) (UploadJarResponse, error) {
-	res, err := c.constructUploadJarRequest(filename, c.constructURL("jars/upload"))
+func (c FlinkRestClient) UploadJar(ctx context.Context, filename string) (UploadJarResponse, error) {
+	res, err := c.constructUploadJarRequest(ctx, filename, c.constructURL("jars/upload"))
 	if err != nil {
 		return UploadJarResponse{}, err
 	}
@@ -66,7 +67,7 @@ func (c FlinkRestClient) UploadJar(filename string) (UploadJarResponse, error) {
 	}
 
 	if res.StatusCode != 200 {
-		return UploadJarResponse{}, fmt.Errorf("Unexpected response status %v with body %v", res.StatusCode, string(body[:]))
+		return UploadJarResponse{}, newAPIError(res, body)
 	}
 
 	response := UploadJarResponse{}
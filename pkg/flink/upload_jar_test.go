@@ -1,6 +1,7 @@
 package flink
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
@@ -19,7 +20,7 @@ func TestUploadJarReturnsAnErrorWhenTheStatusIsNot200(t *testing.T) {
 		BaseURL: server.URL,
 		Client:  retryablehttp.NewClient(),
 	}
-	_, err := api.UploadJar("../testdata/sample.jar")
+	_, err := api.UploadJar(context.Background(), "testdata/sample.jar")
 
 	assert.EqualError(t, err, "Unexpected response status 202 with body {}")
 }
@@ -32,7 +33,7 @@ func TestUploadJarReturnsAnErrorWhenItCannotDeserializeTheResponseAsJSON(t *test
 		BaseURL: server.URL,
 		Client:  retryablehttp.NewClient(),
 	}
-	_, err := api.UploadJar("../testdata/sample.jar")
+	_, err := api.UploadJar(context.Background(), "testdata/sample.jar")
 
 	assert.EqualError(t, err, "Unable to parse API response as valid JSON: {\"jobs: []}")
 }
@@ -45,7 +46,7 @@ func TestUploadJarCorrectlyReturnsARequestID(t *testing.T) {
 		BaseURL: server.URL,
 		Client:  retryablehttp.NewClient(),
 	}
-	res, err := api.UploadJar("../testdata/sample.jar")
+	res, err := api.UploadJar(context.Background(), "testdata/sample.jar")
 
 	assert.Equal(t, res.Filename, "/flink/jars/sample.jar")
 	assert.Equal(t, res.Status, "success")
@@ -0,0 +1,99 @@
+package flink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+)
+
+// A Version is the version of a Flink cluster
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+var versionPattern = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// ParseVersion parses a Flink version such as "1.7.2" or "1.18-SNAPSHOT"
+func ParseVersion(version string) (Version, error) {
+	matches := versionPattern.FindStringSubmatch(version)
+	if matches == nil {
+		return Version{}, fmt.Errorf("unable to parse Flink version \"%v\"", version)
+	}
+
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+	patch := 0
+	if len(matches[3]) > 0 {
+		patch, _ = strconv.Atoi(matches[3])
+	}
+
+	return Version{Major: major, Minor: minor, Patch: patch}, nil
+}
+
+// AtLeast returns whether the version is equal to or newer than major.minor
+func (v Version) AtLeast(major int, minor int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	return v.Minor >= minor
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%v.%v.%v", v.Major, v.Minor, v.Patch)
+}
+
+// DashboardConfig represents the response body
+// used by the config API
+type DashboardConfig struct {
+	RefreshInterval int64  `json:"refresh-interval"`
+	TimezoneName    string `json:"timezone-name"`
+	FlinkVersion    string `json:"flink-version"`
+	FlinkRevision   string `json:"flink-revision"`
+}
+
+// RetrieveConfig returns the dashboard configuration of the Flink cluster
+func (c FlinkRestClient) RetrieveConfig(ctx context.Context) (DashboardConfig, error) {
+	req, err := c.newRequest(ctx, "GET", c.constructURL("config"), nil)
+	if err != nil {
+		return DashboardConfig{}, err
+	}
+
+	res, err := c.do(OperationRead, req)
+	if err != nil {
+		return DashboardConfig{}, err
+	}
+
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return DashboardConfig{}, err
+	}
+
+	if res.StatusCode != 200 {
+		return DashboardConfig{}, newAPIError(res, body)
+	}
+
+	response := DashboardConfig{}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return DashboardConfig{}, fmt.Errorf("Unable to parse API response as valid JSON: %v", string(body[:]))
+	}
+
+	return response, nil
+}
+
+// DetectVersion retrieves the version of the Flink cluster
+func (c FlinkRestClient) DetectVersion(ctx context.Context) (Version, error) {
+	config, err := c.RetrieveConfig(ctx)
+	if err != nil {
+		return Version{}, err
+	}
+
+	return ParseVersion(config.FlinkVersion)
+}
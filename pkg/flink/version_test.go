@@ -0,0 +1,84 @@
+package flink
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func readConfigFixture(t *testing.T, version string) string {
+	content, err := ioutil.ReadFile("testdata/flink-" + version + "/config.json")
+	assert.Nil(t, err)
+	return string(content)
+}
+
+/*
+ * ParseVersion
+ */
+func TestParseVersionShouldParseAReleaseVersion(t *testing.T) {
+	version, err := ParseVersion("1.7.2")
+
+	assert.Nil(t, err)
+	assert.Equal(t, Version{Major: 1, Minor: 7, Patch: 2}, version)
+}
+
+func TestParseVersionShouldParseASnapshotVersion(t *testing.T) {
+	version, err := ParseVersion("1.18-SNAPSHOT")
+
+	assert.Nil(t, err)
+	assert.Equal(t, Version{Major: 1, Minor: 18}, version)
+}
+
+func TestParseVersionShouldReturnAnErrorForAnInvalidVersion(t *testing.T) {
+	_, err := ParseVersion("<unknown>")
+
+	assert.EqualError(t, err, "unable to parse Flink version \"<unknown>\"")
+}
+
+/*
+ * AtLeast
+ */
+func TestAtLeastShouldCompareTheMajorAndMinorVersion(t *testing.T) {
+	version := Version{Major: 1, Minor: 9, Patch: 3}
+
+	assert.True(t, version.AtLeast(1, 8))
+	assert.True(t, version.AtLeast(1, 9))
+	assert.False(t, version.AtLeast(1, 10))
+	assert.False(t, version.AtLeast(2, 0))
+}
+
+/*
+ * DetectVersion
+ */
+func TestDetectVersionReturnsAnErrorWhenTheStatusIsNot200(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/config", "", http.StatusNotFound, "{}")
+	defer server.Close()
+
+	api := FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	}
+	_, err := api.DetectVersion(context.Background())
+
+	assert.EqualError(t, err, "Unexpected response status 404 with body {}")
+}
+
+func TestDetectVersionCorrectlyReturnsTheVersionForEachFixture(t *testing.T) {
+	for _, fixture := range []string{"1.7.2", "1.9.3", "1.16.3", "1.18.1"} {
+		server := createTestServerWithBodyCheck(t, "/config", "", http.StatusOK, readConfigFixture(t, fixture))
+
+		api := FlinkRestClient{
+			BaseURL: server.URL,
+			Client:  retryablehttp.NewClient(),
+		}
+		version, err := api.DetectVersion(context.Background())
+		server.Close()
+
+		assert.Nil(t, err)
+		assert.Equal(t, fixture, version.String())
+	}
+}
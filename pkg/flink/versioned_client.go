@@ -0,0 +1,138 @@
+package flink
+
+import (
+	"context"
+	"sync"
+)
+
+// A VersionedFlinkRestClient detects the version of the Flink cluster on first
+// use and routes the calls whose REST API differs between Flink versions to the
+// matching implementation. All other calls are handled by the FlinkRestClient,
+// which implements the Flink 1.7 REST API.
+type VersionedFlinkRestClient struct {
+	FlinkRestClient
+
+	mutex   *sync.Mutex
+	version *Version
+}
+
+// NewVersionedFlinkRestClient creates a VersionedFlinkRestClient which
+// detects the Flink version through the supplied client
+func NewVersionedFlinkRestClient(client FlinkRestClient) *VersionedFlinkRestClient {
+	return &VersionedFlinkRestClient{
+		FlinkRestClient: client,
+		mutex:           &sync.Mutex{},
+	}
+}
+
+// Version returns the version of the Flink cluster, which is detected through
+// the config API on the first call. Failed detections are retried on the next call.
+func (c *VersionedFlinkRestClient) Version(ctx context.Context) (Version, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.version != nil {
+		return *c.version, nil
+	}
+
+	version, err := c.FlinkRestClient.DetectVersion(ctx)
+	if err != nil {
+		return Version{}, Errorf("unable to detect the Flink version: %v", err)
+	}
+	c.version = &version
+
+	return version, nil
+}
+
+// CheckRunJarOptions returns the error RunJar returns for the options, which
+// are invalid or not supported by the Flink version of the cluster. Options
+// are checked before a job is stopped to make room for the new one.
+func (c *VersionedFlinkRestClient) CheckRunJarOptions(ctx context.Context, options RunJarOptions) error {
+	err := options.Validate()
+	if err != nil || options.IsEmpty() {
+		return err
+	}
+
+	version, err := c.Version(ctx)
+	if err != nil {
+		return err
+	}
+	return options.SupportedBy(version)
+}
+
+// RunJar executes a specific JAR file with the supplied parameters on the Flink cluster.
+// Flink 1.8 and newer receive the program arguments as a list, so arguments containing
+// spaces are passed on unchanged. Options which the Flink version doesn't support are
+// rejected.
+func (c *VersionedFlinkRestClient) RunJar(ctx context.Context, jarID string, entryClass string, jarArgs []string, parallelism int, savepointPath string, allowNonRestoredState bool, options RunJarOptions) (RunJarResponse, error) {
+	err := c.CheckRunJarOptions(ctx, options)
+	if err != nil {
+		return RunJarResponse{}, err
+	}
+
+	version, err := c.Version(ctx)
+	if err != nil {
+		return RunJarResponse{}, err
+	}
+
+	if version.AtLeast(1, 8) {
+		return c.FlinkRestClient.runJarWithProgramArgsList(ctx, jarID, entryClass, jarArgs, parallelism, savepointPath, allowNonRestoredState, options)
+	}
+	return c.FlinkRestClient.RunJar(ctx, jarID, entryClass, jarArgs, parallelism, savepointPath, allowNonRestoredState, options)
+}
+
+// CreateSavepoint creates a savepoint for a job specified by job ID. Flink 1.16 and
+// newer receive a trigger ID, so a retried request doesn't trigger a second savepoint.
+func (c *VersionedFlinkRestClient) CreateSavepoint(ctx context.Context, jobID string, savepointPath string) (CreateSavepointResponse, error) {
+	version, err := c.Version(ctx)
+	if err != nil {
+		return CreateSavepointResponse{}, err
+	}
+
+	if version.AtLeast(1, 16) {
+		return c.FlinkRestClient.createSavepointWithTriggerID(ctx, jobID, savepointPath)
+	}
+	return c.FlinkRestClient.CreateSavepoint(ctx, jobID, savepointPath)
+}
+
+// Terminate terminates a running job specified by job ID. Flink 1.9 removed the stop
+// mode of the job API in favour of the stop API, which stops the job with a savepoint
+// in the default savepoint directory of the cluster.
+func (c *VersionedFlinkRestClient) Terminate(ctx context.Context, jobID string, mode string) error {
+	if mode != "stop" {
+		return c.FlinkRestClient.Terminate(ctx, jobID, mode)
+	}
+
+	_, err := c.StopJob(ctx, jobID, "")
+	return err
+}
+
+// StopJob stops a running job specified by job ID. Flink 1.9 and newer take a
+// savepoint in the target directory before stopping the job, and return the
+// request ID of that savepoint. Older versions stop the job without a savepoint
+// through the job API, so the request ID is empty.
+func (c *VersionedFlinkRestClient) StopJob(ctx context.Context, jobID string, targetDirectory string) (CreateSavepointResponse, error) {
+	version, err := c.Version(ctx)
+	if err != nil {
+		return CreateSavepointResponse{}, err
+	}
+
+	if version.AtLeast(1, 9) {
+		return c.FlinkRestClient.StopJob(ctx, jobID, targetDirectory)
+	}
+	return CreateSavepointResponse{}, c.FlinkRestClient.Terminate(ctx, jobID, "stop")
+}
+
+// Rescale changes the parallelism of a running job specified by job ID.
+// Flink 1.9 and newer reject rescaling requests, so they aren't sent at all.
+func (c *VersionedFlinkRestClient) Rescale(ctx context.Context, jobID string, parallelism int) (RescaleResponse, error) {
+	version, err := c.Version(ctx)
+	if err != nil {
+		return RescaleResponse{}, err
+	}
+
+	if version.AtLeast(1, 9) {
+		return RescaleResponse{}, ErrRescalingUnsupported
+	}
+	return c.FlinkRestClient.Rescale(ctx, jobID, parallelism)
+}
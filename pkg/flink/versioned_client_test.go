@@ -0,0 +1,254 @@
+package flink
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+)
+
+// createVersionedTestServer serves the config fixture of the given Flink
+// version and verifies every other request like createTestServer does
+func createVersionedTestServer(t *testing.T, version string, expectedURL string, expectedBody string, status int, body string) *httptest.Server {
+	return createVersionedTestServerWithBodyPattern(t, version, expectedURL, regexp.QuoteMeta(expectedBody), status, body)
+}
+
+func createVersionedTestServerWithBodyPattern(t *testing.T, version string, expectedURL string, expectedBodyPattern string, status int, body string) *httptest.Server {
+	config := readConfigFixture(t, version)
+
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.String() == "/config" {
+			rw.WriteHeader(http.StatusOK)
+			rw.Write([]byte(config))
+			return
+		}
+
+		assert.Equal(t, expectedURL, req.URL.String())
+
+		reqBody, err := ioutil.ReadAll(req.Body)
+		assert.Nil(t, err)
+		assert.Regexp(t, "^"+expectedBodyPattern+"$", strings.Replace(string(reqBody[:]), "\n", "", -1))
+
+		rw.WriteHeader(status)
+		rw.Write([]byte(body))
+	}))
+}
+
+func constructVersionedTestClient(server *httptest.Server) *VersionedFlinkRestClient {
+	return NewVersionedFlinkRestClient(FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  retryablehttp.NewClient(),
+	})
+}
+
+/*
+ * Version
+ */
+func TestVersionReturnsAnErrorWhenTheVersionCannotBeDetected(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/config", "", http.StatusNotFound, "{}")
+	defer server.Close()
+
+	api := constructVersionedTestClient(server)
+	_, err := api.Version(context.Background())
+
+	assert.EqualError(t, err, "unable to detect the Flink version: Unexpected response status 404 with body {}")
+}
+
+func TestVersionKeepsTheCategoryOfTheDetectionFailure(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	client := retryablehttp.NewClient()
+	client.RetryMax = 0
+	client.Logger = nil
+
+	api := NewVersionedFlinkRestClient(FlinkRestClient{BaseURL: server.URL, Client: client})
+	_, err := api.Version(context.Background())
+
+	assert.True(t, strings.HasPrefix(err.Error(), "unable to detect the Flink version: "), err.Error())
+	assert.Equal(t, ErrClusterUnreachable, Category(err))
+}
+
+func TestVersionKeepsTheCategoryOfAnUnauthorizedDetection(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/config", "", http.StatusUnauthorized, "{}")
+	defer server.Close()
+
+	_, err := constructVersionedTestClient(server).Version(context.Background())
+
+	assert.Equal(t, ErrUnauthorized, Category(err))
+}
+
+func TestVersionOnlyDetectsTheVersionOnce(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		rw.Write([]byte(readConfigFixture(t, "1.9.3")))
+	}))
+	defer server.Close()
+
+	api := constructVersionedTestClient(server)
+	api.Version(context.Background())
+	version, err := api.Version(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, Version{Major: 1, Minor: 9, Patch: 3}, version)
+	assert.Equal(t, 1, requests)
+}
+
+/*
+ * RunJar
+ */
+func TestVersionedRunJarSendsTheProgramArgsAsStringOnFlink17(t *testing.T) {
+	server := createVersionedTestServer(t, "1.7.2", "/jars/id/run", `{"entryClass":"MainClass","programArgs":"--name \"a b\"","parallelism":1,"allowNonRestoredState":false,"savepointPath":""}`, http.StatusOK, "{}")
+	defer server.Close()
+
+	_, err := constructVersionedTestClient(server).RunJar(context.Background(), "id", "MainClass", []string{"--name", "a b"}, 1, "", false, RunJarOptions{})
+
+	assert.Nil(t, err)
+}
+
+func TestVersionedRunJarSendsTheProgramArgsAsListOnNewerVersions(t *testing.T) {
+	for _, version := range []string{"1.9.3", "1.16.3", "1.18.1"} {
+		server := createVersionedTestServer(t, version, "/jars/id/run", `{"entryClass":"MainClass","programArgsList":["--name","a b"],"parallelism":1,"allowNonRestoredState":false,"savepointPath":""}`, http.StatusOK, "{}")
+
+		_, err := constructVersionedTestClient(server).RunJar(context.Background(), "id", "MainClass", []string{"--name", "a b"}, 1, "", false, RunJarOptions{})
+		server.Close()
+
+		assert.Nil(t, err)
+	}
+}
+
+func TestVersionedRunJarSendsTheOptionsOnFlink118(t *testing.T) {
+	server := createVersionedTestServer(t, "1.18.1", "/jars/id/run", `{"entryClass":"MainClass","programArgsList":[],"parallelism":1,"allowNonRestoredState":false,"savepointPath":"/data/flink","jobId":"a8c2a1e0a2b94e5f8a6b7c3d4e5f6a7b","restoreMode":"CLAIM","flinkConfiguration":{"pipeline.name":"word-count"}}`, http.StatusOK, "{}")
+	defer server.Close()
+
+	_, err := constructVersionedTestClient(server).RunJar(context.Background(), "id", "MainClass", nil, 1, "/data/flink", false, RunJarOptions{
+		JobID:              "a8c2a1e0a2b94e5f8a6b7c3d4e5f6a7b",
+		RestoreMode:        RestoreModeClaim,
+		FlinkConfiguration: map[string]string{"pipeline.name": "word-count"},
+	})
+
+	assert.Nil(t, err)
+}
+
+func TestVersionedRunJarRejectsOptionsTheVersionDoesNotSupport(t *testing.T) {
+	server := createVersionedTestServer(t, "1.9.3", "", "", http.StatusOK, "{}")
+	defer server.Close()
+	api := constructVersionedTestClient(server)
+
+	_, err := api.RunJar(context.Background(), "id", "MainClass", nil, 1, "", false, RunJarOptions{RestoreMode: RestoreModeNoClaim})
+	assert.EqualError(t, err, "the restore mode requires Flink 1.15 or newer, but the cluster runs Flink 1.9.3")
+
+	_, err = api.RunJar(context.Background(), "id", "MainClass", nil, 1, "", false, RunJarOptions{FlinkConfiguration: map[string]string{"pipeline.name": "word-count"}})
+	assert.EqualError(t, err, "the Flink configuration requires Flink 1.17 or newer, but the cluster runs Flink 1.9.3")
+}
+
+func TestVersionedRunJarRejectsAnUnknownRestoreMode(t *testing.T) {
+	api := NewVersionedFlinkRestClient(FlinkRestClient{BaseURL: "http://localhost:8081"})
+
+	_, err := api.RunJar(context.Background(), "id", "MainClass", nil, 1, "", false, RunJarOptions{RestoreMode: "CLAIMED"})
+
+	assert.EqualError(t, err, "unknown restore mode CLAIMED, only CLAIM, NO_CLAIM and LEGACY are supported")
+}
+
+/*
+ * CreateSavepoint
+ */
+func TestVersionedCreateSavepointOmitsTheTriggerIDOnFlink19(t *testing.T) {
+	server := createVersionedTestServer(t, "1.9.3", "/jobs/1/savepoints", `{"target-directory":"/data/flink","cancel-job":false}`, http.StatusAccepted, `{"request-id":"2"}`)
+	defer server.Close()
+
+	res, err := constructVersionedTestClient(server).CreateSavepoint(context.Background(), "1", "/data/flink")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "2", res.RequestID)
+}
+
+func TestVersionedCreateSavepointSendsATriggerIDOnFlink116(t *testing.T) {
+	server := createVersionedTestServerWithBodyPattern(t, "1.16.3", "/jobs/1/savepoints", `\{"target-directory":"/data/flink","cancel-job":false,"triggerId":"[0-9a-f]{32}"\}`, http.StatusAccepted, `{"request-id":"2"}`)
+	defer server.Close()
+
+	res, err := constructVersionedTestClient(server).CreateSavepoint(context.Background(), "1", "/data/flink")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "2", res.RequestID)
+}
+
+/*
+ * Terminate
+ */
+func TestVersionedTerminateWithModeCancelDoesNotDetectTheVersion(t *testing.T) {
+	server := createTestServerWithBodyCheck(t, "/jobs/id?mode=cancel", "", http.StatusAccepted, "")
+	defer server.Close()
+
+	err := constructVersionedTestClient(server).Terminate(context.Background(), "id", "cancel")
+
+	assert.Nil(t, err)
+}
+
+func TestVersionedTerminateWithModeStopUsesTheJobAPIOnFlink17(t *testing.T) {
+	server := createVersionedTestServer(t, "1.7.2", "/jobs/id?mode=stop", "", http.StatusAccepted, "")
+	defer server.Close()
+
+	err := constructVersionedTestClient(server).Terminate(context.Background(), "id", "stop")
+
+	assert.Nil(t, err)
+}
+
+func TestVersionedTerminateWithModeStopUsesTheStopAPIOnNewerVersions(t *testing.T) {
+	for _, version := range []string{"1.9.3", "1.18.1"} {
+		server := createVersionedTestServer(t, version, "/jobs/id/stop", `{"drain":false}`, http.StatusAccepted, `{"request-id":"2"}`)
+
+		err := constructVersionedTestClient(server).Terminate(context.Background(), "id", "stop")
+		server.Close()
+
+		assert.Nil(t, err)
+	}
+}
+
+func TestVersionedStopJobSendsTheTargetDirectoryOnNewerVersions(t *testing.T) {
+	server := createVersionedTestServer(t, "1.18.1", "/jobs/id/stop", `{"targetDirectory":"/savepoints","drain":false}`, http.StatusAccepted, `{"request-id":"2"}`)
+	defer server.Close()
+
+	res, err := constructVersionedTestClient(server).StopJob(context.Background(), "id", "/savepoints")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "2", res.RequestID)
+}
+
+func TestVersionedStopJobReturnsNoRequestIDOnFlink17(t *testing.T) {
+	server := createVersionedTestServer(t, "1.7.2", "/jobs/id?mode=stop", "", http.StatusAccepted, "")
+	defer server.Close()
+
+	res, err := constructVersionedTestClient(server).StopJob(context.Background(), "id", "/savepoints")
+
+	assert.Nil(t, err)
+	assert.Empty(t, res.RequestID)
+}
+
+/*
+ * Rescale
+ */
+func TestVersionedRescaleUsesTheRescalingAPIOnFlink17(t *testing.T) {
+	server := createVersionedTestServer(t, "1.7.2", "/jobs/1/rescaling?parallelism=4", "", http.StatusOK, `{"request-id":"2"}`)
+	defer server.Close()
+
+	res, err := constructVersionedTestClient(server).Rescale(context.Background(), "1", 4)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "2", res.RequestID)
+}
+
+func TestVersionedRescaleReturnsErrRescalingUnsupportedOnNewerVersions(t *testing.T) {
+	server := createVersionedTestServer(t, "1.18.1", "", "", http.StatusInternalServerError, "")
+	defer server.Close()
+
+	_, err := constructVersionedTestClient(server).Rescale(context.Background(), "1", 4)
+
+	assert.Equal(t, ErrRescalingUnsupported, err)
+}
@@ -0,0 +1,365 @@
+// Package flinktest provides an in-memory Flink cluster which implements the
+// parts of the Flink REST API used by the deployer, for tests and local demos:
+//
+//	cluster := flinktest.NewCluster()
+//	server := httptest.NewServer(cluster)
+//	defer server.Close()
+//
+// Uploaded JAR files run as jobs until they are cancelled or stopped.
+// Savepoints complete asynchronously once the SavepointDuration has passed
+// and checkpoints complete every CheckpointInterval. Failures and latencies
+// can be injected per endpoint to exercise the error handling of clients.
+package flinktest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// The statuses of a job
+const (
+	StatusRunning  = "RUNNING"
+	StatusCanceled = "CANCELED"
+	StatusFinished = "FINISHED"
+	StatusFailed   = "FAILED"
+)
+
+// A Cluster is an in-memory Flink cluster serving the Flink REST API. Configure
+// its exported fields before it serves the first request.
+type Cluster struct {
+	// Version is the Flink version reported by the cluster
+	Version string
+	// TaskManagers is the number of task managers of the cluster
+	TaskManagers int
+	// SlotsPerTaskManager is the number of task slots of every task manager
+	SlotsPerTaskManager int
+	// SavepointDuration is the time a savepoint takes to complete
+	SavepointDuration time.Duration
+	// CheckpointInterval is the time between the checkpoints of a running
+	// job. Jobs don't checkpoint when it is zero.
+	CheckpointInterval time.Duration
+	// DefaultSavepointDir is the savepoint directory used when a job is
+	// stopped without a target directory, like `state.savepoints.dir`
+	DefaultSavepointDir string
+	// Filesystem, when set, receives a directory with a _metadata file for
+	// every completed savepoint, so the latest savepoint can be found in
+	// the savepoint directory
+	Filesystem afero.Fs
+	// Logger, when set, logs the changes to the state of the cluster
+	Logger *log.Logger
+
+	mutex            sync.Mutex
+	now              func() time.Time
+	jars             []*Jar
+	jobs             []*Job
+	savepoints       []*savepoint
+	rescalings       map[string]string
+	savepointFailure string
+	failures         []*injectedFailure
+	latencies        []Latency
+	requests         []string
+	logLines         []string
+}
+
+// A Jar is a JAR file uploaded to the cluster
+type Jar struct {
+	ID       string
+	Name     string
+	Content  []byte
+	Uploaded time.Time
+}
+
+// A Job is the state of a job on the cluster
+type Job struct {
+	ID                    string
+	Name                  string
+	Status                string
+	JarID                 string
+	EntryClass            string
+	ProgramArgs           []string
+	Parallelism           int
+	SavepointPath         string
+	AllowNonRestoredState bool
+	RestoreMode           string
+	FlinkConfiguration    map[string]string
+	StartTime             time.Time
+	EndTime               time.Time
+	Exception             string
+
+	savepoints    int
+	lastSavepoint *savepoint
+}
+
+// A savepoint is an asynchronous savepoint operation, which
+// optionally terminates the job once it has completed
+type savepoint struct {
+	jobID           string
+	triggerID       string
+	targetDirectory string
+	terminateStatus string
+	triggered       time.Time
+	completed       bool
+	location        string
+	failure         string
+}
+
+// NewCluster creates a cluster running Flink 1.9.0 with
+// a single task manager offering four task slots
+func NewCluster() *Cluster {
+	return &Cluster{
+		Version:             "1.9.0",
+		TaskManagers:        1,
+		SlotsPerTaskManager: 4,
+		now:                 time.Now,
+		rescalings:          map[string]string{},
+	}
+}
+
+// newID generates a random ID in the hexadecimal format Flink uses for IDs
+func newID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// logf records a change to the state of the cluster
+// in the job manager log, and logs it to the Logger
+func (c *Cluster) logf(format string, args ...interface{}) {
+	line := fmt.Sprintf(format, args...)
+	c.logLines = append(c.logLines, fmt.Sprintf("%v INFO %v", c.now().UTC().Format("2006-01-02 15:04:05,000"), line))
+	if c.Logger != nil {
+		c.Logger.Println(line)
+	}
+}
+
+func (c *Cluster) findJar(jarID string) *Jar {
+	for _, jar := range c.jars {
+		if jar.ID == jarID {
+			return jar
+		}
+	}
+	return nil
+}
+
+func (c *Cluster) findJob(jobID string) *Job {
+	for _, job := range c.jobs {
+		if job.ID == jobID {
+			return job
+		}
+	}
+	return nil
+}
+
+func (c *Cluster) findSavepoint(jobID string, triggerID string) *savepoint {
+	for _, s := range c.savepoints {
+		if s.jobID == jobID && s.triggerID == triggerID {
+			return s
+		}
+	}
+	return nil
+}
+
+// slotsTotal returns the number of task slots of the cluster
+func (c *Cluster) slotsTotal() int {
+	return c.TaskManagers * c.SlotsPerTaskManager
+}
+
+// slotsUsed returns the number of task slots used by running jobs
+func (c *Cluster) slotsUsed() int {
+	used := 0
+	for _, job := range c.jobs {
+		if job.Status == StatusRunning {
+			used += job.Parallelism
+		}
+	}
+	return used
+}
+
+// startJob adds a running job to the cluster
+func (c *Cluster) startJob(job *Job) *Job {
+	if len(job.ID) == 0 {
+		job.ID = newID()
+	}
+	job.Status = StatusRunning
+	job.StartTime = c.now()
+	if job.Parallelism <= 0 {
+		job.Parallelism = 1
+	}
+	c.jobs = append(c.jobs, job)
+
+	if len(job.SavepointPath) > 0 {
+		c.logf("Job %v (%v) restored from savepoint %v", job.Name, job.ID, job.SavepointPath)
+	}
+	c.logf("Job %v (%v) switched from state CREATED to RUNNING", job.Name, job.ID)
+	return job
+}
+
+// endJob moves a running job to a terminal status
+func (c *Cluster) endJob(job *Job, status string) {
+	c.logf("Job %v (%v) switched from state %v to %v", job.Name, job.ID, job.Status, status)
+	job.Status = status
+	job.EndTime = c.now()
+}
+
+// progress completes the savepoints which have taken the SavepointDuration
+func (c *Cluster) progress() {
+	for _, s := range c.savepoints {
+		if s.completed || c.now().Before(s.triggered.Add(c.SavepointDuration)) {
+			continue
+		}
+		c.completeSavepoint(s)
+	}
+}
+
+func (c *Cluster) completeSavepoint(s *savepoint) {
+	s.completed = true
+
+	job := c.findJob(s.jobID)
+	if job.Status != StatusRunning {
+		s.failure = fmt.Sprintf("java.lang.IllegalStateException: Job %v is not running", job.ID)
+		return
+	}
+	if len(c.savepointFailure) > 0 {
+		s.failure = c.savepointFailure
+		c.logf("Savepoint of job %v (%v) failed: %v", job.Name, job.ID, s.failure)
+		return
+	}
+
+	s.location = fmt.Sprintf("%v/savepoint-%v-%v", strings.TrimRight(s.targetDirectory, "/"), job.ID[:6], newID()[:12])
+	if c.Filesystem != nil {
+		err := c.writeSavepoint(s.location)
+		if err != nil {
+			s.location = ""
+			s.failure = fmt.Sprintf("java.io.IOException: %v", err)
+			return
+		}
+	}
+	job.savepoints++
+	job.lastSavepoint = s
+	c.logf("Savepoint of job %v (%v) completed at %v", job.Name, job.ID, s.location)
+
+	if len(s.terminateStatus) > 0 {
+		c.endJob(job, s.terminateStatus)
+	}
+}
+
+// writeSavepoint writes the directory of a completed savepoint to the Filesystem
+func (c *Cluster) writeSavepoint(location string) error {
+	dir := strings.TrimPrefix(location, "file://")
+	err := c.Filesystem.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	err = afero.WriteFile(c.Filesystem, path.Join(dir, "_metadata"), []byte{}, 0644)
+	if err != nil {
+		return err
+	}
+	return c.Filesystem.Chtimes(dir, c.now(), c.now())
+}
+
+// StartJob starts a job which doesn't belong to an uploaded JAR file,
+// e.g. to set up a job to update, and returns its ID
+func (c *Cluster) StartJob(name string, parallelism int) string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.startJob(&Job{Name: name, Parallelism: parallelism}).ID
+}
+
+// FailJob fails a running job with the exception
+func (c *Cluster) FailJob(jobID string, exception string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	job := c.findJob(jobID)
+	if job == nil {
+		return fmt.Errorf("job %v doesn't exist", jobID)
+	}
+	if job.Status != StatusRunning {
+		return errors.New("only running jobs can fail")
+	}
+
+	job.Exception = exception
+	c.endJob(job, StatusFailed)
+	return nil
+}
+
+// FailSavepoints makes all following savepoints fail with the cause.
+// Supply an empty cause to let savepoints complete again.
+func (c *Cluster) FailSavepoints(cause string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.savepointFailure = cause
+}
+
+// Jars returns the JAR files uploaded to the cluster
+func (c *Cluster) Jars() []Jar {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	jars := []Jar{}
+	for _, jar := range c.jars {
+		jars = append(jars, *jar)
+	}
+	return jars
+}
+
+// Jobs returns all jobs of the cluster in the order they were started
+func (c *Cluster) Jobs() []Job {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.progress()
+	jobs := []Job{}
+	for _, job := range c.jobs {
+		jobs = append(jobs, *job)
+	}
+	return jobs
+}
+
+// Job returns the job specified by ID
+func (c *Cluster) Job(jobID string) (Job, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.progress()
+	job := c.findJob(jobID)
+	if job == nil {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// Savepoints returns the locations of the completed savepoints
+func (c *Cluster) Savepoints() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.progress()
+	locations := []string{}
+	for _, s := range c.savepoints {
+		if len(s.location) > 0 {
+			locations = append(locations, s.location)
+		}
+	}
+	return locations
+}
+
+// Requests returns the method and path of every request
+// the cluster received, e.g. "GET /jobs/overview"
+func (c *Cluster) Requests() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]string{}, c.requests...)
+}
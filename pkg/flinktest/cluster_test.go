@@ -0,0 +1,450 @@
+package flinktest
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func constructTestClient(server *httptest.Server) *flink.VersionedFlinkRestClient {
+	client := retryablehttp.NewClient()
+	client.RetryMax = 1
+	client.RetryWaitMin = time.Millisecond
+	client.RetryWaitMax = time.Millisecond
+	client.Logger = nil
+	return flink.NewVersionedFlinkRestClient(flink.FlinkRestClient{
+		BaseURL: server.URL,
+		Client:  client,
+	})
+}
+
+func uploadTestJar(t *testing.T, api *flink.VersionedFlinkRestClient, name string) string {
+	dir, err := ioutil.TempDir("", "flinktest")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := dir + "/" + name
+	err = ioutil.WriteFile(filename, []byte("jar"), 0644)
+	assert.Nil(t, err)
+
+	res, err := api.UploadJar(context.Background(), filename)
+	assert.Nil(t, err)
+	return res.Filename[len(uploadDir)+1:]
+}
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+/*
+ * JAR files and jobs
+ */
+func TestRunJarShouldStartAJobNamedAfterTheJarFile(t *testing.T) {
+	cluster := NewCluster()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	jarID := uploadTestJar(t, api, "WordCount-1.0.jar")
+	res, err := api.RunJar(context.Background(), jarID, "com.example.WordCount", []string{"--input", "a b"}, 2, "", false, flink.RunJarOptions{})
+
+	assert.Nil(t, err)
+	jobs, err := api.RetrieveJobs(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, res.JobID, jobs[0].ID)
+	assert.Equal(t, "WordCount-1.0", jobs[0].Name)
+	assert.Equal(t, "RUNNING", jobs[0].Status)
+
+	job, ok := cluster.Job(res.JobID)
+	assert.True(t, ok)
+	assert.Equal(t, jarID, job.JarID)
+	assert.Equal(t, "com.example.WordCount", job.EntryClass)
+	assert.Equal(t, []string{"--input", "a b"}, job.ProgramArgs)
+	assert.Equal(t, 2, job.Parallelism)
+}
+
+func TestRunJarShouldReturnAnErrorForAnUnknownJar(t *testing.T) {
+	server := httptest.NewServer(NewCluster())
+	defer server.Close()
+	api := constructTestClient(server)
+
+	_, err := api.RunJar(context.Background(), "unknown.jar", "", nil, 1, "", false, flink.RunJarOptions{})
+
+	assert.Contains(t, err.Error(), "Jar file /tmp/flink-web-upload/unknown.jar does not exist")
+}
+
+func TestRunJarShouldReturnAnErrorForAMissingSavepoint(t *testing.T) {
+	cluster := NewCluster()
+	cluster.Filesystem = afero.NewMemMapFs()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	jarID := uploadTestJar(t, api, "WordCount.jar")
+	_, err := api.RunJar(context.Background(), jarID, "", nil, 1, "/savepoints/savepoint-missing", false, flink.RunJarOptions{})
+
+	assert.NotNil(t, err)
+	assert.Len(t, cluster.Jobs(), 0)
+}
+
+func TestRunJarShouldStartAJobWithTheOptions(t *testing.T) {
+	cluster := NewCluster()
+	cluster.Version = "1.18.1"
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	jarID := uploadTestJar(t, api, "WordCount.jar")
+	res, err := api.RunJar(context.Background(), jarID, "", nil, 1, "", false, flink.RunJarOptions{
+		JobID:              "a8c2a1e0a2b94e5f8a6b7c3d4e5f6a7b",
+		RestoreMode:        flink.RestoreModeNoClaim,
+		FlinkConfiguration: map[string]string{"pipeline.name": "word-count"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "a8c2a1e0a2b94e5f8a6b7c3d4e5f6a7b", res.JobID)
+	job, ok := cluster.Job(res.JobID)
+	assert.True(t, ok)
+	assert.Equal(t, "NO_CLAIM", job.RestoreMode)
+	assert.Equal(t, map[string]string{"pipeline.name": "word-count"}, job.FlinkConfiguration)
+}
+
+func TestRunJarShouldReturnAnErrorForADuplicateJobID(t *testing.T) {
+	cluster := NewCluster()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	jarID := uploadTestJar(t, api, "WordCount.jar")
+	options := flink.RunJarOptions{JobID: "a8c2a1e0a2b94e5f8a6b7c3d4e5f6a7b"}
+	_, err := api.RunJar(context.Background(), jarID, "", nil, 1, "", false, options)
+	assert.Nil(t, err)
+	_, err = api.RunJar(context.Background(), jarID, "", nil, 1, "", false, options)
+
+	assert.Contains(t, err.Error(), "Job has already been submitted")
+	assert.Len(t, cluster.Jobs(), 1)
+}
+
+func TestDeleteJarShouldRemoveTheJar(t *testing.T) {
+	cluster := NewCluster()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	jarID := uploadTestJar(t, api, "WordCount.jar")
+	err := api.DeleteJar(context.Background(), jarID)
+
+	assert.Nil(t, err)
+	assert.Len(t, cluster.Jars(), 0)
+}
+
+func TestRetrieveClusterOverviewShouldReportTheSlotsUsedByRunningJobs(t *testing.T) {
+	cluster := NewCluster()
+	cluster.TaskManagers = 2
+	cluster.StartJob("WordCount", 3)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	overview, err := api.RetrieveClusterOverview(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 8, overview.SlotsTotal)
+	assert.Equal(t, 5, overview.SlotsAvailable)
+	assert.Equal(t, 1, overview.JobsRunning)
+
+	taskManagers, err := api.RetrieveTaskManagers(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, taskManagers, 2)
+	assert.Equal(t, 1, taskManagers[0].FreeSlots)
+	assert.Equal(t, 4, taskManagers[1].FreeSlots)
+}
+
+func TestFailJobShouldReportTheException(t *testing.T) {
+	cluster := NewCluster()
+	jobID := cluster.StartJob("WordCount", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	err := cluster.FailJob(jobID, "java.lang.OutOfMemoryError")
+	assert.Nil(t, err)
+
+	exceptions, err := api.RetrieveJobExceptions(context.Background(), jobID)
+	assert.Nil(t, err)
+	assert.Equal(t, "java.lang.OutOfMemoryError", exceptions.RootException)
+	assert.Len(t, exceptions.AllExceptions, 1)
+	job, _ := cluster.Job(jobID)
+	assert.Equal(t, StatusFailed, job.Status)
+}
+
+/*
+ * Terminate
+ */
+func TestTerminateShouldCancelTheJob(t *testing.T) {
+	cluster := NewCluster()
+	jobID := cluster.StartJob("WordCount", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	err := api.Terminate(context.Background(), jobID, "cancel")
+
+	assert.Nil(t, err)
+	job, _ := cluster.Job(jobID)
+	assert.Equal(t, StatusCanceled, job.Status)
+}
+
+func TestTerminateShouldReturnAConflictForAJobWhichIsNotRunning(t *testing.T) {
+	cluster := NewCluster()
+	jobID := cluster.StartJob("WordCount", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	api.Terminate(context.Background(), jobID, "cancel")
+	err := api.Terminate(context.Background(), jobID, "cancel")
+
+	assert.Equal(t, flink.ErrConflict, flink.Category(err))
+}
+
+func TestTerminateShouldStopTheJobWithASavepoint(t *testing.T) {
+	cluster := NewCluster()
+	cluster.DefaultSavepointDir = "/savepoints"
+	jobID := cluster.StartJob("WordCount", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	err := api.Terminate(context.Background(), jobID, "stop")
+
+	assert.Nil(t, err)
+	job, _ := cluster.Job(jobID)
+	assert.Equal(t, StatusFinished, job.Status)
+	assert.Len(t, cluster.Savepoints(), 1)
+}
+
+/*
+ * Savepoints
+ */
+func TestSavepointShouldCompleteAfterTheSavepointDuration(t *testing.T) {
+	clock := &testClock{now: time.Unix(1500000000, 0)}
+	cluster := NewCluster()
+	cluster.now = clock.Now
+	cluster.SavepointDuration = 10 * time.Second
+	cluster.Filesystem = afero.NewMemMapFs()
+	jobID := cluster.StartJob("WordCount", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	res, err := api.CreateSavepoint(context.Background(), jobID, "/savepoints")
+	assert.Nil(t, err)
+
+	status, err := api.MonitorSavepointCreation(context.Background(), jobID, res.RequestID)
+	assert.Nil(t, err)
+	assert.Equal(t, "IN_PROGRESS", status.Status.Id)
+
+	clock.now = clock.now.Add(10 * time.Second)
+	status, err = api.MonitorSavepointCreation(context.Background(), jobID, res.RequestID)
+	assert.Nil(t, err)
+	assert.Equal(t, "COMPLETED", status.Status.Id)
+	assert.Regexp(t, "^/savepoints/savepoint-"+jobID[:6]+"-[0-9a-f]{12}$", status.Operation.Location)
+
+	exists, err := afero.Exists(cluster.Filesystem, status.Operation.Location+"/_metadata")
+	assert.Nil(t, err)
+	assert.True(t, exists)
+}
+
+func TestSavepointShouldReportTheInjectedFailureCause(t *testing.T) {
+	cluster := NewCluster()
+	cluster.FailSavepoints("java.io.IOException: disk full")
+	jobID := cluster.StartJob("WordCount", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	res, err := api.CreateSavepoint(context.Background(), jobID, "/savepoints")
+	assert.Nil(t, err)
+	status, err := api.MonitorSavepointCreation(context.Background(), jobID, res.RequestID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "COMPLETED", status.Status.Id)
+	assert.Equal(t, "java.io.IOException: disk full", status.Operation.FailureCause.StackTrace)
+	assert.Len(t, cluster.Savepoints(), 0)
+}
+
+func TestSavepointShouldReturnTheTriggerIDOnFlink116(t *testing.T) {
+	cluster := NewCluster()
+	cluster.Version = "1.16.0"
+	jobID := cluster.StartJob("WordCount", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	res, err := api.CreateSavepoint(context.Background(), jobID, "/savepoints")
+
+	assert.Nil(t, err)
+	assert.Regexp(t, "^[0-9a-f]{32}$", res.RequestID)
+	status, err := api.MonitorSavepointCreation(context.Background(), jobID, res.RequestID)
+	assert.Nil(t, err)
+	assert.Equal(t, "COMPLETED", status.Status.Id)
+}
+
+func TestMonitorSavepointCreationShouldReturnNotFoundForAnUnknownTriggerID(t *testing.T) {
+	cluster := NewCluster()
+	jobID := cluster.StartJob("WordCount", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	_, err := api.MonitorSavepointCreation(context.Background(), jobID, "unknown")
+
+	assert.Equal(t, flink.ErrNotFound, flink.Category(err))
+}
+
+/*
+ * Checkpoints
+ */
+func TestRetrieveCheckpointsShouldCountTheCheckpointsOfTheInterval(t *testing.T) {
+	clock := &testClock{now: time.Unix(1500000000, 0)}
+	cluster := NewCluster()
+	cluster.now = clock.Now
+	cluster.CheckpointInterval = time.Minute
+	jobID := cluster.StartJob("WordCount", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	clock.now = clock.now.Add(150 * time.Second)
+	checkpoints, err := api.RetrieveCheckpoints(context.Background(), jobID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, checkpoints.Counts.Completed)
+	assert.Equal(t, "COMPLETED", checkpoints.Latest.Completed.Status)
+	assert.Equal(t, int64(checkpointDuration), checkpoints.Summary.EndToEndDuration.Avg)
+	assert.Nil(t, checkpoints.Latest.Savepoint)
+}
+
+/*
+ * Rescaling
+ */
+func TestRescaleShouldChangeTheParallelismBeforeFlink19(t *testing.T) {
+	cluster := NewCluster()
+	cluster.Version = "1.7.2"
+	jobID := cluster.StartJob("WordCount", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	res, err := api.Rescale(context.Background(), jobID, 3)
+	assert.Nil(t, err)
+	status, err := api.MonitorRescaling(context.Background(), jobID, res.RequestID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "COMPLETED", status.Status.Id)
+	job, _ := cluster.Job(jobID)
+	assert.Equal(t, 3, job.Parallelism)
+}
+
+func TestRescaleShouldBeDisabledOnFlink19(t *testing.T) {
+	cluster := NewCluster()
+	jobID := cluster.StartJob("WordCount", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+
+	client := retryablehttp.NewClient()
+	client.CheckRetry = func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		return false, err
+	}
+
+	_, err := flink.FlinkRestClient{BaseURL: server.URL, Client: client}.Rescale(context.Background(), jobID, 3)
+
+	assert.Equal(t, flink.ErrRescalingUnsupported, err)
+}
+
+/*
+ * Injections
+ */
+func TestInjectFailureShouldFailTheMatchingRequestsTheNumberOfTimes(t *testing.T) {
+	cluster := NewCluster()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	cluster.InjectFailure(Failure{Method: "GET", Path: "/jobs/overview", StatusCode: 401, Times: 1})
+
+	_, err := api.RetrieveJobs(context.Background())
+	assert.Equal(t, flink.ErrUnauthorized, flink.Category(err))
+
+	_, err = api.RetrieveJobs(context.Background())
+	assert.Nil(t, err)
+}
+
+func TestInjectFailureShouldMatchThePathPattern(t *testing.T) {
+	cluster := NewCluster()
+	jobID := cluster.StartJob("WordCount", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	cluster.InjectFailure(Failure{Path: "/jobs/*/savepoints", StatusCode: 409, Message: "savepoint in progress"})
+
+	_, err := api.CreateSavepoint(context.Background(), jobID, "/savepoints")
+	assert.Contains(t, err.Error(), "savepoint in progress")
+	_, err = api.RetrieveJobs(context.Background())
+	assert.Nil(t, err)
+
+	cluster.ClearInjections()
+	_, err = api.CreateSavepoint(context.Background(), jobID, "/savepoints")
+	assert.Nil(t, err)
+}
+
+func TestInjectFailureShouldDropTheConnection(t *testing.T) {
+	cluster := NewCluster()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	cluster.InjectFailure(Failure{Path: "/jobs/overview", Drop: true})
+
+	_, err := api.RetrieveJobs(context.Background())
+	assert.Equal(t, flink.ErrClusterUnreachable, flink.Category(err))
+	assert.Len(t, cluster.Requests(), 2)
+}
+
+func TestInjectLatencyShouldDelayTheResponse(t *testing.T) {
+	cluster := NewCluster()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	api := constructTestClient(server)
+
+	cluster.InjectLatency(Latency{Path: "/jobs/overview", Delay: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := api.RetrieveJobs(ctx)
+	assert.Equal(t, flink.ErrTimeout, flink.Category(err))
+}
+
+func TestServeHTTPShouldRespondWithNotFoundForAnUnknownPath(t *testing.T) {
+	server := httptest.NewServer(NewCluster())
+	defer server.Close()
+
+	_, err := flink.FlinkRestClient{BaseURL: server.URL + "/unknown", Client: retryablehttp.NewClient()}.RetrieveJobs(context.Background())
+
+	assert.Equal(t, flink.ErrNotFound, flink.Category(err))
+}
@@ -0,0 +1,733 @@
+package flinktest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// vertexID is the ID of the single vertex of every job
+const vertexID = "cbc357ccb763df2852fee8c4fc7d55f2"
+
+// checkpointDuration is the end to end duration of every checkpoint in milliseconds
+const checkpointDuration = 120
+
+// uploadDir is the directory the JAR files are reported to be uploaded to
+const uploadDir = "/tmp/flink-web-upload"
+
+type object map[string]interface{}
+
+// A route maps the requests with the method and a path
+// matching the pattern, in which * matches a single segment,
+// to the handler. The matched segments are the parameters.
+type route struct {
+	method  string
+	pattern string
+	handle  func(c *Cluster, w http.ResponseWriter, r *http.Request, params []string)
+}
+
+var routes = []route{
+	{"GET", "/config", (*Cluster).getConfig},
+	{"GET", "/overview", (*Cluster).getOverview},
+	{"GET", "/taskmanagers", (*Cluster).getTaskManagers},
+	{"GET", "/taskmanagers/*/log", (*Cluster).getTaskManagerLog},
+	{"GET", "/jobmanager/log", (*Cluster).getJobManagerLog},
+	{"GET", "/jars", (*Cluster).getJars},
+	{"POST", "/jars/upload", (*Cluster).uploadJar},
+	{"DELETE", "/jars/*", (*Cluster).deleteJar},
+	{"POST", "/jars/*/run", (*Cluster).runJar},
+	{"GET", "/jars/*/plan", (*Cluster).getJarPlan},
+	{"POST", "/jars/*/plan", (*Cluster).getJarPlan},
+	{"GET", "/jobs/overview", (*Cluster).getJobsOverview},
+	{"GET", "/jobs/*", (*Cluster).getJob},
+	{"PATCH", "/jobs/*", (*Cluster).terminateJob},
+	{"GET", "/jobs/*/plan", (*Cluster).getJobPlan},
+	{"GET", "/jobs/*/exceptions", (*Cluster).getJobExceptions},
+	{"GET", "/jobs/*/checkpoints", (*Cluster).getCheckpoints},
+	{"GET", "/jobs/*/vertices/*/backpressure", (*Cluster).getBackPressure},
+	{"POST", "/jobs/*/savepoints", (*Cluster).triggerSavepoint},
+	{"GET", "/jobs/*/savepoints/*", (*Cluster).getSavepointStatus},
+	{"POST", "/jobs/*/stop", (*Cluster).stopJob},
+	{"PATCH", "/jobs/*/rescaling", (*Cluster).rescaleJob},
+	{"GET", "/jobs/*/rescaling/*", (*Cluster).getRescalingStatus},
+}
+
+// match returns the segments of the path matching the wildcards of the pattern
+func match(pattern string, path string) ([]string, bool) {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(patternSegments) != len(pathSegments) {
+		return nil, false
+	}
+
+	params := []string{}
+	for i, segment := range patternSegments {
+		if segment == "*" {
+			params = append(params, pathSegments[i])
+		} else if segment != pathSegments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// ServeHTTP handles a request to the Flink REST API
+func (c *Cluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mutex.Lock()
+	c.requests = append(c.requests, fmt.Sprintf("%v %v", r.Method, r.URL.Path))
+	delay := c.delayFor(r)
+	failure, failed := c.failureFor(r)
+	c.mutex.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if failed {
+		injectFailure(w, failure)
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.progress()
+
+	methodAllowed := true
+	for _, route := range routes {
+		params, ok := match(route.pattern, r.URL.Path)
+		if !ok {
+			continue
+		}
+		if route.method != r.Method {
+			methodAllowed = false
+			continue
+		}
+		route.handle(c, w, r, params)
+		return
+	}
+
+	if !methodAllowed {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("Request method %v is not allowed for %v.", r.Method, r.URL.Path))
+		return
+	}
+	writeError(w, http.StatusNotFound, "Not found.")
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, messages ...string) {
+	writeJSON(w, statusCode, object{"errors": messages})
+}
+
+// decodeBody decodes the JSON body of the request, which may be empty
+func decodeBody(r *http.Request, v interface{}) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+func millis(t time.Time) int64 {
+	if t.IsZero() {
+		return -1
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// runningJob returns the job specified by ID, or responds with an error
+// when the job doesn't exist or, when required, isn't running
+func (c *Cluster) runningJob(w http.ResponseWriter, jobID string, required bool) *Job {
+	job := c.findJob(jobID)
+	if job == nil {
+		writeError(w, http.StatusNotFound, "Job could not be found.")
+		return nil
+	}
+	if required && job.Status != StatusRunning {
+		writeError(w, http.StatusConflict, fmt.Sprintf("Job %v is not running but %v.", job.ID, job.Status))
+		return nil
+	}
+	return job
+}
+
+/*
+ * Cluster
+ */
+func (c *Cluster) getConfig(w http.ResponseWriter, r *http.Request, params []string) {
+	writeJSON(w, http.StatusOK, object{
+		"refresh-interval": 3000,
+		"timezone-name":    "Coordinated Universal Time",
+		"timezone-offset":  0,
+		"flink-version":    c.Version,
+		"flink-revision":   "flinktest",
+	})
+}
+
+func (c *Cluster) getOverview(w http.ResponseWriter, r *http.Request, params []string) {
+	counts := map[string]int{}
+	for _, job := range c.jobs {
+		counts[job.Status]++
+	}
+
+	available := c.slotsTotal() - c.slotsUsed()
+	if available < 0 {
+		available = 0
+	}
+	writeJSON(w, http.StatusOK, object{
+		"taskmanagers":    c.TaskManagers,
+		"slots-total":     c.slotsTotal(),
+		"slots-available": available,
+		"jobs-running":    counts[StatusRunning],
+		"jobs-finished":   counts[StatusFinished],
+		"jobs-cancelled":  counts[StatusCanceled],
+		"jobs-failed":     counts[StatusFailed],
+		"flink-version":   c.Version,
+		"flink-commit":    "flinktest",
+	})
+}
+
+func (c *Cluster) getTaskManagers(w http.ResponseWriter, r *http.Request, params []string) {
+	used := c.slotsUsed()
+	taskManagers := []object{}
+	for i := 0; i < c.TaskManagers; i++ {
+		usedByTaskManager := used
+		if usedByTaskManager > c.SlotsPerTaskManager {
+			usedByTaskManager = c.SlotsPerTaskManager
+		}
+		used -= usedByTaskManager
+
+		taskManagers = append(taskManagers, object{
+			"id":          fmt.Sprintf("taskmanager-%v", i+1),
+			"path":        fmt.Sprintf("akka.tcp://flink@taskmanager-%v:6122/user/taskmanager_0", i+1),
+			"slotsNumber": c.SlotsPerTaskManager,
+			"freeSlots":   c.SlotsPerTaskManager - usedByTaskManager,
+		})
+	}
+	writeJSON(w, http.StatusOK, object{"taskmanagers": taskManagers})
+}
+
+func (c *Cluster) getTaskManagerLog(w http.ResponseWriter, r *http.Request, params []string) {
+	var index int
+	_, err := fmt.Sscanf(params[0], "taskmanager-%d", &index)
+	if err != nil || index < 1 || index > c.TaskManagers {
+		writeError(w, http.StatusNotFound, "TaskManager id "+params[0]+" does not exist.")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	fmt.Fprintf(w, "%v INFO Starting TaskManager %v with %v task slots\n", c.now().UTC().Format("2006-01-02 15:04:05,000"), params[0], c.SlotsPerTaskManager)
+}
+
+func (c *Cluster) getJobManagerLog(w http.ResponseWriter, r *http.Request, params []string) {
+	w.Header().Set("Content-Type", "text/plain")
+	for _, line := range c.logLines {
+		fmt.Fprintln(w, line)
+	}
+}
+
+/*
+ * JAR files
+ */
+func (c *Cluster) getJars(w http.ResponseWriter, r *http.Request, params []string) {
+	files := []object{}
+	for _, jar := range c.jars {
+		files = append(files, object{
+			"id":       jar.ID,
+			"name":     jar.Name,
+			"uploaded": millis(jar.Uploaded),
+			"entry":    []object{},
+		})
+	}
+	writeJSON(w, http.StatusOK, object{"address": "http://" + r.Host, "files": files})
+}
+
+func (c *Cluster) uploadJar(w http.ResponseWriter, r *http.Request, params []string) {
+	file, header, err := r.FormFile("jarfile")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Exactly 1 file must be sent, received 0.")
+		return
+	}
+	defer file.Close()
+
+	if !strings.HasSuffix(header.Filename, ".jar") {
+		writeError(w, http.StatusBadRequest, "Only Jar files are allowed.")
+		return
+	}
+
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	jar := &Jar{
+		ID:       newID() + "_" + header.Filename,
+		Name:     header.Filename,
+		Content:  content,
+		Uploaded: c.now(),
+	}
+	c.jars = append(c.jars, jar)
+	c.logf("Uploaded JAR file %v as %v", jar.Name, jar.ID)
+
+	writeJSON(w, http.StatusOK, object{
+		"filename": uploadDir + "/" + jar.ID,
+		"status":   "success",
+	})
+}
+
+func (c *Cluster) deleteJar(w http.ResponseWriter, r *http.Request, params []string) {
+	for i, jar := range c.jars {
+		if jar.ID == params[0] {
+			c.jars = append(c.jars[:i], c.jars[i+1:]...)
+			c.logf("Deleted JAR file %v", jar.ID)
+			writeJSON(w, http.StatusOK, object{})
+			return
+		}
+	}
+	writeError(w, http.StatusBadRequest, fmt.Sprintf("File %v does not exist in %v.", params[0], uploadDir))
+}
+
+// programArgsPattern matches the program arguments in the programArgs
+// string, like the tokenizer of the JarRunHandler of Flink
+var programArgsPattern = regexp.MustCompile(`([^"'\s]\S*|".+?"|'.+?')\s*`)
+
+// tokenizeProgramArgs splits the programArgs string into the program
+// arguments and removes the quotes, like Flink does
+func tokenizeProgramArgs(programArgs string) []string {
+	args := []string{}
+	for _, match := range programArgsPattern.FindAllString(programArgs, -1) {
+		args = append(args, strings.NewReplacer(`"`, "", "'", "").Replace(strings.TrimSpace(match)))
+	}
+	return args
+}
+
+type runJarRequest struct {
+	EntryClass            string            `json:"entryClass"`
+	ProgramArgs           string            `json:"programArgs"`
+	ProgramArgsList       []string          `json:"programArgsList"`
+	Parallelism           int               `json:"parallelism"`
+	AllowNonRestoredState bool              `json:"allowNonRestoredState"`
+	SavepointPath         string            `json:"savepointPath"`
+	JobID                 string            `json:"jobId"`
+	RestoreMode           string            `json:"restoreMode"`
+	FlinkConfiguration    map[string]string `json:"flinkConfiguration"`
+}
+
+func (c *Cluster) runJar(w http.ResponseWriter, r *http.Request, params []string) {
+	jar := c.findJar(params[0])
+	if jar == nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Jar file %v/%v does not exist", uploadDir, params[0]))
+		return
+	}
+
+	request := runJarRequest{}
+	err := decodeBody(r, &request)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Request did not match expected format RunJarRequestBody.")
+		return
+	}
+
+	programArgs := request.ProgramArgsList
+	if programArgs == nil {
+		programArgs = tokenizeProgramArgs(request.ProgramArgs)
+	}
+
+	// Like Flink, ignore the fields the version doesn't know
+	version, _ := parseVersion(c.Version)
+	if version < 109 {
+		request.JobID = ""
+	}
+	if version < 115 {
+		request.RestoreMode = ""
+	}
+	if version < 117 {
+		request.FlinkConfiguration = nil
+	}
+	if len(request.JobID) > 0 && c.findJob(request.JobID) != nil {
+		writeError(w, http.StatusBadRequest, "Internal server error.", fmt.Sprintf("org.apache.flink.runtime.client.DuplicateJobSubmissionException: Job has already been submitted. JobID: %v", request.JobID))
+		return
+	}
+
+	if len(request.SavepointPath) > 0 && c.Filesystem != nil {
+		_, err := c.Filesystem.Stat(strings.TrimPrefix(request.SavepointPath, "file://"))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Internal server error.", fmt.Sprintf("java.io.FileNotFoundException: Cannot find checkpoint or savepoint file/directory '%v'", request.SavepointPath))
+			return
+		}
+	}
+
+	job := c.startJob(&Job{
+		ID:                    request.JobID,
+		Name:                  strings.TrimSuffix(jar.Name, ".jar"),
+		JarID:                 jar.ID,
+		EntryClass:            request.EntryClass,
+		ProgramArgs:           programArgs,
+		Parallelism:           request.Parallelism,
+		SavepointPath:         request.SavepointPath,
+		AllowNonRestoredState: request.AllowNonRestoredState,
+		RestoreMode:           request.RestoreMode,
+		FlinkConfiguration:    request.FlinkConfiguration,
+	})
+	writeJSON(w, http.StatusOK, object{"jobid": job.ID})
+}
+
+func (c *Cluster) getJarPlan(w http.ResponseWriter, r *http.Request, params []string) {
+	jar := c.findJar(params[0])
+	if jar == nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Jar file %v/%v does not exist", uploadDir, params[0]))
+		return
+	}
+
+	parallelism := 1
+	if value := r.URL.Query().Get("parallelism"); len(value) > 0 {
+		parallelism, _ = strconv.Atoi(value)
+	}
+	writeJSON(w, http.StatusOK, object{"plan": plan("", strings.TrimSuffix(jar.Name, ".jar"), parallelism)})
+}
+
+func plan(jobID string, name string, parallelism int) object {
+	return object{
+		"jid":  jobID,
+		"name": name,
+		"nodes": []object{{
+			"id":                vertexID,
+			"parallelism":       parallelism,
+			"operator":          "",
+			"operator_strategy": "",
+			"description":       "Source: Custom Source -&gt; Map -&gt; Sink: Unnamed",
+		}},
+	}
+}
+
+/*
+ * Jobs
+ */
+func (c *Cluster) getJobsOverview(w http.ResponseWriter, r *http.Request, params []string) {
+	jobs := []object{}
+	for _, job := range c.jobs {
+		jobs = append(jobs, object{
+			"jid":        job.ID,
+			"name":       job.Name,
+			"state":      job.Status,
+			"start-time": millis(job.StartTime),
+			"end-time":   millis(job.EndTime),
+		})
+	}
+	writeJSON(w, http.StatusOK, object{"jobs": jobs})
+}
+
+func (c *Cluster) getJob(w http.ResponseWriter, r *http.Request, params []string) {
+	job := c.runningJob(w, params[0], false)
+	if job == nil {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, object{
+		"jid":        job.ID,
+		"name":       job.Name,
+		"state":      job.Status,
+		"start-time": millis(job.StartTime),
+		"end-time":   millis(job.EndTime),
+		"vertices": []object{{
+			"id":          vertexID,
+			"name":        "Source: Custom Source -> Map -> Sink: Unnamed",
+			"parallelism": job.Parallelism,
+			"status":      job.Status,
+		}},
+	})
+}
+
+func (c *Cluster) terminateJob(w http.ResponseWriter, r *http.Request, params []string) {
+	job := c.runningJob(w, params[0], true)
+	if job == nil {
+		return
+	}
+
+	switch r.URL.Query().Get("mode") {
+	case "", "cancel":
+		c.endJob(job, StatusCanceled)
+	case "stop":
+		c.endJob(job, StatusFinished)
+	default:
+		writeError(w, http.StatusBadRequest, "Bad request, could not parse parameters: mode")
+		return
+	}
+	writeJSON(w, http.StatusAccepted, object{})
+}
+
+func (c *Cluster) getJobPlan(w http.ResponseWriter, r *http.Request, params []string) {
+	job := c.runningJob(w, params[0], false)
+	if job == nil {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, object{"plan": plan(job.ID, job.Name, job.Parallelism)})
+}
+
+func (c *Cluster) getJobExceptions(w http.ResponseWriter, r *http.Request, params []string) {
+	job := c.runningJob(w, params[0], false)
+	if job == nil {
+		return
+	}
+
+	if len(job.Exception) == 0 {
+		writeJSON(w, http.StatusOK, object{"root-exception": nil, "timestamp": nil, "all-exceptions": []object{}, "truncated": false})
+		return
+	}
+	writeJSON(w, http.StatusOK, object{
+		"root-exception": job.Exception,
+		"timestamp":      millis(job.EndTime),
+		"all-exceptions": []object{{
+			"exception": job.Exception,
+			"task":      "Source: Custom Source -> Map -> Sink: Unnamed (1/1)",
+			"location":  "taskmanager-1:6122",
+			"timestamp": millis(job.EndTime),
+		}},
+		"truncated": false,
+	})
+}
+
+func (c *Cluster) getCheckpoints(w http.ResponseWriter, r *http.Request, params []string) {
+	job := c.runningJob(w, params[0], false)
+	if job == nil {
+		return
+	}
+
+	checkpoints := 0
+	if c.CheckpointInterval > 0 {
+		end := c.now()
+		if job.Status != StatusRunning {
+			end = job.EndTime
+		}
+		checkpoints = int(end.Sub(job.StartTime) / c.CheckpointInterval)
+	}
+
+	restored := 0
+	if len(job.SavepointPath) > 0 {
+		restored = 1
+	}
+
+	latest := object{"completed": nil, "savepoint": nil, "failed": nil, "restored": nil}
+	duration := object{"min": 0, "max": 0, "avg": 0}
+	if checkpoints > 0 {
+		triggered := job.StartTime.Add(time.Duration(checkpoints) * c.CheckpointInterval)
+		latest["completed"] = object{
+			"id":                   checkpoints + job.savepoints,
+			"status":               "COMPLETED",
+			"is_savepoint":         false,
+			"trigger_timestamp":    millis(triggered),
+			"latest_ack_timestamp": millis(triggered) + checkpointDuration,
+			"end_to_end_duration":  checkpointDuration,
+			"external_path":        "<checkpoint-not-externally-addressable>",
+		}
+		duration = object{"min": checkpointDuration, "max": checkpointDuration, "avg": checkpointDuration}
+	}
+	if job.lastSavepoint != nil {
+		latest["savepoint"] = object{
+			"id":                   checkpoints + job.savepoints,
+			"status":               "COMPLETED",
+			"is_savepoint":         true,
+			"trigger_timestamp":    millis(job.lastSavepoint.triggered),
+			"latest_ack_timestamp": millis(job.lastSavepoint.triggered) + checkpointDuration,
+			"end_to_end_duration":  checkpointDuration,
+			"external_path":        job.lastSavepoint.location,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, object{
+		"counts": object{
+			"restored":    restored,
+			"total":       checkpoints + job.savepoints,
+			"in_progress": 0,
+			"completed":   checkpoints + job.savepoints,
+			"failed":      0,
+		},
+		"summary": object{"end_to_end_duration": duration},
+		"latest":  latest,
+	})
+}
+
+func (c *Cluster) getBackPressure(w http.ResponseWriter, r *http.Request, params []string) {
+	job := c.runningJob(w, params[0], false)
+	if job == nil {
+		return
+	}
+	if params[1] != vertexID {
+		writeError(w, http.StatusNotFound, "JobVertexId "+params[1]+" not found.")
+		return
+	}
+
+	subtasks := []object{}
+	for i := 0; i < job.Parallelism; i++ {
+		subtasks = append(subtasks, object{"subtask": i, "backpressure-level": "ok", "ratio": 0})
+	}
+	writeJSON(w, http.StatusOK, object{
+		"status":             "ok",
+		"backpressure-level": "ok",
+		"end-timestamp":      millis(c.now()),
+		"subtasks":           subtasks,
+	})
+}
+
+/*
+ * Savepoints
+ */
+type triggerSavepointRequest struct {
+	TargetDirectory string `json:"target-directory"`
+	CancelJob       bool   `json:"cancel-job"`
+	TriggerID       string `json:"triggerId"`
+}
+
+type stopJobRequest struct {
+	TargetDirectory string `json:"targetDirectory"`
+	Drain           bool   `json:"drain"`
+}
+
+func (c *Cluster) triggerSavepoint(w http.ResponseWriter, r *http.Request, params []string) {
+	request := triggerSavepointRequest{}
+	err := decodeBody(r, &request)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Request did not match expected format SavepointTriggerRequestBody.")
+		return
+	}
+
+	terminateStatus := ""
+	if request.CancelJob {
+		terminateStatus = StatusCanceled
+	}
+	c.createSavepoint(w, params[0], request.TargetDirectory, request.TriggerID, terminateStatus)
+}
+
+func (c *Cluster) stopJob(w http.ResponseWriter, r *http.Request, params []string) {
+	request := stopJobRequest{}
+	err := decodeBody(r, &request)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Request did not match expected format StopWithSavepointRequestBody.")
+		return
+	}
+
+	c.createSavepoint(w, params[0], request.TargetDirectory, "", StatusFinished)
+}
+
+func (c *Cluster) createSavepoint(w http.ResponseWriter, jobID string, targetDirectory string, triggerID string, terminateStatus string) {
+	job := c.runningJob(w, jobID, false)
+	if job == nil {
+		return
+	}
+
+	if len(triggerID) > 0 && c.findSavepoint(job.ID, triggerID) != nil {
+		writeJSON(w, http.StatusAccepted, object{"request-id": triggerID})
+		return
+	}
+
+	if job.Status != StatusRunning {
+		writeError(w, http.StatusConflict, fmt.Sprintf("Job %v is not running but %v.", job.ID, job.Status))
+		return
+	}
+	if len(targetDirectory) == 0 {
+		targetDirectory = c.DefaultSavepointDir
+	}
+	if len(targetDirectory) == 0 {
+		writeError(w, http.StatusBadRequest, "Config key [state.savepoints.dir] is not set. Property [target-directory] must be provided.")
+		return
+	}
+	if len(triggerID) == 0 {
+		triggerID = newID()
+	}
+
+	c.savepoints = append(c.savepoints, &savepoint{
+		jobID:           job.ID,
+		triggerID:       triggerID,
+		targetDirectory: targetDirectory,
+		terminateStatus: terminateStatus,
+		triggered:       c.now(),
+	})
+	c.logf("Triggering savepoint for job %v (%v) in %v", job.Name, job.ID, targetDirectory)
+
+	writeJSON(w, http.StatusAccepted, object{"request-id": triggerID})
+}
+
+func (c *Cluster) getSavepointStatus(w http.ResponseWriter, r *http.Request, params []string) {
+	s := c.findSavepoint(params[0], params[1])
+	if s == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Operation not found under key: %v/%v", params[0], params[1]))
+		return
+	}
+
+	if !s.completed {
+		writeJSON(w, http.StatusOK, object{"status": object{"id": "IN_PROGRESS"}, "operation": nil})
+		return
+	}
+	if len(s.failure) > 0 {
+		writeJSON(w, http.StatusOK, object{
+			"status": object{"id": "COMPLETED"},
+			"operation": object{"failure-cause": object{
+				"class":       "java.util.concurrent.CompletionException",
+				"stack-trace": s.failure,
+			}},
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, object{
+		"status":    object{"id": "COMPLETED"},
+		"operation": object{"location": s.location},
+	})
+}
+
+/*
+ * Rescaling
+ */
+func (c *Cluster) rescaleJob(w http.ResponseWriter, r *http.Request, params []string) {
+	version, _ := parseVersion(c.Version)
+	if version >= 109 {
+		writeError(w, http.StatusServiceUnavailable, "Rescaling is temporarily disabled. See FLINK-12312.")
+		return
+	}
+
+	job := c.runningJob(w, params[0], true)
+	if job == nil {
+		return
+	}
+
+	parallelism, err := strconv.Atoi(r.URL.Query().Get("parallelism"))
+	if err != nil || parallelism <= 0 {
+		writeError(w, http.StatusBadRequest, "Bad request, could not parse parameters: parallelism")
+		return
+	}
+
+	c.logf("Rescaling job %v (%v) from parallelism %v to %v", job.Name, job.ID, job.Parallelism, parallelism)
+	job.Parallelism = parallelism
+	triggerID := newID()
+	c.rescalings[triggerID] = job.ID
+
+	writeJSON(w, http.StatusOK, object{"request-id": triggerID})
+}
+
+func (c *Cluster) getRescalingStatus(w http.ResponseWriter, r *http.Request, params []string) {
+	if c.rescalings[params[1]] != params[0] {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Operation not found under key: %v/%v", params[0], params[1]))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, object{"status": object{"id": "COMPLETED"}, "operation": object{}})
+}
+
+// parseVersion returns the major and minor version as a number, e.g. 109 for 1.9.0
+func parseVersion(version string) (int, error) {
+	var major, minor int
+	_, err := fmt.Sscanf(version, "%d.%d", &major, &minor)
+	return major*100 + minor, err
+}
@@ -0,0 +1,126 @@
+package flinktest
+
+import (
+	"net/http"
+	"path"
+	"time"
+)
+
+// A Failure makes the cluster respond to matching requests with an error
+type Failure struct {
+	// Method is the HTTP method to match, or empty to match every method
+	Method string
+	// Path is the pattern of the request path to match, as supported
+	// by path.Match, e.g. "/jobs/*/savepoints"
+	Path string
+	// StatusCode is the status of the error response, which defaults to 500
+	StatusCode int
+	// Message is the error reported in the body of the error response
+	Message string
+	// Drop closes the connection without responding at all,
+	// like a JobManager which crashed or is unreachable
+	Drop bool
+	// Times is the number of requests to fail, or zero to fail all of them
+	Times int
+}
+
+// A Latency delays the responses to matching requests
+type Latency struct {
+	// Method is the HTTP method to match, or empty to match every method
+	Method string
+	// Path is the pattern of the request path to match, as supported
+	// by path.Match, e.g. "/jobs/overview"
+	Path string
+	// Delay is the time the response is delayed
+	Delay time.Duration
+}
+
+type injectedFailure struct {
+	Failure
+	remaining int
+}
+
+func matches(method string, pattern string, r *http.Request) bool {
+	if len(method) > 0 && method != r.Method {
+		return false
+	}
+	matched, _ := path.Match(pattern, r.URL.Path)
+	return matched
+}
+
+// InjectFailure makes the matching requests fail. Failures are matched
+// in the order they were injected, before the request is handled.
+func (c *Cluster) InjectFailure(failure Failure) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if failure.StatusCode == 0 {
+		failure.StatusCode = http.StatusInternalServerError
+	}
+	if len(failure.Message) == 0 {
+		failure.Message = "Injected failure."
+	}
+	c.failures = append(c.failures, &injectedFailure{Failure: failure, remaining: failure.Times})
+}
+
+// InjectLatency delays the responses to the matching requests.
+// The delays of all matching latencies add up.
+func (c *Cluster) InjectLatency(latency Latency) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.latencies = append(c.latencies, latency)
+}
+
+// ClearInjections removes all injected failures and latencies
+func (c *Cluster) ClearInjections() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.failures = nil
+	c.latencies = nil
+}
+
+// failureFor returns the injected failure the request matches, if any,
+// and counts the request towards the number of times it fails
+func (c *Cluster) failureFor(r *http.Request) (Failure, bool) {
+	for i, failure := range c.failures {
+		if !matches(failure.Method, failure.Path, r) {
+			continue
+		}
+		if failure.Times > 0 {
+			failure.remaining--
+			if failure.remaining == 0 {
+				c.failures = append(c.failures[:i], c.failures[i+1:]...)
+			}
+		}
+		return failure.Failure, true
+	}
+	return Failure{}, false
+}
+
+// delayFor returns the total delay of the latencies the request matches
+func (c *Cluster) delayFor(r *http.Request) time.Duration {
+	var delay time.Duration
+	for _, latency := range c.latencies {
+		if matches(latency.Method, latency.Path, r) {
+			delay += latency.Delay
+		}
+	}
+	return delay
+}
+
+// injectFailure responds with the failure, or drops the connection
+func injectFailure(w http.ResponseWriter, failure Failure) {
+	if failure.Drop {
+		hijacker, ok := w.(http.Hijacker)
+		if ok {
+			conn, _, err := hijacker.Hijack()
+			if err == nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+	writeError(w, failure.StatusCode, failure.Message)
+}
@@ -0,0 +1,125 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// clusterCapacity represents the task slots of the Flink cluster
+type clusterCapacity struct {
+	TaskManagers   int
+	TotalSlots     int
+	AvailableSlots int
+}
+
+func (o RealOperator) retrieveClusterCapacity(ctx context.Context) (clusterCapacity, error) {
+	overview, err := o.FlinkRestAPI.RetrieveClusterOverview(ctx)
+	if err != nil {
+		return clusterCapacity{}, flink.Errorf("retrieving the cluster overview failed: %v", err)
+	}
+
+	taskManagers, err := o.FlinkRestAPI.RetrieveTaskManagers(ctx)
+	if err != nil {
+		return clusterCapacity{}, flink.Errorf("retrieving the task managers failed: %v", err)
+	}
+
+	freeSlots := 0
+	for _, taskManager := range taskManagers {
+		freeSlots += taskManager.FreeSlots
+	}
+
+	// Both endpoints are served from different components and can briefly
+	// disagree while task managers (de)register, so use the lowest count
+	availableSlots := overview.SlotsAvailable
+	if freeSlots < availableSlots {
+		availableSlots = freeSlots
+	}
+
+	return clusterCapacity{
+		TaskManagers:   len(taskManagers),
+		TotalSlots:     overview.SlotsTotal,
+		AvailableSlots: availableSlots,
+	}, nil
+}
+
+// retrieveSlotsUsedByJob returns the number of slots a running job occupies.
+// With the default slot sharing group this equals the highest vertex parallelism.
+func (o RealOperator) retrieveSlotsUsedByJob(ctx context.Context, jobID string) (int, error) {
+	plan, err := o.FlinkRestAPI.RetrieveJobPlan(ctx, jobID)
+	if err != nil {
+		return 0, flink.Errorf("retrieving the plan of job \"%v\" failed: %v", jobID, err)
+	}
+
+	slots := 0
+	for _, node := range plan.Nodes {
+		if node.Parallelism > slots {
+			slots = node.Parallelism
+		}
+	}
+
+	return slots, nil
+}
+
+// resolveAutoParallelism determines the parallelism based on the available
+// slots, including the slots that will be released by a job being replaced
+func (o RealOperator) resolveAutoParallelism(ctx context.Context, limit int, releasedSlots int) (int, error) {
+	capacity, err := o.retrieveClusterCapacity(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	parallelism := capacity.AvailableSlots + releasedSlots
+	if parallelism == 0 {
+		return 0, errors.New("unable to determine the parallelism automatically as no task slots are available")
+	}
+	if limit > 0 && parallelism > limit {
+		parallelism = limit
+	}
+
+	o.logf("using automatically determined parallelism of %v", parallelism)
+
+	return parallelism, nil
+}
+
+// waitForCapacity verifies that the cluster has enough free slots to run a job with
+// the required parallelism. A timeout of 0 fails immediately when capacity is short.
+// Clusters without task managers start them on demand, like native Kubernetes and
+// YARN sessions, so their capacity isn't known before the job is submitted.
+func (o RealOperator) waitForCapacity(ctx context.Context, required int, releasedSlots int, timeout int) error {
+	op := func() error {
+		capacity, err := o.retrieveClusterCapacity(ctx)
+		if err != nil {
+			return permanent(err)
+		}
+
+		if capacity.TaskManagers == 0 {
+			o.logf("cluster has no task managers, skipping the capacity check as they are started on demand")
+			return nil
+		}
+
+		available := capacity.AvailableSlots + releasedSlots
+		if available >= required {
+			o.logf("cluster has %v available slots for a parallelism of %v", available, required)
+			return nil
+		}
+
+		return fmt.Errorf("insufficient capacity: the job requires %v slots but only %v of %v slots on %v task managers are available", required, available, capacity.TotalSlots, capacity.TaskManagers)
+	}
+
+	if timeout <= 0 {
+		err := op()
+		if permanentErr, ok := err.(*backoff.PermanentError); ok {
+			return permanentErr.Err
+		}
+		return err
+	}
+
+	poller := Poller{Timeout: time.Duration(timeout) * time.Second, Logger: o.Logger}
+	err := poller.Poll(ctx, op)
+	return timeoutErrorf(err, "%v after waiting %v seconds", err, poller.Timeout.Seconds())
+}
@@ -0,0 +1,220 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockClusterCapacity(totalSlots int, availableSlots int) {
+	mockedRetrieveClusterOverviewResponse = flink.ClusterOverview{
+		TaskManagers:   1,
+		SlotsTotal:     totalSlots,
+		SlotsAvailable: availableSlots,
+	}
+	mockedRetrieveClusterOverviewError = nil
+	mockedRetrieveTaskManagersResponse = []flink.TaskManager{
+		flink.TaskManager{
+			ID:          "tm-1",
+			SlotsNumber: totalSlots,
+			FreeSlots:   availableSlots,
+		},
+	}
+	mockedRetrieveTaskManagersError = nil
+}
+
+/*
+ * retrieveClusterCapacity
+ */
+func TestRetrieveClusterCapacityShouldReturnAnErrorWhenTheOverviewCannotBeRetrieved(t *testing.T) {
+	mockedRetrieveClusterOverviewError = errors.New("failed")
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	_, err := operator.retrieveClusterCapacity(context.Background())
+
+	assert.EqualError(t, err, "retrieving the cluster overview failed: failed")
+}
+
+func TestRetrieveClusterCapacityShouldUseTheLowestNumberOfAvailableSlots(t *testing.T) {
+	mockClusterCapacity(4, 4)
+	mockedRetrieveTaskManagersResponse[0].FreeSlots = 2
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	capacity, err := operator.retrieveClusterCapacity(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, clusterCapacity{TaskManagers: 1, TotalSlots: 4, AvailableSlots: 2}, capacity)
+}
+
+/*
+ * retrieveSlotsUsedByJob
+ */
+func TestRetrieveSlotsUsedByJobShouldReturnTheHighestVertexParallelism(t *testing.T) {
+	mockedRetrieveJobPlanResponse = testPlan
+	mockedRetrieveJobPlanError = nil
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	slots, err := operator.retrieveSlotsUsedByJob(context.Background(), "job-1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, slots)
+}
+
+/*
+ * resolveAutoParallelism
+ */
+func TestResolveAutoParallelismShouldReturnAnErrorWhenNoSlotsAreAvailable(t *testing.T) {
+	mockClusterCapacity(4, 0)
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	_, err := operator.resolveAutoParallelism(context.Background(), 0, 0)
+
+	assert.EqualError(t, err, "unable to determine the parallelism automatically as no task slots are available")
+}
+
+func TestResolveAutoParallelismShouldIncludeTheReleasedSlots(t *testing.T) {
+	mockClusterCapacity(4, 1)
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	parallelism, err := operator.resolveAutoParallelism(context.Background(), 0, 2)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, parallelism)
+}
+
+func TestResolveAutoParallelismShouldNotExceedTheLimit(t *testing.T) {
+	mockClusterCapacity(8, 8)
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	parallelism, err := operator.resolveAutoParallelism(context.Background(), 3, 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, parallelism)
+}
+
+/*
+ * waitForCapacity
+ */
+func TestWaitForCapacityShouldFailFastWhenTheCapacityIsShort(t *testing.T) {
+	mockClusterCapacity(4, 1)
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	err := operator.waitForCapacity(context.Background(), 2, 0, 0)
+
+	assert.EqualError(t, err, "insufficient capacity: the job requires 2 slots but only 1 of 4 slots on 1 task managers are available")
+}
+
+func TestWaitForCapacityShouldReturnAnErrorAfterTheTimeout(t *testing.T) {
+	mockClusterCapacity(4, 1)
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	err := operator.waitForCapacity(context.Background(), 2, 0, 1)
+
+	assert.EqualError(t, err, "insufficient capacity: the job requires 2 slots but only 1 of 4 slots on 1 task managers are available after waiting 1 seconds")
+}
+
+func TestWaitForCapacityShouldTakeTheReleasedSlotsIntoAccount(t *testing.T) {
+	mockClusterCapacity(4, 1)
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	err := operator.waitForCapacity(context.Background(), 3, 2, 0)
+
+	assert.Nil(t, err)
+}
+
+func TestWaitForCapacityShouldSkipTheCheckWhenTheClusterHasNoTaskManagers(t *testing.T) {
+	mockedRetrieveClusterOverviewResponse = flink.ClusterOverview{}
+	mockedRetrieveClusterOverviewError = nil
+	mockedRetrieveTaskManagersResponse = []flink.TaskManager{}
+	mockedRetrieveTaskManagersError = nil
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	err := operator.waitForCapacity(context.Background(), 2, 0, 0)
+
+	assert.Nil(t, err)
+}
+
+/*
+ * Deploy and Update
+ */
+func TestDeployShouldReturnAnErrorWhenTheCapacityIsShort(t *testing.T) {
+	mockClusterCapacity(4, 1)
+
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	_, err := operator.Deploy(context.Background(), Deploy{
+		LocalFilename: "testdata/sample.jar",
+		Parallelism:   2,
+		CheckCapacity: true,
+	})
+
+	assert.EqualError(t, err, "insufficient capacity: the job requires 2 slots but only 1 of 4 slots on 1 task managers are available")
+}
+
+func TestUpdateJobShouldReturnAnErrorBeforeCreatingASavepointWhenTheCapacityIsShort(t *testing.T) {
+	mockClusterCapacity(4, 0)
+	mockedRetrieveJobsError = nil
+	mockedRetrieveJobsResponse = []flink.Job{
+		flink.Job{
+			ID:     "Job-A",
+			Name:   "WordCountStateful v1.0",
+			Status: "RUNNING",
+		},
+	}
+	mockedRetrieveJobPlanResponse = testPlan
+	mockedRetrieveJobPlanError = nil
+	mockedCreateSavepointError = errors.New("should not be called")
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	_, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:   "WordCountStateful",
+		LocalFilename: "testdata/sample.jar",
+		SavepointDir:  "/data/flink",
+		Parallelism:   3,
+		CheckCapacity: true,
+	})
+
+	assert.EqualError(t, err, "insufficient capacity: the job requires 3 slots but only 2 of 4 slots on 1 task managers are available")
+}
@@ -0,0 +1,244 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// Deploy represents the configuration used for
+// deploying a job to the Flink cluster
+type Deploy struct {
+	RemoteFilename        string
+	APIToken              string
+	APITokenPreset        string
+	LocalFilename         string
+	Artifact              string
+	SHA256                string
+	Signature             string
+	EntryClass            string
+	Parallelism           int
+	ProgramArgs           []string
+	SavepointDir          string
+	SavepointPath         string
+	AllowNonRestoredState bool
+	AutoParallelism       bool
+	ParallelismLimit      int
+	CheckCapacity         bool
+	CapacityTimeout       int
+	JobID                 string
+	RestoreMode           string
+	FlinkConfiguration    map[string]string
+	// JarID is the ID of a JAR file which is already uploaded to the
+	// cluster and is run instead of uploading the JAR file of the source
+	JarID string
+}
+
+// DeployResult describes the job started by a deployment
+type DeployResult struct {
+	JarID         string
+	JobID         string
+	Parallelism   int
+	SavepointPath string
+}
+
+func (d Deploy) jarSource() jarSource {
+	return jarSource{
+		LocalFilename:  d.LocalFilename,
+		RemoteFilename: d.RemoteFilename,
+		APIToken:       d.APIToken,
+		APITokenPreset: d.APITokenPreset,
+		Artifact:       d.Artifact,
+		SHA256:         d.SHA256,
+		Signature:      d.Signature,
+		EntryClass:     d.EntryClass,
+	}
+}
+
+func (d Deploy) runJarOptions() flink.RunJarOptions {
+	return flink.RunJarOptions{
+		JobID:              d.JobID,
+		RestoreMode:        d.RestoreMode,
+		FlinkConfiguration: d.FlinkConfiguration,
+	}
+}
+
+func (o RealOperator) extractJarIDFromFilename(filename string) string {
+	parts := strings.Split(filename, "/")
+	return parts[len(parts)-1]
+}
+
+// prepareJar downloads the JAR file of the source, unless it's a local
+// file, and verifies it. It returns the path of the JAR file and a function
+// removing the downloaded file.
+func (o RealOperator) prepareJar(ctx context.Context, source jarSource) (string, func(), error) {
+	filename := source.LocalFilename
+	cleanup := func() {}
+	if len(filename) == 0 {
+		dir, err := ioutil.TempDir("", "flink-deployer")
+		if err != nil {
+			return "", cleanup, err
+		}
+		cleanup = func() { os.RemoveAll(dir) }
+
+		filename, err = o.downloadJar(ctx, source, dir)
+		if err != nil {
+			cleanup()
+			return "", func() {}, err
+		}
+	}
+
+	err := o.verifyJar(ctx, source, filename)
+	if err == nil {
+		err = o.inspectJar(source, filename)
+	}
+	if err != nil {
+		cleanup()
+		return "", func() {}, err
+	}
+	return filename, cleanup, nil
+}
+
+// uploadFile uploads the JAR file to the
+// Flink cluster and returns the resulting JAR ID
+func (o RealOperator) uploadFile(ctx context.Context, filename string) (string, error) {
+	o.logf("Uploading JAR file")
+	uploadResponse, err := o.FlinkRestAPI.UploadJar(ctx, filename)
+	if err != nil {
+		return "", err
+	}
+
+	jarID := o.extractJarIDFromFilename(uploadResponse.Filename)
+	o.notify(Event{Type: EventJarUploaded, JarID: jarID})
+	return jarID, nil
+}
+
+// uploadJar uploads the JAR file of the source to the
+// Flink cluster and returns the resulting JAR ID
+func (o RealOperator) uploadJar(ctx context.Context, source jarSource) (string, error) {
+	filename, cleanup, err := o.prepareJar(ctx, source)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	return o.uploadFile(ctx, filename)
+}
+
+// Deploy executes the actual deployment to the Flink cluster
+func (o RealOperator) Deploy(ctx context.Context, d Deploy) (DeployResult, error) {
+	programArgs, err := o.Secrets.Substitute(ctx, d.ProgramArgs)
+	if err != nil {
+		return DeployResult{}, err
+	}
+	d.ProgramArgs = programArgs
+
+	return o.deploy(ctx, d, false)
+}
+
+// deploy executes the deployment with the secrets of the program arguments
+// already resolved. The options of the run request are checked with the
+// cluster before the JAR file is uploaded, unless they've been checked.
+func (o RealOperator) deploy(ctx context.Context, d Deploy, optionsChecked bool) (DeployResult, error) {
+	o.logf("Starting deploy")
+
+	if len(d.SavepointDir) > 0 && len(d.SavepointPath) > 0 {
+		return DeployResult{}, errors.New("both properties 'SavepointDir' and 'SavepointPath' are specified")
+	}
+
+	if len(d.SavepointDir) > 0 {
+		o.logf("Using savepoint directory to retrieve the latest savepoint: %v", d.SavepointDir)
+
+		latestSavepoint, err := o.retrieveLatestSavepoint(d.SavepointDir)
+		if err != nil {
+			return DeployResult{}, flink.Errorf("retrieving the latest savepoint failed: %v", err)
+		}
+
+		if len(latestSavepoint) != 0 {
+			d.SavepointPath = latestSavepoint
+		}
+	}
+
+	if len(d.SavepointPath) > 0 {
+		o.logf("Using savepoint for deployment: %v", d.SavepointPath)
+	}
+
+	if d.AllowNonRestoredState == true {
+		o.logf("Allowing non restorable state")
+	}
+
+	if len(d.JarID) == 0 && len(d.RemoteFilename) == 0 && len(d.LocalFilename) == 0 && len(d.Artifact) == 0 {
+		return DeployResult{}, errors.New("the properties 'RemoteFilename', 'LocalFilename' and 'Artifact' are unspecified")
+	}
+
+	// The JAR file is verified and inspected before any call to the cluster
+	var filename string
+	if len(d.JarID) == 0 {
+		var cleanup func()
+		var err error
+		filename, cleanup, err = o.prepareJar(ctx, d.jarSource())
+		if err != nil {
+			return DeployResult{}, err
+		}
+		defer cleanup()
+	}
+
+	var err error
+	if !optionsChecked {
+		err = o.FlinkRestAPI.CheckRunJarOptions(ctx, d.runJarOptions())
+		if err != nil {
+			return DeployResult{}, err
+		}
+	}
+
+	if d.AutoParallelism == true {
+		parallelism, err := o.resolveAutoParallelism(ctx, d.ParallelismLimit, 0)
+		if err != nil {
+			return DeployResult{}, err
+		}
+		d.Parallelism = parallelism
+	}
+
+	if d.CheckCapacity == true {
+		err := o.waitForCapacity(ctx, d.Parallelism, 0, d.CapacityTimeout)
+		if err != nil {
+			return DeployResult{}, err
+		}
+	}
+
+	jarID := d.JarID
+	if len(jarID) == 0 {
+		err = o.safePoint(ctx, "deploy stopped before uploading the JAR file")
+		if err != nil {
+			return DeployResult{}, err
+		}
+
+		jarID, err = o.uploadFile(ctx, filename)
+		if err != nil {
+			return DeployResult{}, err
+		}
+	}
+
+	err = o.safePoint(ctx, "deploy stopped after uploading JAR \"%v\", before running the job", jarID)
+	if err != nil {
+		return DeployResult{}, err
+	}
+
+	o.logf("Running job")
+	runResponse, err := o.FlinkRestAPI.RunJar(ctx, jarID, d.EntryClass, d.ProgramArgs, d.Parallelism, d.SavepointPath, d.AllowNonRestoredState, d.runJarOptions())
+	if err != nil {
+		return DeployResult{}, o.diagnoseFailure(ctx, "", err)
+	}
+	o.notify(Event{Type: EventJobRunning, JobID: runResponse.JobID, JarID: jarID, SavepointPath: d.SavepointPath})
+
+	return DeployResult{
+		JarID:         jarID,
+		JobID:         runResponse.JobID,
+		Parallelism:   d.Parallelism,
+		SavepointPath: d.SavepointPath,
+	}, nil
+}
@@ -0,0 +1,459 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ing-bank/flink-deployer/pkg/artifact"
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/ing-bank/flink-deployer/pkg/flinktest"
+	"github.com/ing-bank/flink-deployer/pkg/secret"
+	"github.com/spf13/afero"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * extractJarIDFromFilename
+ */
+func TestExtractJarIDFromFilenameShouldReturnThePartAfterTheLastSlash(t *testing.T) {
+	operator := RealOperator{}
+
+	jarID := operator.extractJarIDFromFilename("/data/flink/sample.jar")
+
+	assert.Equal(t, "sample.jar", jarID)
+}
+
+/*
+ * Deploy
+ */
+func TestDeployShouldReturnAnErrorWhenBothTheSavepointDirAndSavepointPathAreSet(t *testing.T) {
+	operator := RealOperator{}
+
+	_, err := operator.Deploy(context.Background(), Deploy{
+		SavepointDir:  "/data/flink",
+		SavepointPath: "/data/flink/savepoint-abc",
+	})
+
+	assert.EqualError(t, err, "both properties 'SavepointDir' and 'SavepointPath' are specified")
+}
+
+func TestDeployShouldReturnAnErrorWhenNeitherTheLocalOrRemoteFileNameAreSet(t *testing.T) {
+	operator := RealOperator{}
+
+	_, err := operator.Deploy(context.Background(), Deploy{})
+
+	assert.EqualError(t, err, "the properties 'RemoteFilename', 'LocalFilename' and 'Artifact' are unspecified")
+}
+
+func TestDeployShouldReturnAnErrorWhenTheJarUploadFails(t *testing.T) {
+	mockedUploadJarError = errors.New("failed")
+
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	_, err := operator.Deploy(context.Background(), Deploy{
+		LocalFilename: "testdata/sample.jar",
+	})
+
+	assert.EqualError(t, err, "failed")
+}
+
+func TestDeployShouldReturnAnErrorWhenTheLatestSavepointCannotBeRetrieved(t *testing.T) {
+	filesystem := afero.NewMemMapFs()
+	filesystem.Mkdir("/data/flink/", 0755)
+
+	mockedUploadJarResponse = flink.UploadJarResponse{
+		Filename: "/data/flink/sample.jar",
+		Status:   "success",
+	}
+	mockedUploadJarError = nil
+
+	operator := RealOperator{
+		Filesystem: filesystem,
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	_, err := operator.Deploy(context.Background(), Deploy{
+		LocalFilename: "testdata/sample.jar",
+		SavepointDir:  "/data/flink",
+	})
+
+	assert.EqualError(t, err, "retrieving the latest savepoint failed: No savepoints present in directory: /data/flink")
+}
+
+func TestDeployShouldReturnAnErrorWhenTheJarRunFails(t *testing.T) {
+	mockedUploadJarResponse = flink.UploadJarResponse{
+		Filename: "/data/flink/sample.jar",
+		Status:   "success",
+	}
+	mockedUploadJarError = nil
+	mockedRunJarError = errors.New("failed")
+
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	_, err := operator.Deploy(context.Background(), Deploy{
+		LocalFilename: "testdata/sample.jar",
+	})
+
+	assert.EqualError(t, err, "failed")
+}
+
+func TestDeployShouldReturnNilWhenTheDeploySucceeds(t *testing.T) {
+	mockedUploadJarResponse = flink.UploadJarResponse{
+		Filename: "/data/flink/sample.jar",
+		Status:   "success",
+	}
+	mockedUploadJarError = nil
+	mockedRunJarError = nil
+
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	_, err := operator.Deploy(context.Background(), Deploy{
+		LocalFilename: "testdata/sample.jar",
+	})
+
+	assert.Nil(t, err)
+}
+
+func TestDeployShouldStopBeforeUploadingTheJarWhenCanceled(t *testing.T) {
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := operator.Deploy(ctx, Deploy{
+		LocalFilename: "testdata/sample.jar",
+	})
+
+	assert.EqualError(t, err, "deploy stopped before uploading the JAR file")
+	assert.Equal(t, flink.ErrCanceled, flink.Category(err))
+}
+
+func TestDeployShouldRunTheJarOnAFakeCluster(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	filename, cleanup := createTestJarFile(t, "WordCount.jar")
+	defer cleanup()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	result, err := operator.Deploy(context.Background(), Deploy{
+		LocalFilename: filename,
+		EntryClass:    "com.example.WordCount",
+		ProgramArgs:   []string{"--input", "/data/input"},
+		Parallelism:   3,
+	})
+
+	assert.Nil(t, err)
+	job, ok := cluster.Job(result.JobID)
+	assert.True(t, ok)
+	assert.Equal(t, flinktest.StatusRunning, job.Status)
+	assert.Equal(t, result.JarID, job.JarID)
+	assert.Equal(t, "com.example.WordCount", job.EntryClass)
+	assert.Equal(t, []string{"--input", "/data/input"}, job.ProgramArgs)
+	assert.Equal(t, 3, job.Parallelism)
+}
+
+func TestDeployShouldRunTheJarWithTheOptionsOnAFakeCluster(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	cluster.Version = "1.18.1"
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	filename, cleanup := createTestJarFile(t, "WordCount.jar")
+	defer cleanup()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	result, err := operator.Deploy(context.Background(), Deploy{
+		LocalFilename:      filename,
+		JobID:              "a8c2a1e0a2b94e5f8a6b7c3d4e5f6a7b",
+		RestoreMode:        flink.RestoreModeNoClaim,
+		FlinkConfiguration: map[string]string{"pipeline.name": "word-count"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "a8c2a1e0a2b94e5f8a6b7c3d4e5f6a7b", result.JobID)
+	job, ok := cluster.Job(result.JobID)
+	assert.True(t, ok)
+	assert.Equal(t, "NO_CLAIM", job.RestoreMode)
+	assert.Equal(t, map[string]string{"pipeline.name": "word-count"}, job.FlinkConfiguration)
+}
+
+func TestDeployShouldResolveTheSecretsOfTheProgramArgs(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	filename, cleanup := createTestJarFile(t, "WordCount.jar")
+	defer cleanup()
+	secretFile, err := ioutil.TempFile("", "kafka-password")
+	assert.Nil(t, err)
+	defer os.Remove(secretFile.Name())
+	secretFile.WriteString("s3cr3t\n")
+	secretFile.Close()
+
+	redactor := &secret.Redactor{}
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	operator.Secrets = secret.Resolver{Redactor: redactor}
+	result, err := operator.Deploy(context.Background(), Deploy{
+		LocalFilename: filename,
+		ProgramArgs:   []string{"--password", "${file:" + secretFile.Name() + "}", "--topic", "$${raw}"},
+	})
+
+	assert.Nil(t, err)
+	job, _ := cluster.Job(result.JobID)
+	assert.Equal(t, []string{"--password", "s3cr3t", "--topic", "${raw}"}, job.ProgramArgs)
+	assert.Equal(t, "--password [redacted]", redactor.Redact("--password s3cr3t"))
+}
+
+func TestUpdateJobShouldKeepTheJobRunningWhenASecretCannotBeResolved(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	jobID := cluster.StartJob("WordCount v1", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	filename, cleanup := createTestJarFile(t, "WordCount.jar")
+	defer cleanup()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	_, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:   "WordCount",
+		LocalFilename: filename,
+		SavepointDir:  "/data/flink/savepoints",
+		ProgramArgs:   []string{"--password", "${file:/run/secrets/flink-test-missing}"},
+	})
+
+	assert.EqualError(t, err, `unable to resolve the secret of the program argument "${file:/run/secrets/flink-test-missing}": unable to read the secret file: open /run/secrets/flink-test-missing: no such file or directory`)
+	job, _ := cluster.Job(jobID)
+	assert.Equal(t, "RUNNING", job.Status)
+	assert.Len(t, cluster.Requests(), 0)
+}
+
+func TestDeployShouldPrintTheExceptionOfTheRunRequestWhenTheJobFailsToRun(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	jobID := cluster.StartJob("WordCount v1", 1)
+	assert.Nil(t, cluster.FailJob(jobID, "java.lang.OutOfMemoryError: Java heap space"))
+	cluster.InjectFailure(flinktest.Failure{Method: "POST", Path: "/jars/*/run", StatusCode: http.StatusInternalServerError, Message: "The main method caused an error"})
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	filename, cleanup := createTestJarFile(t, "WordCount.jar")
+	defer cleanup()
+
+	output := new(bytes.Buffer)
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	operator.Logger = log.New(output, "", 0)
+	_, err := operator.Deploy(context.Background(), Deploy{
+		LocalFilename: filename,
+	})
+
+	assert.NotNil(t, err)
+	assert.Contains(t, output.String(), "exception returned by the cluster:\nThe main method caused an error\n")
+	assert.NotContains(t, output.String(), "java.lang.OutOfMemoryError")
+	runs := 0
+	for _, request := range cluster.Requests() {
+		if strings.HasPrefix(request, "POST /jars/") && strings.HasSuffix(request, "/run") {
+			runs++
+		}
+	}
+	assert.Equal(t, 1, runs)
+}
+
+func TestDeployShouldNotUploadAJarFileWhenTheClusterDoesNotSupportTheOptions(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	filename, cleanup := createTestJarFile(t, "WordCount.jar")
+	defer cleanup()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	_, err := operator.Deploy(context.Background(), Deploy{
+		LocalFilename: filename,
+		RestoreMode:   flink.RestoreModeClaim,
+	})
+
+	assert.EqualError(t, err, "the restore mode requires Flink 1.15 or newer, but the cluster runs Flink 1.9.0")
+	assert.Len(t, cluster.Jars(), 0)
+}
+
+func TestDeployShouldNotUploadAJarFileWithoutTheEntryClass(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	filename, cleanup := createTestJarArchive(t, "Main-Class: com.example.WordCount\n", "com.example.WordCount")
+	defer cleanup()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	_, err := operator.Deploy(context.Background(), Deploy{
+		LocalFilename: filename,
+		EntryClass:    "com.example.Wordcount",
+	})
+
+	assert.EqualError(t, err, "the entry class com.example.Wordcount doesn't exist in the JAR file")
+	assert.Equal(t, 0, len(cluster.Jars()))
+}
+
+func TestDeployShouldRunTheMainClassOfTheManifest(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	filename, cleanup := createTestJarArchive(t, "Main-Class: com.example.WordCount\nImplementation-Version: 1.2.0\n", "com.example.WordCount")
+	defer cleanup()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	result, err := operator.Deploy(context.Background(), Deploy{LocalFilename: filename})
+
+	assert.Nil(t, err)
+	_, ok := cluster.Job(result.JobID)
+	assert.True(t, ok)
+}
+
+func TestDeployShouldRunTheMavenArtifactOnAFakeCluster(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	jar := wordCountJar(t)
+	checksum := sha1.Sum(jar)
+	repository := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/com/example/word-count/1.2.0/word-count-1.2.0.jar":
+			w.Write(jar)
+		case "/com/example/word-count/1.2.0/word-count-1.2.0.jar.sha1":
+			w.Write([]byte(hex.EncodeToString(checksum[:])))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer repository.Close()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	operator.Maven = artifact.Maven{Repositories: []artifact.MavenRepository{{URL: repository.URL}}}
+	result, err := operator.Deploy(context.Background(), Deploy{
+		Artifact:   "maven:com.example:word-count:1.2.0",
+		EntryClass: "com.example.WordCount",
+	})
+
+	assert.Nil(t, err)
+	job, ok := cluster.Job(result.JobID)
+	assert.True(t, ok)
+	assert.Equal(t, "word-count-1.2.0", job.Name)
+	assert.Equal(t, jar, cluster.Jars()[0].Content)
+}
+
+func TestDeployShouldRunTheS3ArtifactOnAFakeCluster(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	jar := wordCountJar(t)
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/jobs/word-count/word-count-1.2.0.jar" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(jar)
+	}))
+	defer storage.Close()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	operator.S3 = artifact.S3{Endpoint: storage.URL, PathStyle: true}
+	result, err := operator.Deploy(context.Background(), Deploy{
+		Artifact: "s3://jobs/word-count/word-count-1.2.0.jar",
+	})
+
+	assert.Nil(t, err)
+	job, ok := cluster.Job(result.JobID)
+	assert.True(t, ok)
+	assert.Equal(t, "word-count-1.2.0", job.Name)
+}
+
+func TestDeployShouldRunTheOCIArtifactOnAFakeCluster(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	jar := wordCountJar(t)
+	sum := sha256.Sum256(jar)
+	layerDigest := "sha256:" + hex.EncodeToString(sum[:])
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/jobs/word-count/manifests/1.2.0":
+			fmt.Fprintf(w, `{"schemaVersion": 2, "layers": [{"mediaType": "application/java-archive", "digest": "%v", "size": %v, "annotations": {"org.opencontainers.image.title": "word-count-1.2.0.jar"}}]}`, layerDigest, len(jar))
+		case "/v2/jobs/word-count/blobs/" + layerDigest:
+			w.Write(jar)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer registry.Close()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	operator.OCI = artifact.OCI{PlainHTTP: true}
+	result, err := operator.Deploy(context.Background(), Deploy{
+		Artifact: "oci://" + strings.TrimPrefix(registry.URL, "http://") + "/jobs/word-count:1.2.0",
+	})
+
+	assert.Nil(t, err)
+	job, ok := cluster.Job(result.JobID)
+	assert.True(t, ok)
+	assert.Equal(t, "word-count-1.2.0", job.Name)
+}
+
+func TestDeployShouldNotUploadAJarFileWithAnotherChecksum(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("tampered"))
+	}))
+	defer storage.Close()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	operator.S3 = artifact.S3{Endpoint: storage.URL, PathStyle: true}
+	checksum := sha256.Sum256([]byte("jar"))
+	_, err := operator.Deploy(context.Background(), Deploy{
+		Artifact: "s3://jobs/word-count.jar",
+		SHA256:   hex.EncodeToString(checksum[:]),
+	})
+
+	assert.True(t, strings.HasPrefix(err.Error(), "the SHA-256 checksum of "))
+	assert.Equal(t, 0, len(cluster.Jars()))
+}
+
+func TestDeployShouldReturnAnErrorForAnUnsupportedArtifact(t *testing.T) {
+	operator := RealOperator{}
+
+	_, err := operator.Deploy(context.Background(), Deploy{
+		Artifact: "ivy:com.example:word-count:1.2.0",
+	})
+
+	assert.EqualError(t, err, "retrieving artifact \"ivy:com.example:word-count:1.2.0\" failed: unsupported artifact reference \"ivy:com.example:word-count:1.2.0\"")
+}
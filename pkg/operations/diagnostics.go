@@ -0,0 +1,154 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// diagnosticsLogTailLines is the number of job manager log lines
+// printed when an operation fails
+const diagnosticsLogTailLines = 50
+
+// Exceptions represents the configuration used for
+// retrieving the exceptions of a job on the Flink cluster
+type Exceptions struct {
+	JobID       string
+	JobNameBase string
+}
+
+// Logs represents the configuration used for
+// retrieving the log files of the Flink cluster
+type Logs struct {
+	JobManager      bool
+	TaskManagerID   string
+	AllTaskManagers bool
+	TailLines       int
+}
+
+// A LogFile is the (tail of the) log file of a single Flink component
+type LogFile struct {
+	Source  string
+	Content string
+}
+
+// findLatestJob returns the most recently started job matching
+// the base name, regardless of its status
+func (o RealOperator) findLatestJob(ctx context.Context, jobNameBase string) (flink.Job, error) {
+	jobs, err := o.FlinkRestAPI.RetrieveJobs(ctx)
+	if err != nil {
+		return flink.Job{}, flink.Errorf("retrieving jobs failed: %v", err)
+	}
+
+	var latest flink.Job
+	found := false
+	for _, job := range jobs {
+		if !strings.HasPrefix(job.Name, jobNameBase) {
+			continue
+		}
+		if !found || job.StartTime > latest.StartTime {
+			latest = job
+			found = true
+		}
+	}
+
+	if !found {
+		return flink.Job{}, flink.CategorizedErrorf(flink.ErrNotFound, "no job found for job name base \"%v\"", jobNameBase)
+	}
+
+	return latest, nil
+}
+
+// Exceptions retrieves the exceptions of a job specified by ID or of
+// the most recently started job matching the base name
+func (o RealOperator) Exceptions(ctx context.Context, e Exceptions) (flink.JobExceptions, error) {
+	if len(e.JobID) == 0 && len(e.JobNameBase) == 0 {
+		return flink.JobExceptions{}, errors.New("both properties 'JobID' and 'JobNameBase' are unspecified")
+	}
+
+	jobID := e.JobID
+	if len(jobID) == 0 {
+		job, err := o.findLatestJob(ctx, e.JobNameBase)
+		if err != nil {
+			return flink.JobExceptions{}, err
+		}
+		jobID = job.ID
+	}
+
+	return o.FlinkRestAPI.RetrieveJobExceptions(ctx, jobID)
+}
+
+// Logs retrieves the log files of the job manager and the requested task managers
+func (o RealOperator) Logs(ctx context.Context, l Logs) ([]LogFile, error) {
+	logFiles := []LogFile{}
+
+	if l.JobManager == true || (len(l.TaskManagerID) == 0 && l.AllTaskManagers == false) {
+		content, err := o.FlinkRestAPI.RetrieveJobManagerLog(ctx, l.TailLines)
+		if err != nil {
+			return nil, flink.Errorf("retrieving the job manager log failed: %v", err)
+		}
+		logFiles = append(logFiles, LogFile{Source: "jobmanager", Content: content})
+	}
+
+	taskManagerIDs := []string{}
+	if len(l.TaskManagerID) > 0 {
+		taskManagerIDs = append(taskManagerIDs, l.TaskManagerID)
+	}
+	if l.AllTaskManagers == true {
+		taskManagers, err := o.FlinkRestAPI.RetrieveTaskManagers(ctx)
+		if err != nil {
+			return nil, flink.Errorf("retrieving the task managers failed: %v", err)
+		}
+		for _, taskManager := range taskManagers {
+			if taskManager.ID != l.TaskManagerID {
+				taskManagerIDs = append(taskManagerIDs, taskManager.ID)
+			}
+		}
+	}
+
+	for _, taskManagerID := range taskManagerIDs {
+		content, err := o.FlinkRestAPI.RetrieveTaskManagerLog(ctx, taskManagerID, l.TailLines)
+		if err != nil {
+			return nil, flink.Errorf("retrieving the log of task manager \"%v\" failed: %v", taskManagerID, err)
+		}
+		logFiles = append(logFiles, LogFile{Source: "taskmanager " + taskManagerID, Content: content})
+	}
+
+	return logFiles, nil
+}
+
+// diagnoseFailure prints the root cause exception of the job with the ID, or the
+// exception returned by the cluster when the job couldn't be started and has no
+// ID, and the tail of the job manager log before returning the original error.
+// Failures while collecting the diagnostics are only logged and operations
+// which were stopped through their context aren't diagnosed.
+func (o RealOperator) diagnoseFailure(ctx context.Context, jobID string, err error) error {
+	if isCanceled(err) {
+		return err
+	}
+
+	o.logf("collecting diagnostics for the failed operation")
+
+	if len(jobID) > 0 {
+		exceptions, exceptionsErr := o.FlinkRestAPI.RetrieveJobExceptions(ctx, jobID)
+		if exceptionsErr != nil {
+			o.logf("unable to retrieve the job exceptions: %v", exceptionsErr)
+		} else if len(exceptions.RootException) > 0 {
+			o.logf("root cause exception of job \"%v\":\n%v", jobID, exceptions.RootException)
+		}
+	} else if apiErr := flink.APIErrorOf(err); apiErr != nil && len(apiErr.Errors) > 0 {
+		o.logf("exception returned by the cluster:\n%v", strings.Join(apiErr.Errors, "\n"))
+	}
+
+	logFiles, logsErr := o.Logs(ctx, Logs{JobManager: true, TailLines: diagnosticsLogTailLines})
+	if logsErr != nil {
+		o.logf("unable to retrieve the job manager log: %v", logsErr)
+	}
+	for _, logFile := range logFiles {
+		o.logf("last %v lines of the %v log:\n%v", diagnosticsLogTailLines, logFile.Source, logFile.Content)
+	}
+
+	return err
+}
@@ -0,0 +1,136 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * findLatestJob
+ */
+func TestFindLatestJobShouldReturnTheMostRecentlyStartedJob(t *testing.T) {
+	mockedRetrieveJobsError = nil
+	mockedRetrieveJobsResponse = []flink.Job{
+		flink.Job{ID: "Job-A", Name: "WordCountStateful v1.0", Status: "CANCELED", StartTime: 1},
+		flink.Job{ID: "Job-B", Name: "WordCountStateful v1.1", Status: "FAILED", StartTime: 3},
+		flink.Job{ID: "Job-C", Name: "Other", Status: "RUNNING", StartTime: 5},
+	}
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	job, err := operator.findLatestJob(context.Background(), "WordCountStateful")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Job-B", job.ID)
+}
+
+func TestFindLatestJobShouldReturnAnErrorWhenNoJobMatches(t *testing.T) {
+	mockedRetrieveJobsError = nil
+	mockedRetrieveJobsResponse = []flink.Job{}
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	_, err := operator.findLatestJob(context.Background(), "WordCountStateful")
+
+	assert.EqualError(t, err, "no job found for job name base \"WordCountStateful\"")
+}
+
+/*
+ * Exceptions
+ */
+func TestExceptionsShouldReturnAnErrorWhenNoJobIsSpecified(t *testing.T) {
+	operator := RealOperator{}
+
+	_, err := operator.Exceptions(context.Background(), Exceptions{})
+
+	assert.EqualError(t, err, "both properties 'JobID' and 'JobNameBase' are unspecified")
+}
+
+func TestExceptionsShouldReturnTheExceptionsOfTheJob(t *testing.T) {
+	mockedRetrieveJobExceptionsError = nil
+	mockedRetrieveJobExceptionsResponse = flink.JobExceptions{
+		RootException: "java.lang.RuntimeException: boom",
+	}
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	exceptions, err := operator.Exceptions(context.Background(), Exceptions{JobID: "Job-A"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "java.lang.RuntimeException: boom", exceptions.RootException)
+}
+
+/*
+ * Logs
+ */
+func TestLogsShouldDefaultToTheJobManagerLog(t *testing.T) {
+	mockedRetrieveJobManagerLogError = nil
+	mockedRetrieveJobManagerLogResponse = "line 2\nline 3\n"
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	logFiles, err := operator.Logs(context.Background(), Logs{TailLines: 2})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []LogFile{LogFile{Source: "jobmanager", Content: "line 2\nline 3\n"}}, logFiles)
+}
+
+func TestLogsShouldRetrieveTheLogsOfAllTaskManagers(t *testing.T) {
+	mockedRetrieveTaskManagersError = nil
+	mockedRetrieveTaskManagersResponse = []flink.TaskManager{
+		flink.TaskManager{ID: "tm-1"},
+		flink.TaskManager{ID: "tm-2"},
+	}
+	mockedRetrieveTaskManagerLogError = nil
+	mockedRetrieveTaskManagerLogResponse = "line 1\n"
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	logFiles, err := operator.Logs(context.Background(), Logs{AllTaskManagers: true})
+
+	assert.Nil(t, err)
+	assert.Len(t, logFiles, 2)
+	assert.Equal(t, "taskmanager tm-2", logFiles[1].Source)
+}
+
+func TestLogsShouldReturnAnErrorWhenATaskManagerLogCannotBeRetrieved(t *testing.T) {
+	mockedRetrieveTaskManagerLogError = errors.New("failed")
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	_, err := operator.Logs(context.Background(), Logs{TaskManagerID: "tm-1"})
+
+	assert.EqualError(t, err, "retrieving the log of task manager \"tm-1\" failed: failed")
+}
+
+/*
+ * diagnoseFailure
+ */
+func TestDiagnoseFailureShouldReturnTheOriginalErrorWhenDiagnosticsFail(t *testing.T) {
+	mockedRetrieveJobExceptionsError = errors.New("unreachable")
+	mockedRetrieveJobManagerLogError = errors.New("unreachable")
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	err := operator.diagnoseFailure(context.Background(), "job-1", errors.New("failed"))
+
+	assert.EqualError(t, err, "failed")
+}
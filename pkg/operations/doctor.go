@@ -0,0 +1,196 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/spf13/afero"
+)
+
+// The possible outcomes of a single doctor check
+const (
+	CheckPass = "PASS"
+	CheckWarn = "WARN"
+	CheckFail = "FAIL"
+)
+
+// defaultSlowCheckpointSeconds is the average checkpoint duration
+// above which checkpoints are reported as slow
+const defaultSlowCheckpointSeconds = 60
+
+// Doctor represents the configuration used for
+// checking the health of the Flink cluster
+type Doctor struct {
+	JobNameBase           string
+	SavepointDir          string
+	SlowCheckpointSeconds int
+}
+
+// A CheckResult is the outcome of a single doctor check
+type CheckResult struct {
+	Name    string
+	Status  string
+	Message string
+}
+
+// Failed returns whether any of the check results has failed
+func Failed(results []CheckResult) bool {
+	for _, result := range results {
+		if result.Status == CheckFail {
+			return true
+		}
+	}
+	return false
+}
+
+func (o RealOperator) checkReachability(ctx context.Context) CheckResult {
+	overview, err := o.FlinkRestAPI.RetrieveClusterOverview(ctx)
+	if err != nil {
+		return CheckResult{"REST API", CheckFail, fmt.Sprintf("the cluster is unreachable: %v", err)}
+	}
+	return CheckResult{"REST API", CheckPass, fmt.Sprintf("reachable, Flink version %v", overview.FlinkVersion)}
+}
+
+func (o RealOperator) checkTaskManagers(ctx context.Context) CheckResult {
+	capacity, err := o.retrieveClusterCapacity(ctx)
+	if err != nil {
+		return CheckResult{"Task managers", CheckFail, err.Error()}
+	}
+	message := fmt.Sprintf("%v task managers with %v of %v slots available", capacity.TaskManagers, capacity.AvailableSlots, capacity.TotalSlots)
+	if capacity.TaskManagers == 0 {
+		return CheckResult{"Task managers", CheckFail, "no task managers registered"}
+	}
+	if capacity.AvailableSlots == 0 {
+		return CheckResult{"Task managers", CheckWarn, message}
+	}
+	return CheckResult{"Task managers", CheckPass, message}
+}
+
+func (o RealOperator) checkJobs(jobs []flink.Job) CheckResult {
+	restarting := []string{}
+	failed := []string{}
+	for _, job := range jobs {
+		switch job.Status {
+		case "RESTARTING":
+			restarting = append(restarting, job.Name)
+		case "FAILED":
+			failed = append(failed, job.Name)
+		}
+	}
+
+	if len(restarting) > 0 {
+		return CheckResult{"Jobs", CheckFail, fmt.Sprintf("jobs restarting: %v", strings.Join(restarting, ", "))}
+	}
+	if len(failed) > 0 {
+		return CheckResult{"Jobs", CheckWarn, fmt.Sprintf("jobs failed: %v", strings.Join(failed, ", "))}
+	}
+	return CheckResult{"Jobs", CheckPass, fmt.Sprintf("%v jobs, none restarting or failed", len(jobs))}
+}
+
+func (o RealOperator) checkCheckpoints(ctx context.Context, job flink.Job, slowCheckpointSeconds int) CheckResult {
+	name := fmt.Sprintf("Checkpoints of %v", job.Name)
+
+	statistics, err := o.FlinkRestAPI.RetrieveCheckpoints(ctx, job.ID)
+	if err != nil {
+		return CheckResult{name, CheckFail, fmt.Sprintf("retrieving the checkpoints failed: %v", err)}
+	}
+
+	latest := statistics.Latest
+	if latest.Failed != nil && (latest.Completed == nil || latest.Failed.TriggerTimestamp > latest.Completed.TriggerTimestamp) {
+		return CheckResult{name, CheckFail, fmt.Sprintf("the latest checkpoint %v failed: %v", latest.Failed.ID, latest.Failed.FailureMessage)}
+	}
+	if statistics.Counts.Completed == 0 {
+		return CheckResult{name, CheckWarn, "no completed checkpoints"}
+	}
+
+	average := statistics.Summary.EndToEndDuration.Avg / 1000
+	if average > int64(slowCheckpointSeconds) {
+		return CheckResult{name, CheckWarn, fmt.Sprintf("checkpoints take %v seconds on average", average)}
+	}
+	if statistics.Counts.Failed > 0 {
+		return CheckResult{name, CheckWarn, fmt.Sprintf("%v of %v checkpoints failed", statistics.Counts.Failed, statistics.Counts.Total)}
+	}
+	return CheckResult{name, CheckPass, fmt.Sprintf("%v checkpoints completed, %v seconds on average", statistics.Counts.Completed, average)}
+}
+
+func (o RealOperator) checkBackPressure(ctx context.Context, job flink.Job) CheckResult {
+	name := fmt.Sprintf("Back pressure of %v", job.Name)
+
+	plan, err := o.FlinkRestAPI.RetrieveJobPlan(ctx, job.ID)
+	if err != nil {
+		return CheckResult{name, CheckFail, fmt.Sprintf("retrieving the plan failed: %v", err)}
+	}
+
+	high := []string{}
+	sampled := 0
+	for _, node := range plan.Nodes {
+		backPressure, err := o.FlinkRestAPI.RetrieveBackPressure(ctx, job.ID, node.ID)
+		if err != nil {
+			return CheckResult{name, CheckFail, fmt.Sprintf("retrieving the back pressure failed: %v", err)}
+		}
+		if backPressure.Status != "ok" {
+			continue
+		}
+		sampled++
+		if backPressure.BackPressureLevel == "high" {
+			high = append(high, planNodeName(node))
+		}
+	}
+
+	if len(high) > 0 {
+		return CheckResult{name, CheckWarn, fmt.Sprintf("high back pressure on: %v", strings.Join(high, ", "))}
+	}
+	if sampled == 0 {
+		return CheckResult{name, CheckPass, "sampling triggered, no samples available yet"}
+	}
+	return CheckResult{name, CheckPass, fmt.Sprintf("no high back pressure on %v sampled vertices", sampled)}
+}
+
+func (o RealOperator) checkSavepointDir(dir string) CheckResult {
+	files, err := afero.ReadDir(o.Filesystem, dir)
+	if err != nil {
+		return CheckResult{"Savepoint directory", CheckFail, fmt.Sprintf("%v is not readable: %v", dir, err)}
+	}
+	return CheckResult{"Savepoint directory", CheckPass, fmt.Sprintf("%v is readable and contains %v entries", dir, len(files))}
+}
+
+// Doctor checks the health of the Flink cluster and its running jobs
+func (o RealOperator) Doctor(ctx context.Context, d Doctor) []CheckResult {
+	slowCheckpointSeconds := d.SlowCheckpointSeconds
+	if slowCheckpointSeconds <= 0 {
+		slowCheckpointSeconds = defaultSlowCheckpointSeconds
+	}
+
+	results := []CheckResult{o.checkReachability(ctx)}
+	if results[0].Status == CheckFail {
+		return results
+	}
+
+	results = append(results, o.checkTaskManagers(ctx))
+
+	jobs, err := o.FlinkRestAPI.RetrieveJobs(ctx)
+	if err != nil {
+		results = append(results, CheckResult{"Jobs", CheckFail, fmt.Sprintf("retrieving jobs failed: %v", err)})
+	} else {
+		matchingJobs := []flink.Job{}
+		for _, job := range jobs {
+			if strings.HasPrefix(job.Name, d.JobNameBase) {
+				matchingJobs = append(matchingJobs, job)
+			}
+		}
+
+		results = append(results, o.checkJobs(matchingJobs))
+		for _, job := range o.filterRunningJobsByName(matchingJobs, d.JobNameBase) {
+			results = append(results, o.checkCheckpoints(ctx, job, slowCheckpointSeconds))
+			results = append(results, o.checkBackPressure(ctx, job))
+		}
+	}
+
+	if len(d.SavepointDir) > 0 {
+		results = append(results, o.checkSavepointDir(d.SavepointDir))
+	}
+
+	return results
+}
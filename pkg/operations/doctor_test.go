@@ -0,0 +1,181 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * Failed
+ */
+func TestFailedShouldOnlyReturnTrueWhenACheckFailed(t *testing.T) {
+	assert.False(t, Failed([]CheckResult{CheckResult{Status: CheckPass}, CheckResult{Status: CheckWarn}}))
+	assert.True(t, Failed([]CheckResult{CheckResult{Status: CheckPass}, CheckResult{Status: CheckFail}}))
+}
+
+/*
+ * checkJobs
+ */
+func TestCheckJobsShouldFailWhenAJobIsRestarting(t *testing.T) {
+	operator := RealOperator{}
+
+	result := operator.checkJobs([]flink.Job{
+		flink.Job{Name: "Job A", Status: "FAILED"},
+		flink.Job{Name: "Job B", Status: "RESTARTING"},
+	})
+
+	assert.Equal(t, CheckResult{"Jobs", CheckFail, "jobs restarting: Job B"}, result)
+}
+
+func TestCheckJobsShouldWarnWhenAJobHasFailed(t *testing.T) {
+	operator := RealOperator{}
+
+	result := operator.checkJobs([]flink.Job{
+		flink.Job{Name: "Job A", Status: "FAILED"},
+	})
+
+	assert.Equal(t, CheckResult{"Jobs", CheckWarn, "jobs failed: Job A"}, result)
+}
+
+/*
+ * checkCheckpoints
+ */
+func TestCheckCheckpointsShouldFailWhenTheLatestCheckpointFailed(t *testing.T) {
+	mockedRetrieveCheckpointsError = nil
+	mockedRetrieveCheckpointsResponse = flink.CheckpointStatistics{
+		Counts: flink.CheckpointCounts{Total: 2, Completed: 1, Failed: 1},
+		Latest: flink.LatestCheckpoints{
+			Completed: &flink.Checkpoint{ID: 1, TriggerTimestamp: 1},
+			Failed:    &flink.Checkpoint{ID: 2, TriggerTimestamp: 2, FailureMessage: "timeout"},
+		},
+	}
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	result := operator.checkCheckpoints(context.Background(), flink.Job{ID: "1", Name: "Job A"}, 60)
+
+	assert.Equal(t, CheckResult{"Checkpoints of Job A", CheckFail, "the latest checkpoint 2 failed: timeout"}, result)
+}
+
+func TestCheckCheckpointsShouldWarnWhenCheckpointsAreSlow(t *testing.T) {
+	mockedRetrieveCheckpointsError = nil
+	mockedRetrieveCheckpointsResponse = flink.CheckpointStatistics{
+		Counts: flink.CheckpointCounts{Total: 1, Completed: 1},
+		Summary: flink.CheckpointSummary{
+			EndToEndDuration: flink.CheckpointDurationSummary{Avg: 90000},
+		},
+		Latest: flink.LatestCheckpoints{
+			Completed: &flink.Checkpoint{ID: 1, TriggerTimestamp: 1},
+		},
+	}
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	result := operator.checkCheckpoints(context.Background(), flink.Job{ID: "1", Name: "Job A"}, 60)
+
+	assert.Equal(t, CheckResult{"Checkpoints of Job A", CheckWarn, "checkpoints take 90 seconds on average"}, result)
+}
+
+/*
+ * checkBackPressure
+ */
+func TestCheckBackPressureShouldWarnOnHighBackPressure(t *testing.T) {
+	mockedRetrieveJobPlanError = nil
+	mockedRetrieveJobPlanResponse = testPlan
+	mockedRetrieveBackPressureError = nil
+	mockedRetrieveBackPressureResponse = flink.VertexBackPressure{
+		Status:            "ok",
+		BackPressureLevel: "high",
+	}
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	result := operator.checkBackPressure(context.Background(), flink.Job{ID: "1", Name: "Job A"})
+
+	assert.Equal(t, CheckWarn, result.Status)
+	assert.Equal(t, "high back pressure on: Keyed Aggregation -> Sink: Print to Std. Out, Source: Custom Source", result.Message)
+}
+
+/*
+ * checkSavepointDir
+ */
+func TestCheckSavepointDirShouldFailWhenTheDirectoryIsNotReadable(t *testing.T) {
+	operator := RealOperator{
+		Filesystem: afero.NewMemMapFs(),
+	}
+
+	result := operator.checkSavepointDir("/data/flink")
+
+	assert.Equal(t, CheckFail, result.Status)
+}
+
+func TestCheckSavepointDirShouldPassWhenTheDirectoryIsReadable(t *testing.T) {
+	filesystem := afero.NewMemMapFs()
+	filesystem.MkdirAll("/data/flink/savepoint-1", 0755)
+
+	operator := RealOperator{
+		Filesystem: filesystem,
+	}
+
+	result := operator.checkSavepointDir("/data/flink")
+
+	assert.Equal(t, CheckResult{"Savepoint directory", CheckPass, "/data/flink is readable and contains 1 entries"}, result)
+}
+
+/*
+ * Doctor
+ */
+func TestDoctorShouldStopWhenTheClusterIsUnreachable(t *testing.T) {
+	mockedRetrieveClusterOverviewError = errors.New("connection refused")
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	results := operator.Doctor(context.Background(), Doctor{})
+
+	assert.Equal(t, []CheckResult{CheckResult{"REST API", CheckFail, "the cluster is unreachable: connection refused"}}, results)
+}
+
+func TestDoctorShouldCheckTheRunningJobs(t *testing.T) {
+	mockClusterCapacity(4, 2)
+	mockedRetrieveClusterOverviewResponse.FlinkVersion = "1.7.2"
+	mockedRetrieveJobsError = nil
+	mockedRetrieveJobsResponse = []flink.Job{
+		flink.Job{ID: "1", Name: "Job A", Status: "RUNNING"},
+	}
+	mockedRetrieveCheckpointsError = nil
+	mockedRetrieveCheckpointsResponse = flink.CheckpointStatistics{
+		Counts: flink.CheckpointCounts{Total: 1, Completed: 1},
+	}
+	mockedRetrieveJobPlanError = nil
+	mockedRetrieveJobPlanResponse = testPlan
+	mockedRetrieveBackPressureError = nil
+	mockedRetrieveBackPressureResponse = flink.VertexBackPressure{Status: "deprecated"}
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	results := operator.Doctor(context.Background(), Doctor{})
+
+	assert.Equal(t, []CheckResult{
+		CheckResult{"REST API", CheckPass, "reachable, Flink version 1.7.2"},
+		CheckResult{"Task managers", CheckPass, "1 task managers with 2 of 4 slots available"},
+		CheckResult{"Jobs", CheckPass, "1 jobs, none restarting or failed"},
+		CheckResult{"Checkpoints of Job A", CheckPass, "1 checkpoints completed, 0 seconds on average"},
+		CheckResult{"Back pressure of Job A", CheckPass, "sampling triggered, no samples available yet"},
+	}, results)
+	assert.False(t, Failed(results))
+}
@@ -0,0 +1,49 @@
+package operations
+
+import "time"
+
+// An EventType identifies a step of an operation that was reached
+type EventType string
+
+// The events reported to the Observer while operations progress
+const (
+	EventSavepointStarted   EventType = "savepoint-started"
+	EventSavepointCompleted EventType = "savepoint-completed"
+	EventJobCancelled       EventType = "job-cancelled"
+	EventJarUploaded        EventType = "jar-uploaded"
+	EventJobRunning         EventType = "job-running"
+)
+
+// An Event reports the progress of an operation. Only the
+// fields relevant to the type of the event are set.
+type Event struct {
+	Type          EventType
+	Time          time.Time
+	JobID         string
+	JarID         string
+	SavepointPath string
+}
+
+// An Observer is notified of the events of the operations. Events are
+// delivered synchronously, so observers shouldn't block.
+type Observer interface {
+	OnEvent(event Event)
+}
+
+// ObserverFunc is an adapter to use a function as an Observer
+type ObserverFunc func(event Event)
+
+// OnEvent calls the function with the event
+func (f ObserverFunc) OnEvent(event Event) {
+	f(event)
+}
+
+// notify reports the event to the observer, when there is one
+func (o RealOperator) notify(event Event) {
+	if o.Observer == nil {
+		return
+	}
+
+	event.Time = time.Now()
+	o.Observer.OnEvent(event)
+}
@@ -0,0 +1,221 @@
+package operations
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/ing-bank/flink-deployer/pkg/artifact"
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * Fake Flink cluster
+ */
+
+// newFakeClusterOperator creates an operator sending real requests to the
+// server, which doesn't share any state with other tests and can run in parallel
+func newFakeClusterOperator(server *httptest.Server, filesystem afero.Fs) RealOperator {
+	client := retryablehttp.NewClient()
+	client.Logger = nil
+
+	return RealOperator{
+		Filesystem: filesystem,
+		FlinkRestAPI: flink.NewVersionedFlinkRestClient(flink.FlinkRestClient{
+			BaseURL: server.URL,
+			Client:  client,
+		}),
+		HTTP: artifact.HTTP{Client: server.Client()},
+	}
+}
+
+// createTestDir creates a temporary directory, which the test removes
+func createTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "operations")
+	assert.Nil(t, err)
+	return dir
+}
+
+func readTestFile(t *testing.T, path string) string {
+	content, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	return string(content[:])
+}
+
+// testSource is an artifact source which supports all references
+type testSource struct{}
+
+func (testSource) Supports(reference string) bool {
+	return true
+}
+
+func (testSource) Fetch(ctx context.Context, reference string, dir string) (string, error) {
+	path := filepath.Join(dir, "job.jar")
+	return path, ioutil.WriteFile(path, []byte("custom"), 0644)
+}
+
+// testJar returns the content of a JAR file with the manifest and classes
+func testJar(t *testing.T, manifest string, classes ...string) []byte {
+	content := new(bytes.Buffer)
+	archive := zip.NewWriter(content)
+	w, _ := archive.Create("META-INF/MANIFEST.MF")
+	w.Write([]byte(manifest))
+	for _, class := range classes {
+		archive.Create(strings.Replace(class, ".", "/", -1) + ".class")
+	}
+	assert.Nil(t, archive.Close())
+	return content.Bytes()
+}
+
+// wordCountJar returns the content of a JAR file running com.example.WordCount
+func wordCountJar(t *testing.T) []byte {
+	return testJar(t, "Main-Class: com.example.WordCount\n", "com.example.WordCount")
+}
+
+// createTestJarFile creates a JAR file on disk to upload, which is removed by the cleanup function
+func createTestJarFile(t *testing.T, name string) (string, func()) {
+	return writeTestJarFile(t, name, wordCountJar(t))
+}
+
+// createTestJarArchive writes a JAR file with the manifest and classes
+func createTestJarArchive(t *testing.T, manifest string, classes ...string) (string, func()) {
+	return writeTestJarFile(t, "WordCount.jar", testJar(t, manifest, classes...))
+}
+
+func writeTestJarFile(t *testing.T, name string, content []byte) (string, func()) {
+	dir, err := ioutil.TempDir("", "operations")
+	assert.Nil(t, err)
+
+	filename := filepath.Join(dir, name)
+	err = ioutil.WriteFile(filename, content, 0644)
+	assert.Nil(t, err)
+
+	return filename, func() { os.RemoveAll(dir) }
+}
+
+/*
+ * Flink REST API mocking
+ */
+
+var mockedTerminateError error
+var mockedStopJobResponse flink.CreateSavepointResponse
+var mockedStopJobError error
+var mockedCreateSavepointResponse flink.CreateSavepointResponse
+var mockedCreateSavepointError error
+var mockedMonitorSavepointCreationResponse flink.MonitorSavepointCreationResponse
+var mockedMonitorSavepointCreationError error
+var mockedRetrieveJobsResponse []flink.Job
+var mockedRetrieveJobsError error
+var mockedRunJarResponse flink.RunJarResponse
+var mockedRunJarError error
+
+var mockedCheckRunJarOptionsError error
+var mockedUploadJarResponse flink.UploadJarResponse
+var mockedUploadJarError error
+var mockedDeleteJarError error
+var mockedRetrieveJobPlanResponse flink.Plan
+var mockedRetrieveJobPlanError error
+var mockedRetrieveJarPlanResponse flink.Plan
+var mockedRetrieveJarPlanError error
+var mockedRetrieveClusterOverviewResponse flink.ClusterOverview
+var mockedRetrieveClusterOverviewError error
+var mockedRetrieveTaskManagersResponse []flink.TaskManager
+var mockedRetrieveTaskManagersError error
+var mockedRescaleResponse flink.RescaleResponse
+var mockedRescaleError error
+var mockedMonitorRescalingResponse flink.MonitorRescalingResponse
+var mockedMonitorRescalingError error
+var mockedRetrieveJobExceptionsResponse flink.JobExceptions
+var mockedRetrieveJobExceptionsError error
+var mockedRetrieveJobManagerLogResponse string
+var mockedRetrieveJobManagerLogError error
+var mockedRetrieveTaskManagerLogResponse string
+var mockedRetrieveTaskManagerLogError error
+var mockedRetrieveCheckpointsResponse flink.CheckpointStatistics
+var mockedRetrieveCheckpointsError error
+var mockedRetrieveBackPressureResponse flink.VertexBackPressure
+var mockedRetrieveBackPressureError error
+
+type TestFlinkRestClient struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+func (c TestFlinkRestClient) Terminate(ctx context.Context, jobID string, mode string) error {
+	return mockedTerminateError
+}
+
+func (c TestFlinkRestClient) StopJob(ctx context.Context, jobID string, targetDirectory string) (flink.CreateSavepointResponse, error) {
+	return mockedStopJobResponse, mockedStopJobError
+}
+func (c TestFlinkRestClient) CreateSavepoint(ctx context.Context, jobID string, savepointPath string) (flink.CreateSavepointResponse, error) {
+	return mockedCreateSavepointResponse, mockedCreateSavepointError
+}
+func (c TestFlinkRestClient) MonitorSavepointCreation(ctx context.Context, jobID string, requestID string) (flink.MonitorSavepointCreationResponse, error) {
+	return mockedMonitorSavepointCreationResponse, mockedMonitorSavepointCreationError
+}
+func (c TestFlinkRestClient) RetrieveJobs(ctx context.Context) ([]flink.Job, error) {
+	return mockedRetrieveJobsResponse, mockedRetrieveJobsError
+}
+func (c TestFlinkRestClient) RunJar(ctx context.Context, jarID string, entryClass string, jarArgs []string, parallelism int, savepointPath string, allowNonRestoredState bool, options flink.RunJarOptions) (flink.RunJarResponse, error) {
+	return mockedRunJarResponse, mockedRunJarError
+}
+func (c TestFlinkRestClient) CheckRunJarOptions(ctx context.Context, options flink.RunJarOptions) error {
+	return mockedCheckRunJarOptionsError
+}
+func (c TestFlinkRestClient) UploadJar(ctx context.Context, filename string) (flink.UploadJarResponse, error) {
+	return mockedUploadJarResponse, mockedUploadJarError
+}
+func (c TestFlinkRestClient) DeleteJar(ctx context.Context, jarID string) error {
+	return mockedDeleteJarError
+}
+func (c TestFlinkRestClient) RetrieveJobPlan(ctx context.Context, jobID string) (flink.Plan, error) {
+	return mockedRetrieveJobPlanResponse, mockedRetrieveJobPlanError
+}
+func (c TestFlinkRestClient) RetrieveJarPlan(ctx context.Context, jarID string, entryClass string, jarArgs []string, parallelism int) (flink.Plan, error) {
+	return mockedRetrieveJarPlanResponse, mockedRetrieveJarPlanError
+}
+func (c TestFlinkRestClient) RetrieveClusterOverview(ctx context.Context) (flink.ClusterOverview, error) {
+	return mockedRetrieveClusterOverviewResponse, mockedRetrieveClusterOverviewError
+}
+func (c TestFlinkRestClient) RetrieveTaskManagers(ctx context.Context) ([]flink.TaskManager, error) {
+	return mockedRetrieveTaskManagersResponse, mockedRetrieveTaskManagersError
+}
+func (c TestFlinkRestClient) Rescale(ctx context.Context, jobID string, parallelism int) (flink.RescaleResponse, error) {
+	return mockedRescaleResponse, mockedRescaleError
+}
+func (c TestFlinkRestClient) MonitorRescaling(ctx context.Context, jobID string, requestID string) (flink.MonitorRescalingResponse, error) {
+	return mockedMonitorRescalingResponse, mockedMonitorRescalingError
+}
+func (c TestFlinkRestClient) RetrieveJobExceptions(ctx context.Context, jobID string) (flink.JobExceptions, error) {
+	return mockedRetrieveJobExceptionsResponse, mockedRetrieveJobExceptionsError
+}
+func (c TestFlinkRestClient) RetrieveJobManagerLog(ctx context.Context, lines int) (string, error) {
+	return mockedRetrieveJobManagerLogResponse, mockedRetrieveJobManagerLogError
+}
+func (c TestFlinkRestClient) RetrieveTaskManagerLog(ctx context.Context, taskManagerID string, lines int) (string, error) {
+	return mockedRetrieveTaskManagerLogResponse, mockedRetrieveTaskManagerLogError
+}
+func (c TestFlinkRestClient) RetrieveCheckpoints(ctx context.Context, jobID string) (flink.CheckpointStatistics, error) {
+	return mockedRetrieveCheckpointsResponse, mockedRetrieveCheckpointsError
+}
+func (c TestFlinkRestClient) RetrieveBackPressure(ctx context.Context, jobID string, vertexID string) (flink.VertexBackPressure, error) {
+	return mockedRetrieveBackPressureResponse, mockedRetrieveBackPressureError
+}
+
+func constructTestClient() flink.FlinkRestAPI {
+	return TestFlinkRestClient{
+		BaseURL: "http://localhost",
+		Client:  &http.Client{},
+	}
+}
@@ -0,0 +1,135 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ing-bank/flink-deployer/pkg/artifact"
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// A jarSource specifies the JAR file of a job, either as a local file, a
+// remote file or an artifact reference, with the SHA-256 checksum and the
+// detached signature it's verified with and the entry class it must contain
+type jarSource struct {
+	LocalFilename  string
+	RemoteFilename string
+	APIToken       string
+	APITokenPreset string
+	Artifact       string
+	SHA256         string
+	Signature      string
+	EntryClass     string
+}
+
+// artifactSources returns the Sources followed by the built-in sources
+func (o RealOperator) artifactSources() artifact.Sources {
+	sources := append(artifact.Sources{}, o.Sources...)
+	return append(sources, o.Maven, o.S3, o.OCI, o.HTTP)
+}
+
+// downloadJar downloads the remote file or the artifact of the source
+// into the directory, unless it's cached, and returns the path of the file
+func (o RealOperator) downloadJar(ctx context.Context, source jarSource, dir string) (string, error) {
+	if o.Cache == nil {
+		return o.fetchJar(ctx, source, dir)
+	}
+
+	reference := source.Artifact
+	if len(source.RemoteFilename) > 0 {
+		reference = source.RemoteFilename
+	}
+	return o.Cache.Fetch(reference, dir, func(dir string) (string, error) {
+		return o.fetchJar(ctx, source, dir)
+	})
+}
+
+// fetchJar downloads the remote file or the artifact of the source
+// into the directory and returns the path of the downloaded file
+func (o RealOperator) fetchJar(ctx context.Context, source jarSource, dir string) (string, error) {
+	if len(source.RemoteFilename) > 0 {
+		http := o.HTTP
+		if len(source.APIToken) > 0 {
+			authenticator, err := artifact.Preset(source.APITokenPreset, source.APIToken)
+			if err != nil {
+				return "", err
+			}
+			http = http.WithAuthenticator(authenticator)
+		}
+		return http.Download(ctx, source.RemoteFilename, dir)
+	}
+
+	path, err := o.artifactSources().Fetch(ctx, source.Artifact, dir)
+	if err != nil {
+		return "", flink.Errorf("retrieving artifact \"%v\" failed: %v", source.Artifact, err)
+	}
+	return path, nil
+}
+
+// verifyJar verifies the SHA-256 checksum and the detached signature of
+// the JAR file, when they're specified, and enforces the verification
+// policy. The signature is a local file or an artifact reference.
+func (o RealOperator) verifyJar(ctx context.Context, source jarSource, filename string) error {
+	if len(source.SHA256) > 0 {
+		err := artifact.VerifySHA256(filename, source.SHA256)
+		if err != nil {
+			return err
+		}
+		o.logf("Verified the SHA-256 checksum of the JAR file")
+	}
+
+	if len(source.Signature) == 0 {
+		if o.Verification.RequireSignature {
+			return errors.New("the verification policy requires a signed JAR file, but no signature is specified")
+		}
+		return nil
+	}
+
+	signaturePath := source.Signature
+	if strings.Contains(signaturePath, "://") || o.artifactSources().Supports(signaturePath) {
+		dir, err := ioutil.TempDir("", "flink-deployer")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+
+		signaturePath, err = o.downloadJar(ctx, jarSource{Artifact: source.Signature}, dir)
+		if err != nil {
+			return flink.Errorf("retrieving the signature failed: %v", err)
+		}
+	}
+	signature, err := ioutil.ReadFile(signaturePath)
+	if err != nil {
+		return err
+	}
+
+	err = o.Verification.Keys.VerifySignature(filename, signature)
+	if err != nil {
+		return err
+	}
+	o.logf("Verified the signature of the JAR file")
+	return nil
+}
+
+// inspectJar checks that the JAR file can be read and that the entry class
+// of the source, or the entry class of the manifest, exists in the JAR file
+func (o RealOperator) inspectJar(source jarSource, filename string) error {
+	jar, err := artifact.InspectJar(filename)
+	if err != nil {
+		return err
+	}
+
+	if version := jar.Version(); len(version) > 0 {
+		o.logf("Detected JAR file version %v", version)
+	}
+
+	entryClass, err := jar.ValidateEntryClass(source.EntryClass)
+	if err != nil {
+		return err
+	}
+	o.logf("Using entry class %v", entryClass)
+	return nil
+}
@@ -0,0 +1,191 @@
+package operations
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ing-bank/flink-deployer/pkg/artifact"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * downloadJar
+ */
+func TestDownloadJarShouldSendTheAPITokenOfThePreset(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("Expected GET request, got ‘%s’", r.Method)
+		}
+		if r.Header.Get("X-JFrog-Art-Api") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("TESTTHIS"))
+	}))
+	defer ts.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	operator := RealOperator{}
+	path, err := operator.downloadJar(context.Background(), jarSource{RemoteFilename: ts.URL + "/job.jar", APIToken: "key", APITokenPreset: "artifactory"}, dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "TESTTHIS", readTestFile(t, path))
+}
+
+func TestDownloadJarShouldSendTheGitLabTokenByDefault(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "header" {
+			t.Errorf("Expected certain header, got ‘%s’", r.Header)
+		}
+		w.Write([]byte("TESTTHIS"))
+	}))
+	defer ts.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	operator := RealOperator{}
+	path, err := operator.downloadJar(context.Background(), jarSource{RemoteFilename: ts.URL, APIToken: "header"}, dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "TESTTHIS", readTestFile(t, path))
+}
+
+func TestDownloadJarUsesTheClientOfTheHTTPSource(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("TESTTLS"))
+	}))
+	defer ts.Close()
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	operator := RealOperator{HTTP: artifact.HTTP{Client: ts.Client()}}
+	path, err := operator.downloadJar(context.Background(), jarSource{RemoteFilename: ts.URL + "/job.jar"}, dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "TESTTLS", readTestFile(t, path))
+}
+
+func TestDownloadJarShouldReturnAnErrorForAnUnknownPreset(t *testing.T) {
+	operator := RealOperator{}
+
+	_, err := operator.downloadJar(context.Background(), jarSource{RemoteFilename: "https://example.com/job.jar", APIToken: "token", APITokenPreset: "bitbucket"}, "/tmp")
+
+	assert.EqualError(t, err, "unknown authentication preset \"bitbucket\", only 'gitlab', 'github', 'artifactory' and 'bearer' are supported")
+}
+
+func TestDownloadJarShouldPreferTheCustomSources(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	operator := RealOperator{Sources: artifact.Sources{testSource{}}}
+	path, err := operator.downloadJar(context.Background(), jarSource{Artifact: "https://example.com/job.jar"}, dir)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "custom", readTestFile(t, path))
+}
+
+func TestDownloadJarShouldUseTheCachedFileOfTheRemoteFile(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("jar"))
+	}))
+	defer ts.Close()
+	cacheDir := createTestDir(t)
+	defer os.RemoveAll(cacheDir)
+
+	operator := RealOperator{Cache: &artifact.Cache{Dir: cacheDir, MaxAge: time.Hour}}
+	for i := 0; i < 2; i++ {
+		dir := createTestDir(t)
+		defer os.RemoveAll(dir)
+
+		path, err := operator.downloadJar(context.Background(), jarSource{RemoteFilename: ts.URL + "/job.jar"}, dir)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "jar", readTestFile(t, path))
+	}
+	assert.Equal(t, 1, requests)
+}
+
+/*
+ * verifyJar
+ */
+// signTestJar writes the JAR file and returns its cosign-style signature
+// and the key it's verified with
+func signTestJar(t *testing.T, path string, content string) (string, artifact.TrustedKeys) {
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	assert.Nil(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	digest := sha256.Sum256([]byte(content))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	assert.Nil(t, err)
+	signature, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	assert.Nil(t, err)
+	return base64.StdEncoding.EncodeToString(signature), artifact.TrustedKeys{{Key: &key.PublicKey}}
+}
+
+func TestVerifyJarShouldRejectAnotherChecksum(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "job.jar")
+	ioutil.WriteFile(path, []byte("jar"), 0644)
+
+	err := RealOperator{}.verifyJar(context.Background(), jarSource{SHA256: strings.Repeat("0", 64)}, path)
+
+	assert.True(t, strings.HasPrefix(err.Error(), "the SHA-256 checksum of "+path+" is "))
+}
+
+func TestVerifyJarShouldVerifyARemoteSignature(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "job.jar")
+	signature, keys := signTestJar(t, path, "jar")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(signature))
+	}))
+	defer ts.Close()
+
+	operator := RealOperator{Verification: artifact.VerificationPolicy{Keys: keys, RequireSignature: true}}
+	err := operator.verifyJar(context.Background(), jarSource{Signature: ts.URL + "/job.jar.sig"}, path)
+
+	assert.Nil(t, err)
+}
+
+func TestVerifyJarShouldRejectATamperedJarFile(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "job.jar")
+	signature, keys := signTestJar(t, path, "jar")
+	ioutil.WriteFile(path, []byte("tampered"), 0644)
+	ioutil.WriteFile(path+".sig", []byte(signature), 0644)
+
+	operator := RealOperator{Verification: artifact.VerificationPolicy{Keys: keys}}
+	err := operator.verifyJar(context.Background(), jarSource{Signature: path + ".sig"}, path)
+
+	assert.EqualError(t, err, "the signature of "+path+" doesn't match any of the trusted keys")
+}
+
+func TestVerifyJarShouldRequireASignatureWhenThePolicyDoes(t *testing.T) {
+	operator := RealOperator{Verification: artifact.VerificationPolicy{RequireSignature: true}}
+
+	err := operator.verifyJar(context.Background(), jarSource{}, "job.jar")
+
+	assert.EqualError(t, err, "the verification policy requires a signed JAR file, but no signature is specified")
+}
@@ -0,0 +1,65 @@
+package operations
+
+import (
+	"context"
+	"log"
+
+	"github.com/ing-bank/flink-deployer/pkg/artifact"
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/ing-bank/flink-deployer/pkg/secret"
+	"github.com/spf13/afero"
+)
+
+// Operator is an interface which contains all the functionality
+// that the deployer exposes. Operations stop at the next safe point
+// when their context is done.
+type Operator interface {
+	Deploy(ctx context.Context, d Deploy) (DeployResult, error)
+	Update(ctx context.Context, u UpdateJob) (UpdateResult, error)
+	RetrieveJobs(ctx context.Context) ([]flink.Job, error)
+	Terminate(ctx context.Context, t TerminateJob) error
+	Plan(ctx context.Context, p Plan) (flink.Plan, error)
+	Rescale(ctx context.Context, r Rescale) error
+	Exceptions(ctx context.Context, e Exceptions) (flink.JobExceptions, error)
+	Logs(ctx context.Context, l Logs) ([]LogFile, error)
+	Doctor(ctx context.Context, d Doctor) []CheckResult
+}
+
+// RealOperator is the Operator used in the production code.
+// HTTP downloads remote JAR files, and together with Maven, S3 and OCI
+// the artifacts. The Sources take precedence over these built-in sources for
+// the artifact references they support. The Cache, when set, keeps the
+// downloaded files for the next deployments. The Verification determines
+// which JAR files may be uploaded. The Policies determine how long
+// asynchronous operations are awaited and the Observer, when set, is
+// notified of the progress of the operations. The Logger, when set,
+// receives the progress of the operations as log messages.
+type RealOperator struct {
+	Filesystem   afero.Fs
+	FlinkRestAPI flink.FlinkRestAPI
+	HTTP         artifact.HTTP
+	Maven        artifact.Maven
+	S3           artifact.S3
+	OCI          artifact.OCI
+	Sources      artifact.Sources
+	Cache        *artifact.Cache
+	Verification artifact.VerificationPolicy
+	Policies     flink.Policies
+	Observer     Observer
+	Logger       *log.Logger
+	// Secrets resolves the secret references in the program arguments
+	// and adds the resolved secrets to its Redactor
+	Secrets secret.Resolver
+}
+
+// logf logs to the Logger of the operator, unless it's nil
+func (o RealOperator) logf(format string, v ...interface{}) {
+	logf(o.Logger, format, v...)
+}
+
+// logf logs to the logger, unless it's nil
+func logf(logger *log.Logger, format string, v ...interface{}) {
+	if logger != nil {
+		logger.Printf(format, v...)
+	}
+}
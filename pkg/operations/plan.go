@@ -0,0 +1,246 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// Plan represents the configuration used for
+// retrieving the job graph of a running job or a JAR file
+type Plan struct {
+	JobID          string
+	JobNameBase    string
+	JarID          string
+	LocalFilename  string
+	RemoteFilename string
+	APIToken       string
+	APITokenPreset string
+	Artifact       string
+	SHA256         string
+	Signature      string
+	EntryClass     string
+	Parallelism    int
+	ProgramArgs    []string
+}
+
+// The supported output formats for rendering a plan
+const (
+	PlanFormatASCII   = "ascii"
+	PlanFormatDot     = "dot"
+	PlanFormatMermaid = "mermaid"
+)
+
+func (p Plan) countSources() (count int) {
+	for _, source := range []string{p.JobID, p.JobNameBase, p.JarID, p.LocalFilename, p.RemoteFilename, p.Artifact} {
+		if len(source) > 0 {
+			count++
+		}
+	}
+	return
+}
+
+// Plan retrieves the job graph of either a running job or a JAR file
+func (o RealOperator) Plan(ctx context.Context, p Plan) (flink.Plan, error) {
+	switch p.countSources() {
+	case 0:
+		return flink.Plan{}, errors.New("one of the properties 'JobID', 'JobNameBase', 'JarID', 'LocalFilename', 'RemoteFilename' or 'Artifact' must be specified")
+	case 1:
+	default:
+		return flink.Plan{}, errors.New("only one of the properties 'JobID', 'JobNameBase', 'JarID', 'LocalFilename', 'RemoteFilename' or 'Artifact' may be specified")
+	}
+
+	if len(p.JobID) > 0 {
+		return o.FlinkRestAPI.RetrieveJobPlan(ctx, p.JobID)
+	}
+
+	if len(p.JobNameBase) > 0 {
+		jobs, err := o.FlinkRestAPI.RetrieveJobs(ctx)
+		if err != nil {
+			return flink.Plan{}, flink.Errorf("retrieving jobs failed: %v", err)
+		}
+
+		runningJobs := o.filterRunningJobsByName(jobs, p.JobNameBase)
+		if len(runningJobs) != 1 {
+			return flink.Plan{}, fmt.Errorf("job name with base \"%v\" has %v instances running, expected exactly 1", p.JobNameBase, len(runningJobs))
+		}
+
+		return o.FlinkRestAPI.RetrieveJobPlan(ctx, runningJobs[0].ID)
+	}
+
+	programArgs, err := o.Secrets.Substitute(ctx, p.ProgramArgs)
+	if err != nil {
+		return flink.Plan{}, err
+	}
+	p.ProgramArgs = programArgs
+
+	if len(p.JarID) > 0 {
+		return o.FlinkRestAPI.RetrieveJarPlan(ctx, p.JarID, p.EntryClass, p.ProgramArgs, p.Parallelism)
+	}
+
+	jarID, err := o.uploadJar(ctx, jarSource{
+		LocalFilename:  p.LocalFilename,
+		RemoteFilename: p.RemoteFilename,
+		APIToken:       p.APIToken,
+		APITokenPreset: p.APITokenPreset,
+		Artifact:       p.Artifact,
+		SHA256:         p.SHA256,
+		Signature:      p.Signature,
+		EntryClass:     p.EntryClass,
+	})
+	if err != nil {
+		return flink.Plan{}, err
+	}
+
+	plan, err := o.FlinkRestAPI.RetrieveJarPlan(ctx, jarID, p.EntryClass, p.ProgramArgs, p.Parallelism)
+
+	// The JAR file was only uploaded to build the plan, so remove it again
+	deleteErr := o.FlinkRestAPI.DeleteJar(ctx, jarID)
+	if deleteErr != nil {
+		o.logf("failed to delete JAR file \"%v\": %v", jarID, deleteErr)
+	}
+
+	return plan, err
+}
+
+// RenderPlan renders the job graph in the requested output format
+func RenderPlan(plan flink.Plan, format string) (string, error) {
+	nodes := sortPlanNodes(plan.Nodes)
+
+	switch format {
+	case "", PlanFormatASCII:
+		return renderPlanASCII(plan, nodes), nil
+	case PlanFormatDot:
+		return renderPlanDot(plan, nodes), nil
+	case PlanFormatMermaid:
+		return renderPlanMermaid(nodes), nil
+	default:
+		return "", fmt.Errorf("unknown plan format \"%v\", only '%v', '%v' and '%v' are supported", format, PlanFormatASCII, PlanFormatDot, PlanFormatMermaid)
+	}
+}
+
+// sortPlanNodes orders the nodes so that every node appears after its inputs.
+// Ties keep the order of the API response so the output is stable between runs.
+func sortPlanNodes(nodes []flink.PlanNode) []flink.PlanNode {
+	placed := make(map[string]bool)
+	known := make(map[string]bool)
+	for _, node := range nodes {
+		known[node.ID] = true
+	}
+
+	sorted := make([]flink.PlanNode, 0, len(nodes))
+	for len(sorted) < len(nodes) {
+		progress := false
+		for _, node := range nodes {
+			if placed[node.ID] {
+				continue
+			}
+			ready := true
+			for _, input := range node.Inputs {
+				if known[input.ID] && !placed[input.ID] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				placed[node.ID] = true
+				sorted = append(sorted, node)
+				progress = true
+			}
+		}
+
+		// Iterative jobs contain cycles, append the remaining nodes as-is
+		if !progress {
+			for _, node := range nodes {
+				if !placed[node.ID] {
+					placed[node.ID] = true
+					sorted = append(sorted, node)
+				}
+			}
+		}
+	}
+
+	return sorted
+}
+
+// planNodeName turns the HTML formatted description Flink
+// returns into a single line operator name
+func planNodeName(node flink.PlanNode) string {
+	name := node.Description
+	if len(name) == 0 {
+		name = node.Operator
+	}
+	name = strings.Replace(name, "<br/>", " ", -1)
+	return strings.TrimSpace(html.UnescapeString(name))
+}
+
+func renderPlanASCII(plan flink.Plan, nodes []flink.PlanNode) string {
+	names := make(map[string]string)
+	for _, node := range nodes {
+		names[node.ID] = planNodeName(node)
+	}
+
+	buffer := &bytes.Buffer{}
+	if len(plan.Name) > 0 {
+		fmt.Fprintf(buffer, "%v\n", plan.Name)
+	}
+	for _, node := range nodes {
+		fmt.Fprintf(buffer, "[%v] %v\n", node.ID, names[node.ID])
+		fmt.Fprintf(buffer, "    parallelism: %v\n", node.Parallelism)
+		for _, input := range node.Inputs {
+			fmt.Fprintf(buffer, "    <-- %v -- [%v] %v\n", input.ShipStrategy, input.ID, names[input.ID])
+		}
+	}
+	return buffer.String()
+}
+
+func renderPlanDot(plan flink.Plan, nodes []flink.PlanNode) string {
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace
+
+	buffer := &bytes.Buffer{}
+	fmt.Fprintf(buffer, "digraph \"%v\" {\n", escape(plan.Name))
+	fmt.Fprintln(buffer, "\trankdir=LR;")
+	fmt.Fprintln(buffer, "\tnode [shape=box];")
+	for _, node := range nodes {
+		fmt.Fprintf(buffer, "\t\"%v\" [label=\"%v\\nid: %v\\nparallelism: %v\"];\n", escape(node.ID), escape(planNodeName(node)), escape(node.ID), node.Parallelism)
+	}
+	for _, node := range nodes {
+		for _, input := range node.Inputs {
+			fmt.Fprintf(buffer, "\t\"%v\" -> \"%v\" [label=\"%v\"];\n", escape(input.ID), escape(node.ID), escape(input.ShipStrategy))
+		}
+	}
+	fmt.Fprintln(buffer, "}")
+	return buffer.String()
+}
+
+func renderPlanMermaid(nodes []flink.PlanNode) string {
+	escape := strings.NewReplacer(`"`, "#quot;", "|", "#124;").Replace
+
+	aliases := make(map[string]string)
+	for i, node := range nodes {
+		aliases[node.ID] = fmt.Sprintf("n%v", i)
+	}
+	alias := func(id string) string {
+		if a, ok := aliases[id]; ok {
+			return a
+		}
+		return id
+	}
+
+	buffer := &bytes.Buffer{}
+	fmt.Fprintln(buffer, "graph LR")
+	for _, node := range nodes {
+		fmt.Fprintf(buffer, "\t%v[\"%v<br/>id: %v<br/>parallelism: %v\"]\n", alias(node.ID), escape(planNodeName(node)), escape(node.ID), node.Parallelism)
+	}
+	for _, node := range nodes {
+		for _, input := range node.Inputs {
+			fmt.Fprintf(buffer, "\t%v -->|%v| %v\n", alias(input.ID), escape(input.ShipStrategy), alias(node.ID))
+		}
+	}
+	return buffer.String()
+}
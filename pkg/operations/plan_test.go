@@ -0,0 +1,173 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/stretchr/testify/assert"
+)
+
+var testPlan = flink.Plan{
+	JobID: "job-1",
+	Name:  "Windowed WordCount",
+	Nodes: []flink.PlanNode{
+		flink.PlanNode{
+			ID:          "sink",
+			Parallelism: 2,
+			Description: "Keyed Aggregation -&gt; Sink: Print to Std. Out",
+			Inputs: []flink.PlanInput{
+				flink.PlanInput{
+					ID:           "source",
+					ShipStrategy: "HASH",
+				},
+			},
+		},
+		flink.PlanNode{
+			ID:          "source",
+			Parallelism: 1,
+			Description: "Source: Custom Source",
+		},
+	},
+}
+
+/*
+ * Plan
+ */
+func TestPlanShouldReturnAnErrorWhenNoSourceIsSpecified(t *testing.T) {
+	operator := RealOperator{}
+
+	_, err := operator.Plan(context.Background(), Plan{})
+
+	assert.EqualError(t, err, "one of the properties 'JobID', 'JobNameBase', 'JarID', 'LocalFilename', 'RemoteFilename' or 'Artifact' must be specified")
+}
+
+func TestPlanShouldReturnAnErrorWhenMultipleSourcesAreSpecified(t *testing.T) {
+	operator := RealOperator{}
+
+	_, err := operator.Plan(context.Background(), Plan{
+		JobID: "job-1",
+		JarID: "jar-1",
+	})
+
+	assert.EqualError(t, err, "only one of the properties 'JobID', 'JobNameBase', 'JarID', 'LocalFilename', 'RemoteFilename' or 'Artifact' may be specified")
+}
+
+func TestPlanShouldReturnAnErrorWhenTheJobNameBaseMatchesNoRunningJob(t *testing.T) {
+	mockedRetrieveJobsError = nil
+	mockedRetrieveJobsResponse = []flink.Job{}
+
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	_, err := operator.Plan(context.Background(), Plan{
+		JobNameBase: "WordCountStateful",
+	})
+
+	assert.EqualError(t, err, "job name with base \"WordCountStateful\" has 0 instances running, expected exactly 1")
+}
+
+func TestPlanShouldReturnThePlanOfTheRunningJob(t *testing.T) {
+	mockedRetrieveJobsError = nil
+	mockedRetrieveJobsResponse = []flink.Job{
+		flink.Job{
+			ID:     "job-1",
+			Name:   "WordCountStateful v1.0",
+			Status: "RUNNING",
+		},
+	}
+	mockedRetrieveJobPlanResponse = testPlan
+	mockedRetrieveJobPlanError = nil
+
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	plan, err := operator.Plan(context.Background(), Plan{
+		JobNameBase: "WordCountStateful",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, testPlan, plan)
+}
+
+func TestPlanShouldReturnThePlanOfAnUploadedJarEvenIfDeletingItFails(t *testing.T) {
+	mockedUploadJarResponse = flink.UploadJarResponse{
+		Filename: "/data/flink/sample.jar",
+		Status:   "success",
+	}
+	mockedUploadJarError = nil
+	mockedRetrieveJarPlanResponse = testPlan
+	mockedRetrieveJarPlanError = nil
+	mockedDeleteJarError = errors.New("failed")
+
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	plan, err := operator.Plan(context.Background(), Plan{
+		LocalFilename: "testdata/sample.jar",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, testPlan, plan)
+}
+
+/*
+ * RenderPlan
+ */
+func TestRenderPlanShouldReturnAnErrorForAnUnknownFormat(t *testing.T) {
+	_, err := RenderPlan(testPlan, "svg")
+
+	assert.EqualError(t, err, "unknown plan format \"svg\", only 'ascii', 'dot' and 'mermaid' are supported")
+}
+
+func TestRenderPlanShouldRenderASCIIWithTheSourcesFirst(t *testing.T) {
+	res, err := RenderPlan(testPlan, PlanFormatASCII)
+
+	assert.Nil(t, err)
+	assert.Equal(t, `Windowed WordCount
+[source] Source: Custom Source
+    parallelism: 1
+[sink] Keyed Aggregation -> Sink: Print to Std. Out
+    parallelism: 2
+    <-- HASH -- [source] Source: Custom Source
+`, res)
+}
+
+func TestRenderPlanShouldRenderDot(t *testing.T) {
+	res, err := RenderPlan(testPlan, PlanFormatDot)
+
+	assert.Nil(t, err)
+	assert.Equal(t, `digraph "Windowed WordCount" {
+	rankdir=LR;
+	node [shape=box];
+	"source" [label="Source: Custom Source\nid: source\nparallelism: 1"];
+	"sink" [label="Keyed Aggregation -> Sink: Print to Std. Out\nid: sink\nparallelism: 2"];
+	"source" -> "sink" [label="HASH"];
+}
+`, res)
+}
+
+func TestRenderPlanShouldRenderMermaid(t *testing.T) {
+	res, err := RenderPlan(testPlan, PlanFormatMermaid)
+
+	assert.Nil(t, err)
+	assert.Equal(t, `graph LR
+	n0["Source: Custom Source<br/>id: source<br/>parallelism: 1"]
+	n1["Keyed Aggregation -> Sink: Print to Std. Out<br/>id: sink<br/>parallelism: 2"]
+	n0 -->|HASH| n1
+`, res)
+}
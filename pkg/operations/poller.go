@@ -0,0 +1,129 @@
+package operations
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// A Poller polls the status of an asynchronous operation with an
+// exponential backoff until it completes or the timeout expires.
+// Without a timeout it waits up to flink.DefaultWaitTimeout.
+type Poller struct {
+	Timeout         time.Duration
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	// Logger, when set, receives the errors of the checks which are retried
+	Logger *log.Logger
+}
+
+// A pollTimeoutError is returned when the operation didn't complete
+// before the timeout expired, and contains the last error of the check
+type pollTimeoutError struct {
+	last error
+}
+
+func (e pollTimeoutError) Error() string {
+	return e.last.Error()
+}
+
+// permanent marks an error returned by a poll check as permanent,
+// which stops polling immediately
+func permanent(err error) error {
+	return &backoff.PermanentError{Err: err}
+}
+
+// newPoller creates the Poller for the wait timeout
+// and poll intervals of the operation's policy
+func (o RealOperator) newPoller(operation string) Poller {
+	policy := o.Policies.Get(operation)
+	return Poller{
+		Timeout:         policy.WaitTimeout,
+		InitialInterval: policy.PollInterval,
+		MaxInterval:     policy.PollMaxInterval,
+		Logger:          o.Logger,
+	}
+}
+
+// Poll calls the check until it returns nil. Errors returned by the check are
+// logged and retried, unless they are marked as permanent. When the timeout
+// expires, a pollTimeoutError containing the last error is returned. Polling
+// stops with a canceled error as soon as the context is done.
+func (p Poller) Poll(ctx context.Context, check func() error) error {
+	var failure error
+	op := func() error {
+		err := check()
+		if err == nil {
+			return nil
+		}
+		if permanentErr, ok := err.(*backoff.PermanentError); ok {
+			failure = permanentErr.Err
+			return err
+		}
+		logf(p.Logger, "%v", err)
+		return err
+	}
+
+	err := backoff.Retry(op, backoff.WithContext(p.backOff(), ctx))
+	if err == nil {
+		return nil
+	}
+	if failure != nil {
+		return failure
+	}
+	if ctx.Err() != nil {
+		return canceledErrorf(ctx, "stopped waiting: %v", err)
+	}
+	return pollTimeoutError{last: err}
+}
+
+// backOff returns the exponential backoff of the poller, which falls back
+// to the default intervals and wait timeout for the ones that aren't set
+func (p Poller) backOff() *backoff.ExponentialBackOff {
+	b := &backoff.ExponentialBackOff{
+		InitialInterval:     p.InitialInterval,
+		RandomizationFactor: backoff.DefaultRandomizationFactor,
+		Multiplier:          backoff.DefaultMultiplier,
+		MaxInterval:         p.MaxInterval,
+		MaxElapsedTime:      p.Timeout,
+		Clock:               backoff.SystemClock,
+	}
+	if b.InitialInterval <= 0 {
+		b.InitialInterval = backoff.DefaultInitialInterval
+	}
+	if b.MaxInterval <= 0 {
+		b.MaxInterval = backoff.DefaultMaxInterval
+	}
+	// A zero MaxElapsedTime makes the backoff retry forever
+	if b.MaxElapsedTime <= 0 {
+		b.MaxElapsedTime = flink.DefaultWaitTimeout
+	}
+	return b
+}
+
+// isPollTimeout returns whether polling stopped because the timeout expired
+func isPollTimeout(err error) bool {
+	_, ok := err.(pollTimeoutError)
+	return ok
+}
+
+// canceledErrorf returns an error in the category matching the reason the
+// context is done, which is a timeout when its deadline was exceeded
+func canceledErrorf(ctx context.Context, format string, args ...interface{}) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return flink.CategorizedErrorf(flink.ErrTimeout, format, args...)
+	}
+	return flink.CategorizedErrorf(flink.ErrCanceled, format, args...)
+}
+
+// timeoutErrorf returns a timeout error when polling stopped because the
+// timeout expired, or the original error otherwise
+func timeoutErrorf(err error, format string, args ...interface{}) error {
+	if isPollTimeout(err) {
+		return flink.CategorizedErrorf(flink.ErrTimeout, format, args...)
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollShouldRetryUntilTheCheckSucceeds(t *testing.T) {
+	calls := 0
+	poller := Poller{Timeout: time.Second, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond}
+
+	err := poller.Poll(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("pending")
+		}
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestPollShouldStopOnAPermanentError(t *testing.T) {
+	calls := 0
+	poller := Poller{Timeout: time.Second, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond}
+
+	err := poller.Poll(context.Background(), func() error {
+		calls++
+		return permanent(errors.New("failed"))
+	})
+
+	assert.EqualError(t, err, "failed")
+	assert.False(t, isPollTimeout(err))
+	assert.Equal(t, 1, calls)
+}
+
+func TestPollShouldReturnATimeoutErrorWhenTheTimeoutExpires(t *testing.T) {
+	poller := Poller{Timeout: 10 * time.Millisecond, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond}
+
+	err := poller.Poll(context.Background(), func() error {
+		return errors.New("pending")
+	})
+
+	assert.EqualError(t, err, "pending")
+	assert.True(t, isPollTimeout(err))
+	assert.Equal(t, flink.ErrTimeout, flink.Category(timeoutErrorf(err, "timed out")))
+}
+
+func TestNewPollerShouldUseThePolicyOfTheOperation(t *testing.T) {
+	operator := RealOperator{
+		Policies: flink.Policies{
+			flink.OperationSavepoint: flink.Policy{WaitTimeout: time.Hour, PollInterval: time.Second, PollMaxInterval: time.Minute},
+		},
+	}
+
+	poller := operator.newPoller(flink.OperationSavepoint)
+
+	assert.Equal(t, Poller{Timeout: time.Hour, InitialInterval: time.Second, MaxInterval: time.Minute}, poller)
+}
+
+func TestPollerShouldUseTheDefaultWaitTimeoutWithoutATimeout(t *testing.T) {
+	poller := Poller{}
+
+	b := poller.backOff()
+
+	assert.Equal(t, flink.DefaultWaitTimeout, b.MaxElapsedTime)
+}
+
+func TestPollShouldStopWhenTheContextIsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	poller := Poller{Timeout: time.Minute, InitialInterval: time.Millisecond, MaxInterval: time.Millisecond}
+
+	err := poller.Poll(ctx, func() error {
+		cancel()
+		return errors.New("pending")
+	})
+
+	assert.EqualError(t, err, "stopped waiting: pending")
+	assert.Equal(t, flink.ErrCanceled, flink.Category(err))
+}
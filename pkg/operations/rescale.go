@@ -0,0 +1,212 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// Rescale represents the configuration used for
+// changing the parallelism of a job on the Flink cluster.
+// The JarID and SavepointDir are required when the cluster
+// doesn't support rescaling and the job is restarted instead.
+type Rescale struct {
+	JobNameBase           string
+	Parallelism           int
+	JarID                 string
+	EntryClass            string
+	ProgramArgs           []string
+	SavepointDir          string
+	AllowNonRestoredState bool
+}
+
+func (o RealOperator) monitorRescaling(ctx context.Context, jobID string, requestID string, poller Poller) error {
+	err := poller.Poll(ctx, func() error {
+		o.logf("checking status of rescaling")
+		res, err := o.FlinkRestAPI.MonitorRescaling(ctx, jobID, requestID)
+		if err != nil {
+			return err
+		}
+
+		switch res.Status.Id {
+		case "COMPLETED":
+			if res.Operation != nil && res.Operation.FailureCause != nil {
+				return permanent(fmt.Errorf("rescaling job \"%v\" failed due to: %v", jobID, res.Operation.FailureCause.Class))
+			}
+			return nil
+		case "IN_PROGRESS":
+			return fmt.Errorf("rescaling job \"%v\" is still pending", jobID)
+		default:
+			return fmt.Errorf("rescaling job \"%v\" returned an unknown status \"%v\"", jobID, res.Status.Id)
+		}
+	})
+	return timeoutErrorf(err, "failed to rescale job \"%v\" within %v seconds", jobID, poller.Timeout.Seconds())
+}
+
+// rescaledTo returns whether the plan of the job runs with the parallelism, compared
+// to the plan before rescaling. Vertices with a parallelism set in the job itself,
+// like a sink with a parallelism of 1, are pinned and keep their parallelism.
+// All other vertices must run with the parallelism, and at least one of them must
+// have changed, unless the job already ran with the parallelism before.
+func rescaledTo(before flink.Plan, after flink.Plan, parallelism int) bool {
+	previous := map[string]int{}
+	unchanged := true
+	for _, node := range before.Nodes {
+		previous[node.ID] = node.Parallelism
+		if node.Parallelism != parallelism {
+			unchanged = false
+		}
+	}
+
+	rescaled := false
+	for _, node := range after.Nodes {
+		switch {
+		case node.Parallelism == parallelism && previous[node.ID] != parallelism:
+			rescaled = true
+		case node.Parallelism == parallelism, node.Parallelism == previous[node.ID]:
+		default:
+			return false
+		}
+	}
+
+	return rescaled || unchanged
+}
+
+// verifyParallelism waits until a job with the given name is running with
+// the expected parallelism, compared to the plan of the job before rescaling
+func (o RealOperator) verifyParallelism(ctx context.Context, jobName string, parallelism int, before flink.Plan, poller Poller) error {
+	err := poller.Poll(ctx, func() error {
+		jobs, err := o.FlinkRestAPI.RetrieveJobs(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, job := range jobs {
+			if job.Status != "RUNNING" || job.Name != jobName {
+				continue
+			}
+
+			plan, err := o.FlinkRestAPI.RetrieveJobPlan(ctx, job.ID)
+			if err != nil {
+				return flink.Errorf("retrieving the plan of job \"%v\" failed: %v", job.ID, err)
+			}
+			if rescaledTo(before, plan, parallelism) {
+				o.logf("job \"%v\" is running with parallelism %v", job.ID, parallelism)
+				return nil
+			}
+		}
+
+		return fmt.Errorf("job \"%v\" is not yet running with parallelism %v", jobName, parallelism)
+	})
+	return timeoutErrorf(err, "job \"%v\" did not run with parallelism %v within %v seconds", jobName, parallelism, poller.Timeout.Seconds())
+}
+
+// restartWithParallelism rescales a job by taking a savepoint, cancelling
+// the job and running the same JAR file again from that savepoint
+func (o RealOperator) restartWithParallelism(ctx context.Context, job flink.Job, r Rescale) error {
+	if len(r.JarID) == 0 {
+		return errors.New("unspecified argument 'JarID', required to restart the job when rescaling is not supported")
+	}
+	if len(r.SavepointDir) == 0 {
+		return errors.New("unspecified argument 'SavepointDir', required to restart the job when rescaling is not supported")
+	}
+	programArgs, err := o.Secrets.Substitute(ctx, r.ProgramArgs)
+	if err != nil {
+		return err
+	}
+	r.ProgramArgs = programArgs
+
+	// The JAR file is planned before the job is touched, so the
+	// job keeps running when it's missing or can't be planned
+	_, err = o.FlinkRestAPI.RetrieveJarPlan(ctx, r.JarID, r.EntryClass, r.ProgramArgs, r.Parallelism)
+	if err != nil {
+		return flink.Errorf("unable to restart the job from JAR \"%v\", the job is still running: %v", r.JarID, err)
+	}
+
+	o.logf("creating savepoint for job \"%v\"", job.ID)
+	savepointResponse, err := o.FlinkRestAPI.CreateSavepoint(ctx, job.ID, r.SavepointDir)
+	if err != nil {
+		return flink.Errorf("failed to create savepoint for job %v due to error: %v", job.ID, err)
+	}
+	o.notify(Event{Type: EventSavepointStarted, JobID: job.ID})
+
+	savepointPath, err := o.monitorSavepointCreation(ctx, job.ID, savepointResponse.RequestID, o.newPoller(flink.OperationSavepoint))
+	if err != nil {
+		return err
+	}
+	if len(savepointPath) == 0 {
+		return fmt.Errorf("the savepoint of job \"%v\" completed without a location", job.ID)
+	}
+
+	err = o.safePoint(ctx, "rescaling stopped after creating a savepoint for job \"%v\" in %v, the job is still running", job.ID, r.SavepointDir)
+	if err != nil {
+		return err
+	}
+
+	// The job is about to be cancelled, so it is restarted
+	// even when the rescaling is stopped meanwhile
+	ctx = detach(ctx)
+	err = o.FlinkRestAPI.Terminate(ctx, job.ID, "cancel")
+	if err != nil {
+		return flink.Errorf("job \"%v\" failed to cancel due to: %v", job.ID, err)
+	}
+	o.notify(Event{Type: EventJobCancelled, JobID: job.ID})
+
+	o.logf("restarting JAR \"%v\" from savepoint %v with parallelism %v", r.JarID, savepointPath, r.Parallelism)
+	runResponse, err := o.FlinkRestAPI.RunJar(ctx, r.JarID, r.EntryClass, r.ProgramArgs, r.Parallelism, savepointPath, r.AllowNonRestoredState, flink.RunJarOptions{})
+	if err != nil {
+		return o.diagnoseFailure(ctx, "", flink.Errorf("job \"%v\" was cancelled, but failed to restart from savepoint %v, from which it can be restored manually: %v", job.ID, savepointPath, err))
+	}
+	o.notify(Event{Type: EventJobRunning, JobID: runResponse.JobID, JarID: r.JarID, SavepointPath: savepointPath})
+
+	return nil
+}
+
+// Rescale changes the parallelism of a running job on the Flink cluster. When the
+// cluster does not support rescaling, the job is restarted from a savepoint instead.
+func (o RealOperator) Rescale(ctx context.Context, r Rescale) error {
+	if len(r.JobNameBase) == 0 {
+		return errors.New("unspecified argument 'JobNameBase'")
+	}
+	if r.Parallelism <= 0 {
+		return errors.New("unspecified argument 'Parallelism'")
+	}
+
+	jobs, err := o.FlinkRestAPI.RetrieveJobs(ctx)
+	if err != nil {
+		return flink.Errorf("retrieving jobs failed: %v", err)
+	}
+
+	runningJobs := o.filterRunningJobsByName(jobs, r.JobNameBase)
+	if len(runningJobs) != 1 {
+		return fmt.Errorf("job name with base \"%v\" has %v instances running, expected exactly 1", r.JobNameBase, len(runningJobs))
+	}
+	job := runningJobs[0]
+
+	before, err := o.FlinkRestAPI.RetrieveJobPlan(ctx, job.ID)
+	if err != nil {
+		return flink.Errorf("retrieving the plan of job \"%v\" failed: %v", job.ID, err)
+	}
+
+	o.logf("rescaling job \"%v\" to parallelism %v", job.ID, r.Parallelism)
+	rescaleResponse, err := o.FlinkRestAPI.Rescale(ctx, job.ID, r.Parallelism)
+	switch {
+	case err == flink.ErrRescalingUnsupported:
+		o.logf("rescaling is not supported by the cluster, falling back to restarting the job from a savepoint")
+		err = o.restartWithParallelism(ctx, job, r)
+		if err != nil {
+			return err
+		}
+	case err != nil:
+		return flink.Errorf("failed to rescale job \"%v\" due to: %v", job.ID, err)
+	default:
+		err = o.monitorRescaling(ctx, job.ID, rescaleResponse.RequestID, o.newPoller(flink.OperationStart))
+		if err != nil {
+			return err
+		}
+	}
+
+	return o.verifyParallelism(ctx, job.Name, r.Parallelism, before, o.newPoller(flink.OperationStart))
+}
@@ -0,0 +1,267 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/ing-bank/flink-deployer/pkg/flinktest"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockRunningJobWithParallelism(parallelism int) {
+	mockedRetrieveJobsError = nil
+	mockedRetrieveJobsResponse = []flink.Job{
+		flink.Job{
+			ID:     "Job-A",
+			Name:   "WordCountStateful v1.0",
+			Status: "RUNNING",
+		},
+	}
+	mockedRetrieveJobPlanError = nil
+	mockedRetrieveJobPlanResponse = flink.Plan{
+		Nodes: []flink.PlanNode{
+			flink.PlanNode{
+				ID:          "source",
+				Parallelism: parallelism,
+			},
+		},
+	}
+}
+
+/*
+ * monitorRescaling
+ */
+func TestMonitorRescalingShouldReturnTheFailureCause(t *testing.T) {
+	mockedMonitorRescalingError = nil
+	mockedMonitorRescalingResponse = flink.MonitorRescalingResponse{
+		Status: flink.RescalingStatus{
+			Id: "COMPLETED",
+		},
+		Operation: &flink.RescalingOperation{
+			FailureCause: &flink.FailureCause{
+				Class: "java.lang.Exception",
+			},
+		},
+	}
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	err := operator.monitorRescaling(context.Background(), "job-id", "request-id", Poller{Timeout: time.Second})
+
+	assert.EqualError(t, err, "rescaling job \"job-id\" failed due to: java.lang.Exception")
+}
+
+func TestMonitorRescalingShouldReturnAnErrorWhenTheRescalingTimesOut(t *testing.T) {
+	mockedMonitorRescalingError = nil
+	mockedMonitorRescalingResponse = flink.MonitorRescalingResponse{
+		Status: flink.RescalingStatus{
+			Id: "IN_PROGRESS",
+		},
+	}
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	err := operator.monitorRescaling(context.Background(), "job-id", "request-id", Poller{Timeout: time.Second})
+
+	assert.EqualError(t, err, "failed to rescale job \"job-id\" within 1 seconds")
+}
+
+/*
+ * Rescale
+ */
+func TestRescaleShouldReturnAnErrorWhenTheJobNameBaseIsUndefined(t *testing.T) {
+	operator := RealOperator{}
+
+	err := operator.Rescale(context.Background(), Rescale{
+		Parallelism: 2,
+	})
+
+	assert.EqualError(t, err, "unspecified argument 'JobNameBase'")
+}
+
+func TestRescaleShouldReturnAnErrorWhenTheParallelismIsUndefined(t *testing.T) {
+	operator := RealOperator{}
+
+	err := operator.Rescale(context.Background(), Rescale{
+		JobNameBase: "WordCountStateful",
+	})
+
+	assert.EqualError(t, err, "unspecified argument 'Parallelism'")
+}
+
+func TestRescaleShouldReturnNilWhenTheJobIsRescaledInPlace(t *testing.T) {
+	mockRunningJobWithParallelism(4)
+	mockedRescaleError = nil
+	mockedRescaleResponse = flink.RescaleResponse{
+		RequestID: "request-id",
+	}
+	mockedMonitorRescalingError = nil
+	mockedMonitorRescalingResponse = flink.MonitorRescalingResponse{
+		Status: flink.RescalingStatus{
+			Id: "COMPLETED",
+		},
+	}
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	err := operator.Rescale(context.Background(), Rescale{
+		JobNameBase: "WordCountStateful",
+		Parallelism: 4,
+	})
+
+	assert.Nil(t, err)
+}
+
+func TestRescaleShouldReturnAnErrorWhenFallingBackWithoutAJarID(t *testing.T) {
+	mockRunningJobWithParallelism(2)
+	mockedRescaleError = flink.ErrRescalingUnsupported
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	err := operator.Rescale(context.Background(), Rescale{
+		JobNameBase:  "WordCountStateful",
+		Parallelism:  4,
+		SavepointDir: "/data/flink",
+	})
+
+	assert.EqualError(t, err, "unspecified argument 'JarID', required to restart the job when rescaling is not supported")
+}
+
+func TestRescaleShouldReturnAnErrorWhenTheFallbackCannotCreateASavepoint(t *testing.T) {
+	mockRunningJobWithParallelism(2)
+	mockedRescaleError = flink.ErrRescalingUnsupported
+	mockedRetrieveJarPlanError = nil
+	mockedCreateSavepointError = errors.New("failed")
+
+	operator := RealOperator{
+		FlinkRestAPI: constructTestClient(),
+	}
+
+	err := operator.Rescale(context.Background(), Rescale{
+		JobNameBase:  "WordCountStateful",
+		Parallelism:  4,
+		JarID:        "jar-id",
+		SavepointDir: "/data/flink",
+	})
+
+	assert.EqualError(t, err, "failed to create savepoint for job Job-A due to error: failed")
+}
+
+func TestRescaleShouldRestartTheJobFromTheCreatedSavepointOnAFakeCluster(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	filename, cleanup := createTestJarFile(t, "WordCount.jar")
+	defer cleanup()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	deployed, err := operator.Deploy(context.Background(), Deploy{LocalFilename: filename, Parallelism: 2})
+	assert.Nil(t, err)
+
+	err = operator.Rescale(context.Background(), Rescale{
+		JobNameBase:  "WordCount",
+		Parallelism:  4,
+		JarID:        deployed.JarID,
+		SavepointDir: "/savepoints",
+	})
+
+	assert.Nil(t, err)
+	savepoints := cluster.Savepoints()
+	assert.Len(t, savepoints, 1)
+	running := []flinktest.Job{}
+	for _, job := range cluster.Jobs() {
+		if job.Status == flinktest.StatusRunning {
+			running = append(running, job)
+		}
+	}
+	assert.Len(t, running, 1)
+	assert.Equal(t, 4, running[0].Parallelism)
+	assert.Equal(t, savepoints[0], running[0].SavepointPath)
+}
+
+func TestRescaleShouldKeepTheJobRunningWhenTheJarOfTheFallbackIsMissing(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	filename, cleanup := createTestJarFile(t, "WordCount.jar")
+	defer cleanup()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	deployed, err := operator.Deploy(context.Background(), Deploy{LocalFilename: filename, Parallelism: 2})
+	assert.Nil(t, err)
+
+	err = operator.Rescale(context.Background(), Rescale{
+		JobNameBase:  "WordCount",
+		Parallelism:  4,
+		JarID:        "missing.jar",
+		SavepointDir: "/savepoints",
+	})
+
+	assert.Contains(t, err.Error(), "unable to restart the job from JAR \"missing.jar\", the job is still running")
+	assert.Len(t, cluster.Savepoints(), 0)
+	job, _ := cluster.Job(deployed.JobID)
+	assert.Equal(t, flinktest.StatusRunning, job.Status)
+}
+
+func TestRescaleShouldReturnTheSavepointWhenTheJobFailsToRestart(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	filename, cleanup := createTestJarFile(t, "WordCount.jar")
+	defer cleanup()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	deployed, err := operator.Deploy(context.Background(), Deploy{LocalFilename: filename, Parallelism: 2})
+	assert.Nil(t, err)
+	cluster.InjectFailure(flinktest.Failure{Method: "POST", Path: "/jars/*/run", StatusCode: http.StatusBadRequest})
+
+	err = operator.Rescale(context.Background(), Rescale{
+		JobNameBase:  "WordCount",
+		Parallelism:  4,
+		JarID:        deployed.JarID,
+		SavepointDir: "/savepoints",
+	})
+
+	savepoints := cluster.Savepoints()
+	assert.Len(t, savepoints, 1)
+	assert.Contains(t, err.Error(), fmt.Sprintf("job \"%v\" was cancelled, but failed to restart from savepoint %v, from which it can be restored manually", deployed.JobID, savepoints[0]))
+}
+
+/*
+ * rescaledTo
+ */
+func TestRescaledToShouldIgnoreThePinnedVertices(t *testing.T) {
+	before := flink.Plan{Nodes: []flink.PlanNode{{ID: "source", Parallelism: 2}, {ID: "sink", Parallelism: 1}}}
+
+	assert.False(t, rescaledTo(before, before, 4))
+	assert.True(t, rescaledTo(before, flink.Plan{Nodes: []flink.PlanNode{{ID: "source", Parallelism: 4}, {ID: "sink", Parallelism: 1}}}, 4))
+	assert.False(t, rescaledTo(before, flink.Plan{Nodes: []flink.PlanNode{{ID: "source", Parallelism: 3}, {ID: "sink", Parallelism: 1}}}, 4))
+}
+
+func TestRescaledToShouldRequireAChangeWhenAPinnedVertexHasTheParallelism(t *testing.T) {
+	before := flink.Plan{Nodes: []flink.PlanNode{{ID: "source", Parallelism: 8}, {ID: "map", Parallelism: 2}}}
+
+	assert.False(t, rescaledTo(before, before, 8))
+	assert.True(t, rescaledTo(before, flink.Plan{Nodes: []flink.PlanNode{{ID: "source", Parallelism: 8}, {ID: "map", Parallelism: 8}}}, 8))
+}
+
+func TestRescaledToShouldAcceptAJobWhichAlreadyRunsWithTheParallelism(t *testing.T) {
+	before := flink.Plan{Nodes: []flink.PlanNode{{ID: "source", Parallelism: 4}}}
+
+	assert.True(t, rescaledTo(before, before, 4))
+}
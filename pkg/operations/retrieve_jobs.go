@@ -0,0 +1,13 @@
+package operations
+
+import (
+	"context"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// RetrieveJobs executes the logic required for retrieving
+// the jobs from a Flink cluster
+func (o RealOperator) RetrieveJobs(ctx context.Context) ([]flink.Job, error) {
+	return o.FlinkRestAPI.RetrieveJobs(ctx)
+}
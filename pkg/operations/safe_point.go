@@ -0,0 +1,53 @@
+package operations
+
+import (
+	"context"
+	"time"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// safePoint returns an error reporting where the operation stopped when
+// the context is done, or nil when the operation can continue
+func (o RealOperator) safePoint(ctx context.Context, format string, args ...interface{}) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+
+	err := canceledErrorf(ctx, format, args...)
+	o.logf("%v", err)
+	return err
+}
+
+// A detachedContext keeps the values of its parent but is never done. It is
+// used once a job has been cancelled, as stopping before the new job runs
+// would leave the cluster without a running instance of the job.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (c detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (c detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+// detach returns a context which ignores the cancellation of the context
+func detach(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+// isCanceled returns whether the error was caused by a done context
+func isCanceled(err error) bool {
+	return flink.Category(err) == flink.ErrCanceled
+}
@@ -0,0 +1,57 @@
+package operations
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// TerminateJob represents the configuration used for
+// terminate a job on the Flink cluster. The SavepointDir is where
+// the savepoint is taken when stopping the job, which defaults to
+// the default savepoint directory of the cluster.
+type TerminateJob struct {
+	JobNameBase  string
+	Mode         string
+	SavepointDir string
+}
+
+// Terminate executes the actual termination of a job on the Flink cluster
+func (o RealOperator) Terminate(ctx context.Context, t TerminateJob) error {
+	if len(t.JobNameBase) == 0 {
+		return errors.New("unspecified argument 'JobNameBase'")
+	}
+
+	if t.Mode == "stop" {
+		return o.stop(ctx, t)
+	}
+
+	err := o.FlinkRestAPI.Terminate(ctx, t.JobNameBase, t.Mode)
+	if err != nil {
+		return flink.Errorf("job \"%v\" failed to terminate due to: %v", t.JobNameBase, err)
+	}
+	o.notify(Event{Type: EventJobCancelled, JobID: t.JobNameBase})
+
+	return nil
+}
+
+// stop stops the job and, on clusters which take a savepoint while
+// stopping, waits until that savepoint is completed
+func (o RealOperator) stop(ctx context.Context, t TerminateJob) error {
+	res, err := o.FlinkRestAPI.StopJob(ctx, t.JobNameBase, t.SavepointDir)
+	if err != nil {
+		return flink.Errorf("job \"%v\" failed to terminate due to: %v", t.JobNameBase, err)
+	}
+	if len(res.RequestID) == 0 {
+		return nil
+	}
+	o.notify(Event{Type: EventSavepointStarted, JobID: t.JobNameBase})
+
+	_, err = o.monitorSavepointCreation(ctx, t.JobNameBase, res.RequestID, o.newPoller(flink.OperationStop))
+	if err != nil {
+		return flink.Errorf("job \"%v\" failed to stop due to: %v", t.JobNameBase, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package operations
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ing-bank/flink-deployer/pkg/flinktest"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * Terminate
+ */
+func TestTerminateShouldReturnAnErrorWhenTheJobNameBaseIsUndefined(t *testing.T) {
+	operator := RealOperator{FlinkRestAPI: constructTestClient()}
+
+	err := operator.Terminate(context.Background(), TerminateJob{Mode: "cancel"})
+
+	assert.EqualError(t, err, "unspecified argument 'JobNameBase'")
+}
+
+func TestTerminateShouldStopTheJobOnceTheSavepointIsCompletedOnAFakeCluster(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	jobID := cluster.StartJob("WordCount", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	err := operator.Terminate(context.Background(), TerminateJob{
+		JobNameBase:  jobID,
+		Mode:         "stop",
+		SavepointDir: "/data/flink/savepoints",
+	})
+
+	assert.Nil(t, err)
+	job, _ := cluster.Job(jobID)
+	assert.Equal(t, flinktest.StatusFinished, job.Status)
+	savepoints := cluster.Savepoints()
+	assert.Len(t, savepoints, 1)
+	assert.True(t, strings.HasPrefix(savepoints[0], "/data/flink/savepoints/"))
+}
+
+func TestTerminateShouldReturnAnErrorWhenTheSavepointOfTheStopFailsOnAFakeCluster(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	cluster.FailSavepoints("java.io.IOException: No space left on device")
+	jobID := cluster.StartJob("WordCount", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	err := operator.Terminate(context.Background(), TerminateJob{
+		JobNameBase:  jobID,
+		Mode:         "stop",
+		SavepointDir: "/data/flink/savepoints",
+	})
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "failed to stop due to")
+	job, _ := cluster.Job(jobID)
+	assert.Equal(t, flinktest.StatusRunning, job.Status)
+}
+
+func TestTerminateShouldNotRetryTheStopRequestOnAFakeCluster(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	cluster.InjectFailure(flinktest.Failure{Method: "POST", Path: "/jobs/*/stop", StatusCode: http.StatusServiceUnavailable, Times: 1})
+	jobID := cluster.StartJob("WordCount", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	err := operator.Terminate(context.Background(), TerminateJob{
+		JobNameBase:  jobID,
+		Mode:         "stop",
+		SavepointDir: "/data/flink/savepoints",
+	})
+
+	assert.NotNil(t, err)
+	stops := 0
+	for _, request := range cluster.Requests() {
+		if request == "POST /jobs/"+jobID+"/stop" {
+			stops++
+		}
+	}
+	assert.Equal(t, 1, stops)
+}
@@ -0,0 +1,263 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// UpdateJob represents the configuration used for
+// updating a job on the Flink cluster
+type UpdateJob struct {
+	JobNameBase           string
+	LocalFilename         string
+	RemoteFilename        string
+	APIToken              string
+	APITokenPreset        string
+	Artifact              string
+	SHA256                string
+	Signature             string
+	EntryClass            string
+	Parallelism           int
+	ProgramArgs           []string
+	SavepointDir          string
+	AllowNonRestoredState bool
+	FallbackToDeploy      bool
+	AutoParallelism       bool
+	ParallelismLimit      int
+	CheckCapacity         bool
+	CapacityTimeout       int
+	JobID                 string
+	RestoreMode           string
+	FlinkConfiguration    map[string]string
+}
+
+// UpdateResult describes the job started by an update and the job it
+// replaced, which is empty when the update fell back to a deployment
+type UpdateResult struct {
+	DeployResult
+	PreviousJobID string
+}
+
+func (o RealOperator) filterRunningJobsByName(jobs []flink.Job, jobNameBase string) (ret []flink.Job) {
+	for _, job := range jobs {
+		if job.Status == "RUNNING" && strings.HasPrefix(job.Name, jobNameBase) {
+			ret = append(ret, job)
+		}
+	}
+	return
+}
+
+// monitorSavepointCreation waits until the savepoint is created
+// and returns its location as reported by the cluster
+func (o RealOperator) monitorSavepointCreation(ctx context.Context, jobID string, requestID string, poller Poller) (string, error) {
+	var location string
+	err := poller.Poll(ctx, func() error {
+		o.logf("checking status of savepoint creation")
+		res, err := o.FlinkRestAPI.MonitorSavepointCreation(ctx, jobID, requestID)
+		if err != nil {
+			return err
+		}
+
+		switch res.Status.Id {
+		case "COMPLETED":
+			if res.Operation != nil && res.Operation.FailureCause != nil {
+				return permanent(fmt.Errorf("savepoint creation for job \"%v\" failed due to: %v", jobID, res.Operation.FailureCause.Class))
+			}
+			if res.Operation != nil {
+				location = res.Operation.Location
+			}
+			o.notify(Event{Type: EventSavepointCompleted, JobID: jobID, SavepointPath: location})
+			return nil
+		case "IN_PROGRESS":
+			return fmt.Errorf("savepoint creation for job \"%v\" is still pending", jobID)
+		default:
+			return fmt.Errorf("savepoint creation for job \"%v\" returned an unknown status \"%v\"", jobID, res.Status)
+		}
+	})
+	return location, timeoutErrorf(err, "failed to create savepoint for job \"%v\" within %v seconds", jobID, poller.Timeout.Seconds())
+}
+
+// Update executes the actual update of a job on the Flink cluster
+func (o RealOperator) Update(ctx context.Context, u UpdateJob) (UpdateResult, error) {
+	if len(u.JobNameBase) == 0 {
+		return UpdateResult{}, errors.New("unspecified argument 'JobNameBase'")
+	}
+	if len(u.SavepointDir) == 0 {
+		return UpdateResult{}, errors.New("unspecified argument 'SavepointDir'")
+	}
+
+	o.logf("starting job update for base name '%v' and savepoint dir '%v'\n", u.JobNameBase, u.SavepointDir)
+
+	deploy := Deploy{
+		LocalFilename:         u.LocalFilename,
+		RemoteFilename:        u.RemoteFilename,
+		APIToken:              u.APIToken,
+		APITokenPreset:        u.APITokenPreset,
+		Artifact:              u.Artifact,
+		SHA256:                u.SHA256,
+		Signature:             u.Signature,
+		EntryClass:            u.EntryClass,
+		Parallelism:           u.Parallelism,
+		ProgramArgs:           u.ProgramArgs,
+		AllowNonRestoredState: u.AllowNonRestoredState,
+		AutoParallelism:       u.AutoParallelism,
+		ParallelismLimit:      u.ParallelismLimit,
+		CheckCapacity:         u.CheckCapacity,
+		CapacityTimeout:       u.CapacityTimeout,
+		JobID:                 u.JobID,
+		RestoreMode:           u.RestoreMode,
+		FlinkConfiguration:    u.FlinkConfiguration,
+	}
+	if len(deploy.RemoteFilename) == 0 && len(deploy.LocalFilename) == 0 && len(deploy.Artifact) == 0 {
+		return UpdateResult{}, errors.New("the properties 'RemoteFilename', 'LocalFilename' and 'Artifact' are unspecified")
+	}
+
+	// The secrets are resolved before the running job is stopped
+	programArgs, err := o.Secrets.Substitute(ctx, u.ProgramArgs)
+	if err != nil {
+		return UpdateResult{}, err
+	}
+	deploy.ProgramArgs = programArgs
+
+	// The JAR file is downloaded, verified and inspected before any call
+	// to the cluster, so the running job is left alone when it's rejected
+	filename, cleanup, err := o.prepareJar(ctx, deploy.jarSource())
+	if err != nil {
+		return UpdateResult{}, err
+	}
+	defer cleanup()
+
+	// The running job is only stopped when the new job can be run with the options
+	err = o.FlinkRestAPI.CheckRunJarOptions(ctx, deploy.runJarOptions())
+	if err != nil {
+		return UpdateResult{}, err
+	}
+
+	jobs, err := o.FlinkRestAPI.RetrieveJobs(ctx)
+	if err != nil {
+		return UpdateResult{}, flink.Errorf("retrieving jobs failed: %v", err)
+	}
+
+	runningJobs := o.filterRunningJobsByName(jobs, u.JobNameBase)
+	result := UpdateResult{}
+
+	// The uploaded JAR file is removed again when the update stops
+	// before the running job is cancelled or the new job runs
+	keepJar := false
+	defer func() {
+		if keepJar || len(deploy.JarID) == 0 {
+			return
+		}
+		deleteErr := o.FlinkRestAPI.DeleteJar(detach(ctx), deploy.JarID)
+		if deleteErr != nil {
+			o.logf("failed to delete JAR file \"%v\": %v", deploy.JarID, deleteErr)
+		}
+	}()
+
+	switch len(runningJobs) {
+	case 0:
+		if u.FallbackToDeploy == false {
+			return UpdateResult{}, flink.CategorizedErrorf(flink.ErrNotFound, "no instance running for job name base \"%v\". Aborting update", u.JobNameBase)
+		}
+		o.logf("no instance running for job name base \"%v\". Falling back to deploy", u.JobNameBase)
+
+		deploy.JarID, err = o.uploadFile(ctx, filename)
+		if err != nil {
+			return UpdateResult{}, err
+		}
+	case 1:
+		o.logf("found exactly 1 running job with base name: \"%v\"", u.JobNameBase)
+		job := runningJobs[0]
+
+		// Verify the capacity before touching the running job. The slots
+		// of the running job are released once it has been cancelled.
+		if u.AutoParallelism == true || u.CheckCapacity == true {
+			releasedSlots, err := o.retrieveSlotsUsedByJob(ctx, job.ID)
+			if err != nil {
+				return UpdateResult{}, err
+			}
+
+			if u.AutoParallelism == true {
+				deploy.Parallelism, err = o.resolveAutoParallelism(ctx, u.ParallelismLimit, releasedSlots)
+				if err != nil {
+					return UpdateResult{}, err
+				}
+				deploy.AutoParallelism = false
+			}
+
+			if u.CheckCapacity == true {
+				err = o.waitForCapacity(ctx, deploy.Parallelism, releasedSlots, u.CapacityTimeout)
+				if err != nil {
+					return UpdateResult{}, err
+				}
+				deploy.CheckCapacity = false
+			}
+		}
+
+		// The JAR file is uploaded before the savepoint,
+		// so the job keeps running when it's rejected
+		deploy.JarID, err = o.uploadFile(ctx, filename)
+		if err != nil {
+			return UpdateResult{}, err
+		}
+
+		err = o.safePoint(ctx, "update stopped before creating a savepoint for job \"%v\", the job is still running", job.ID)
+		if err != nil {
+			return UpdateResult{}, err
+		}
+
+		o.logf("creating savepoint for job \"%v\"", job.ID)
+		savepointResponse, err := o.FlinkRestAPI.CreateSavepoint(ctx, job.ID, u.SavepointDir)
+		if err != nil {
+			return UpdateResult{}, o.diagnoseFailure(ctx, job.ID, flink.Errorf("failed to create savepoint for job %v due to error: %v", job.ID, err))
+		}
+		o.notify(Event{Type: EventSavepointStarted, JobID: job.ID})
+
+		_, err = o.monitorSavepointCreation(ctx, job.ID, savepointResponse.RequestID, o.newPoller(flink.OperationSavepoint))
+		if isCanceled(err) {
+			return UpdateResult{}, flink.Errorf("update stopped while waiting for the savepoint of job \"%v\", the job is still running: %v", job.ID, err)
+		}
+		if err != nil {
+			return UpdateResult{}, o.diagnoseFailure(ctx, job.ID, err)
+		}
+
+		err = o.safePoint(ctx, "update stopped after creating a savepoint for job \"%v\" in %v, the job is still running", job.ID, u.SavepointDir)
+		if err != nil {
+			return UpdateResult{}, err
+		}
+
+		// The job is about to be cancelled, so the update continues
+		// until the new job runs even when it is stopped meanwhile
+		ctx = detach(ctx)
+		err = o.FlinkRestAPI.Terminate(ctx, job.ID, "cancel")
+		if err != nil {
+			return UpdateResult{}, o.diagnoseFailure(ctx, job.ID, flink.Errorf("job \"%v\" failed to cancel due to: %v", job.ID, err))
+		}
+		keepJar = true
+		o.notify(Event{Type: EventJobCancelled, JobID: job.ID})
+		result.PreviousJobID = job.ID
+
+		latestSavepoint, err := o.retrieveLatestSavepoint(u.SavepointDir)
+		if err != nil {
+			return UpdateResult{}, flink.Errorf("retrieving the latest savepoint failed: %v", err)
+		}
+
+		if len(latestSavepoint) != 0 {
+			deploy.SavepointPath = latestSavepoint
+		}
+	default:
+		return UpdateResult{}, fmt.Errorf("job name with base \"%v\" has %v instances running. Aborting update", u.JobNameBase, len(runningJobs))
+	}
+
+	result.DeployResult, err = o.deploy(ctx, deploy, true)
+	if err != nil {
+		return UpdateResult{}, err
+	}
+	keepJar = true
+
+	return result, nil
+}
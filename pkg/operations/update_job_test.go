@@ -0,0 +1,811 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/ing-bank/flink-deployer/pkg/flinktest"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * filterRunningJobsByName
+ */
+func TestFilterRunningJobsShouldReturnAnEmptySliceIfNoJobsWereProvided(t *testing.T) {
+	operator := RealOperator{}
+
+	res := operator.filterRunningJobsByName([]flink.Job{}, "test")
+
+	assert.Len(t, res, 0)
+}
+
+func TestFilterRunningJobsShouldReturnAnEmptySliceIfNoJobsAreRunning(t *testing.T) {
+	operator := RealOperator{}
+
+	res := operator.filterRunningJobsByName(
+		[]flink.Job{
+			flink.Job{
+				Status: "STOPPED",
+			},
+		},
+		"test",
+	)
+	assert.Len(t, res, 0)
+}
+
+func TestFilterRunningJobsShouldReturnTheRunningJobs(t *testing.T) {
+	operator := RealOperator{}
+
+	res := operator.filterRunningJobsByName(
+		[]flink.Job{
+			flink.Job{
+				Status: "STOPPED",
+				Name:   "test",
+			},
+			flink.Job{
+				Status: "RUNNING",
+				Name:   "test",
+			},
+		},
+		"test",
+	)
+
+	assert.Len(t, res, 1)
+}
+
+func TestFilterRunningJobsShouldReturnTheRunningJobsMatchingTheJobBaseName(t *testing.T) {
+	operator := RealOperator{}
+
+	res := operator.filterRunningJobsByName(
+		[]flink.Job{
+			flink.Job{
+				Status: "STOPPED",
+				Name:   "jobA v1.0",
+			},
+			flink.Job{
+				Status: "RUNNING",
+				Name:   "JobB",
+			},
+			flink.Job{
+				Status: "RUNNING",
+				Name:   "jobA v1.1",
+			},
+		},
+		"jobA",
+	)
+
+	assert.Len(t, res, 1)
+}
+
+/*
+ * monitorSavepointCreation
+ */
+func TestMonitorSavepointCreationShouldReturnAnErrorWhenTheSavepointFailsToBeCreated(t *testing.T) {
+	mockedMonitorSavepointCreationError = errors.New("failed")
+
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	_, err := operator.monitorSavepointCreation(context.Background(), "job-id", "request-id", Poller{Timeout: time.Second})
+
+	assert.EqualError(t, err, "failed to create savepoint for job \"job-id\" within 1 seconds")
+}
+
+func TestMonitorSavepointCreationShouldReturnTheLocationWhenTheSavepointIsCreated(t *testing.T) {
+	mockedMonitorSavepointCreationError = nil
+	mockedMonitorSavepointCreationResponse = flink.MonitorSavepointCreationResponse{
+		Status: flink.SavepointCreationStatus{
+			Id: "COMPLETED",
+		},
+		Operation: &flink.SavepointOperation{
+			Location: "/savepoints/savepoint-1",
+		},
+	}
+
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	location, err := operator.monitorSavepointCreation(context.Background(), "job-id", "request-id", Poller{Timeout: time.Second})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/savepoints/savepoint-1", location)
+}
+
+func TestMonitorSavepointCreationShouldReturnTheFailureCause(t *testing.T) {
+	mockedMonitorSavepointCreationError = nil
+	mockedMonitorSavepointCreationResponse = flink.MonitorSavepointCreationResponse{
+		Status: flink.SavepointCreationStatus{
+			Id: "COMPLETED",
+		},
+		Operation: &flink.SavepointOperation{
+			FailureCause: &flink.FailureCause{Class: "java.util.concurrent.CompletionException"},
+		},
+	}
+
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	_, err := operator.monitorSavepointCreation(context.Background(), "job-id", "request-id", Poller{Timeout: time.Second})
+
+	assert.EqualError(t, err, "savepoint creation for job \"job-id\" failed due to: java.util.concurrent.CompletionException")
+}
+
+/*
+ * UpdateJob
+ */
+func TestUpdateJobShouldReturnAnErrorWhenTheJobNameBaseIsUndefined(t *testing.T) {
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	_, err := operator.Update(context.Background(), UpdateJob{
+		LocalFilename: "testdata/sample.jar",
+	})
+
+	assert.EqualError(t, err, "unspecified argument 'JobNameBase'")
+}
+
+func TestUpdateJobShouldReturnAnErrorWhenTheSavepointDirectoryIsUndefined(t *testing.T) {
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	_, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:   "WordCountStateful",
+		LocalFilename: "testdata/sample.jar",
+	})
+
+	assert.EqualError(t, err, "unspecified argument 'SavepointDir'")
+}
+
+func TestUpdateJobShouldReturnAnErrorWhenRetrievingTheJobsFails(t *testing.T) {
+	mockedRetrieveJobsError = errors.New("failed")
+
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	_, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:   "WordCountStateful",
+		LocalFilename: "testdata/sample.jar",
+		SavepointDir:  "/data/flink",
+	})
+
+	assert.EqualError(t, err, "retrieving jobs failed: failed")
+}
+
+func TestUpdateJobShouldReturnAnErrorWhenTheSavepointCannotBeCreated(t *testing.T) {
+	mockedRetrieveJobsError = nil
+	mockedRetrieveJobsResponse = []flink.Job{
+		flink.Job{
+			ID:     "Job-A",
+			Name:   "WordCountStateful v1.0",
+			Status: "RUNNING",
+		},
+	}
+	mockedCreateSavepointError = errors.New("failed")
+
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	_, err := operator.Update(context.Background(), UpdateJob{
+		// Use the same job name as the mock job above
+		// operator.Update will filter running jobs by name to cancel.
+		JobNameBase:   "WordCountStateful v1.0",
+		LocalFilename: "testdata/sample.jar",
+		SavepointDir:  "/data/flink",
+	})
+
+	assert.EqualError(t, err, "failed to create savepoint for job Job-A due to error: failed")
+}
+
+func TestUpdateJobShouldReturnAnErrorWhenTheJobCannotBeCanceled(t *testing.T) {
+	mockedRetrieveJobsError = nil
+	mockedRetrieveJobsResponse = []flink.Job{
+		{
+			ID:     "Job-A",
+			Name:   "WordCountStateful v1.0",
+			Status: "RUNNING",
+		},
+	}
+	mockedCreateSavepointError = nil
+	mockedCreateSavepointResponse = flink.CreateSavepointResponse{
+		RequestID: "request-id",
+	}
+	mockedMonitorSavepointCreationResponse = flink.MonitorSavepointCreationResponse{
+		Status: flink.SavepointCreationStatus{
+			Id: "COMPLETED",
+		},
+	}
+	mockedTerminateError = errors.New("failed")
+
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	_, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:   "WordCountStateful v1.0",
+		LocalFilename: "testdata/sample.jar",
+		SavepointDir:  "/data/flink",
+	})
+
+	assert.EqualError(t, err, "job \"Job-A\" failed to cancel due to: failed")
+}
+
+func TestUpdateJobShouldReturnAnErrorWhenTheLatestSavepointCannotBeRetrieved(t *testing.T) {
+	filesystem := afero.NewMemMapFs()
+	filesystem.Mkdir("/data/flink/", 0755)
+
+	mockedRetrieveJobsError = nil
+	mockedRetrieveJobsResponse = []flink.Job{
+		flink.Job{
+			ID:     "Job-A",
+			Name:   "WordCountStateful v1.0",
+			Status: "RUNNING",
+		},
+	}
+	mockedCreateSavepointError = nil
+	mockedCreateSavepointResponse = flink.CreateSavepointResponse{
+		RequestID: "request-id",
+	}
+	mockedMonitorSavepointCreationResponse = flink.MonitorSavepointCreationResponse{
+		Status: flink.SavepointCreationStatus{
+			Id: "COMPLETED",
+		},
+	}
+	mockedTerminateError = nil
+	mockedUploadJarResponse = flink.UploadJarResponse{
+		Filename: "/data/flink/sample.jar",
+		Status:   "success",
+	}
+	mockedRunJarError = nil
+
+	operator := RealOperator{
+		Filesystem: filesystem,
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	_, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:   "WordCountStateful v1.0",
+		LocalFilename: "testdata/sample.jar",
+		SavepointDir:  "/data/flink",
+	})
+
+	assert.EqualError(t, err, "retrieving the latest savepoint failed: No savepoints present in directory: /data/flink")
+}
+
+func TestUpdateJobShouldReturnNilWhenTheUpdateSucceeds(t *testing.T) {
+	filesystem := afero.NewMemMapFs()
+	filesystem.Mkdir("/data/flink/", 0755)
+	afero.WriteFile(filesystem, "/data/flink/savepoint-683b3f-59401d30cfc4", []byte("file a"), 644)
+
+	mockedRetrieveJobsError = nil
+	mockedRetrieveJobsResponse = []flink.Job{
+		flink.Job{
+			ID:     "Job-A",
+			Name:   "WordCountStateful v1.0",
+			Status: "RUNNING",
+		},
+	}
+	mockedCreateSavepointError = nil
+	mockedCreateSavepointResponse = flink.CreateSavepointResponse{
+		RequestID: "request-id",
+	}
+	mockedMonitorSavepointCreationResponse = flink.MonitorSavepointCreationResponse{
+		Status: flink.SavepointCreationStatus{
+			Id: "COMPLETED",
+		},
+	}
+	mockedTerminateError = nil
+	mockedUploadJarResponse = flink.UploadJarResponse{
+		Filename: "/data/flink/sample.jar",
+		Status:   "success",
+	}
+	mockedRunJarError = nil
+
+	operator := RealOperator{
+		Filesystem: filesystem,
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	_, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:   "WordCountStateful v1.0",
+		LocalFilename: "testdata/sample.jar",
+		SavepointDir:  "/data/flink",
+	})
+
+	assert.Nil(t, err)
+}
+
+func mockSuccessfulUpdate(filesystem afero.Fs) {
+	filesystem.Mkdir("/data/flink/", 0755)
+	afero.WriteFile(filesystem, "/data/flink/savepoint-683b3f-59401d30cfc4", []byte("file a"), 644)
+
+	mockedRetrieveJobsError = nil
+	mockedRetrieveJobsResponse = []flink.Job{
+		flink.Job{
+			ID:     "Job-A",
+			Name:   "WordCountStateful v1.0",
+			Status: "RUNNING",
+		},
+	}
+	mockedCreateSavepointError = nil
+	mockedCreateSavepointResponse = flink.CreateSavepointResponse{
+		RequestID: "request-id",
+	}
+	mockedMonitorSavepointCreationError = nil
+	mockedMonitorSavepointCreationResponse = flink.MonitorSavepointCreationResponse{
+		Status: flink.SavepointCreationStatus{
+			Id: "COMPLETED",
+		},
+	}
+	mockedTerminateError = nil
+	mockedUploadJarError = nil
+	mockedUploadJarResponse = flink.UploadJarResponse{
+		Filename: "/data/flink/sample.jar",
+		Status:   "success",
+	}
+	mockedRunJarError = nil
+}
+
+// A CancelingFlinkRestClient cancels the context when
+// the operation reaches the API call of the step
+type CancelingFlinkRestClient struct {
+	TestFlinkRestClient
+	cancelOn string
+	cancel   context.CancelFunc
+	calls    []string
+}
+
+func (c *CancelingFlinkRestClient) call(name string) {
+	c.calls = append(c.calls, name)
+	if name == c.cancelOn {
+		c.cancel()
+	}
+}
+
+func (c *CancelingFlinkRestClient) MonitorSavepointCreation(ctx context.Context, jobID string, requestID string) (flink.MonitorSavepointCreationResponse, error) {
+	c.call("MonitorSavepointCreation")
+	return c.TestFlinkRestClient.MonitorSavepointCreation(ctx, jobID, requestID)
+}
+
+func (c *CancelingFlinkRestClient) Terminate(ctx context.Context, jobID string, mode string) error {
+	c.call("Terminate")
+	return c.TestFlinkRestClient.Terminate(ctx, jobID, mode)
+}
+
+func (c *CancelingFlinkRestClient) RunJar(ctx context.Context, jarID string, entryClass string, jarArgs []string, parallelism int, savepointPath string, allowNonRestoredState bool, options flink.RunJarOptions) (flink.RunJarResponse, error) {
+	c.call("RunJar")
+	return c.TestFlinkRestClient.RunJar(ctx, jarID, entryClass, jarArgs, parallelism, savepointPath, allowNonRestoredState, options)
+}
+
+func TestUpdateJobShouldStopBeforeCreatingASavepointWhenCanceled(t *testing.T) {
+	filesystem := afero.NewMemMapFs()
+	mockSuccessfulUpdate(filesystem)
+
+	operator := RealOperator{
+		Filesystem:   filesystem,
+		FlinkRestAPI: TestFlinkRestClient{},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := operator.Update(ctx, UpdateJob{
+		JobNameBase:   "WordCountStateful v1.0",
+		LocalFilename: "testdata/sample.jar",
+		SavepointDir:  "/data/flink",
+	})
+
+	assert.EqualError(t, err, "update stopped before creating a savepoint for job \"Job-A\", the job is still running")
+	assert.Equal(t, flink.ErrCanceled, flink.Category(err))
+}
+
+func TestUpdateJobShouldStopAfterTheSavepointWhenCanceled(t *testing.T) {
+	filesystem := afero.NewMemMapFs()
+	mockSuccessfulUpdate(filesystem)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	api := &CancelingFlinkRestClient{cancelOn: "MonitorSavepointCreation", cancel: cancel}
+	operator := RealOperator{
+		Filesystem:   filesystem,
+		FlinkRestAPI: api,
+	}
+
+	_, err := operator.Update(ctx, UpdateJob{
+		JobNameBase:   "WordCountStateful v1.0",
+		LocalFilename: "testdata/sample.jar",
+		SavepointDir:  "/data/flink",
+	})
+
+	assert.EqualError(t, err, "update stopped after creating a savepoint for job \"Job-A\" in /data/flink, the job is still running")
+	assert.Equal(t, flink.ErrCanceled, flink.Category(err))
+	assert.Equal(t, []string{"MonitorSavepointCreation"}, api.calls)
+}
+
+func TestUpdateJobShouldRunTheNewJobWhenCanceledAfterTheJobWasCancelled(t *testing.T) {
+	filesystem := afero.NewMemMapFs()
+	mockSuccessfulUpdate(filesystem)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	api := &CancelingFlinkRestClient{cancelOn: "Terminate", cancel: cancel}
+	operator := RealOperator{
+		Filesystem:   filesystem,
+		FlinkRestAPI: api,
+	}
+
+	_, err := operator.Update(ctx, UpdateJob{
+		JobNameBase:   "WordCountStateful v1.0",
+		LocalFilename: "testdata/sample.jar",
+		SavepointDir:  "/data/flink",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"MonitorSavepointCreation", "Terminate", "RunJar"}, api.calls)
+}
+
+func TestUpdateJobShouldReturnAnErrorWhenNoRunningJobsAreFound(t *testing.T) {
+	mockedRetrieveJobsError = nil
+	mockedRetrieveJobsResponse = []flink.Job{}
+
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	_, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:   "WordCountStateful",
+		LocalFilename: "testdata/sample.jar",
+		SavepointDir:  "/data/flink",
+	})
+
+	assert.EqualError(t, err, "no instance running for job name base \"WordCountStateful\". Aborting update")
+}
+
+func TestUpdateJobShouldReturnErrorWhenNoRunningJobsAreFoundAndFallbackToDeployIsFalse(t *testing.T) {
+	mockedRetrieveJobsError = nil
+	mockedRetrieveJobsResponse = []flink.Job{}
+
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	_, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:      "WordCountStateful",
+		LocalFilename:    "testdata/sample.jar",
+		SavepointDir:     "/data/flink",
+		FallbackToDeploy: false,
+	})
+
+	assert.EqualError(t, err, "no instance running for job name base \"WordCountStateful\". Aborting update")
+}
+
+func TestUpdateJobShouldFallbackToDeployWhenNoRunningJobsAreFound(t *testing.T) {
+	mockedRetrieveJobsError = nil
+	mockedRetrieveJobsResponse = []flink.Job{}
+
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	_, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:      "WordCountStateful",
+		LocalFilename:    "testdata/sample.jar",
+		SavepointDir:     "/data/flink",
+		FallbackToDeploy: true,
+	})
+
+	assert.Nil(t, err)
+}
+
+func TestUpdateJobShouldReturnAnErrorWhenMultipleRunningJobsAreFound(t *testing.T) {
+	mockedRetrieveJobsError = nil
+	mockedRetrieveJobsResponse = []flink.Job{
+		flink.Job{
+			ID:     "Job-A",
+			Name:   "WordCountStateful v1.0",
+			Status: "RUNNING",
+		},
+		flink.Job{
+			ID:     "Job-B",
+			Name:   "WordCountStateful v1.0",
+			Status: "RUNNING",
+		},
+	}
+
+	operator := RealOperator{
+		FlinkRestAPI: TestFlinkRestClient{
+			BaseURL: "http://localhost",
+			Client:  &http.Client{},
+		},
+	}
+
+	// flink-deployer wont want to update (stop/start) an undesired job
+	// when there are two running jobs with same name. So it must abort the update
+	_, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:   "WordCountStateful",
+		LocalFilename: "testdata/sample.jar",
+		SavepointDir:  "/data/flink",
+	})
+
+	assert.EqualError(t, err, "job name with base \"WordCountStateful\" has 2 instances running. Aborting update")
+}
+
+func TestUpdateJobShouldReturnTheResultAndNotifyTheObserver(t *testing.T) {
+	filesystem := afero.NewMemMapFs()
+	mockSuccessfulUpdate(filesystem)
+	mockedMonitorSavepointCreationResponse.Operation = &flink.SavepointOperation{Location: "/data/flink/savepoint-683b3f-59401d30cfc4"}
+	mockedRunJarResponse = flink.RunJarResponse{JobID: "Job-B"}
+	defer func() { mockedRunJarResponse = flink.RunJarResponse{} }()
+
+	events := []Event{}
+	operator := RealOperator{
+		Filesystem:   filesystem,
+		FlinkRestAPI: TestFlinkRestClient{},
+		Observer: ObserverFunc(func(event Event) {
+			assert.False(t, event.Time.IsZero())
+			event.Time = time.Time{}
+			events = append(events, event)
+		}),
+	}
+
+	result, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:   "WordCountStateful v1.0",
+		LocalFilename: "testdata/sample.jar",
+		SavepointDir:  "/data/flink",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, UpdateResult{
+		DeployResult: DeployResult{
+			JarID:         "sample.jar",
+			JobID:         "Job-B",
+			SavepointPath: "/data/flink/savepoint-683b3f-59401d30cfc4",
+		},
+		PreviousJobID: "Job-A",
+	}, result)
+	assert.Equal(t, []Event{
+		Event{Type: EventJarUploaded, JarID: "sample.jar"},
+		Event{Type: EventSavepointStarted, JobID: "Job-A"},
+		Event{Type: EventSavepointCompleted, JobID: "Job-A", SavepointPath: "/data/flink/savepoint-683b3f-59401d30cfc4"},
+		Event{Type: EventJobCancelled, JobID: "Job-A"},
+		Event{Type: EventJobRunning, JobID: "Job-B", JarID: "sample.jar", SavepointPath: "/data/flink/savepoint-683b3f-59401d30cfc4"},
+	}, events)
+}
+
+func TestUpdateJobShouldReplaceTheJobOnAFakeCluster(t *testing.T) {
+	filesystem := afero.NewMemMapFs()
+	cluster := flinktest.NewCluster()
+	cluster.Filesystem = filesystem
+	previousJobID := cluster.StartJob("WordCount-1", 2)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	filename, cleanup := createTestJarFile(t, "WordCount-2.jar")
+	defer cleanup()
+
+	operator := newFakeClusterOperator(server, filesystem)
+	result, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:   "WordCount",
+		LocalFilename: filename,
+		SavepointDir:  "/data/flink/savepoints",
+		Parallelism:   2,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, previousJobID, result.PreviousJobID)
+	previousJob, _ := cluster.Job(previousJobID)
+	assert.Equal(t, flinktest.StatusCanceled, previousJob.Status)
+	job, _ := cluster.Job(result.JobID)
+	assert.Equal(t, flinktest.StatusRunning, job.Status)
+	assert.Equal(t, "WordCount-2", job.Name)
+	assert.Equal(t, cluster.Savepoints(), []string{job.SavepointPath})
+}
+
+func TestUpdateJobShouldCheckTheOptionsWithTheVersionOfTheClusterOnceBeforeTheSavepoint(t *testing.T) {
+	filesystem := afero.NewMemMapFs()
+	cluster := flinktest.NewCluster()
+	cluster.Filesystem = filesystem
+	cluster.StartJob("WordCount-1", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	filename, cleanup := createTestJarFile(t, "WordCount-2.jar")
+	defer cleanup()
+
+	operator := newFakeClusterOperator(server, filesystem)
+	result, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:   "WordCount",
+		LocalFilename: filename,
+		SavepointDir:  "/data/flink/savepoints",
+		JobID:         "fd72014d4c864993a2e5a9287b4a9c5d",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "fd72014d4c864993a2e5a9287b4a9c5d", result.JobID)
+	versionRequests := []string{}
+	for _, request := range cluster.Requests() {
+		if request == "GET /config" || request == "GET /overview" {
+			versionRequests = append(versionRequests, request)
+		}
+		if strings.HasPrefix(request, "POST /jobs/") {
+			break
+		}
+	}
+	assert.Equal(t, []string{"GET /config"}, versionRequests)
+}
+
+func TestUpdateJobShouldKeepTheJobRunningWhenTheSavepointCannotBeTriggered(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	previousJobID := cluster.StartJob("WordCount-1", 1)
+	cluster.InjectFailure(flinktest.Failure{Method: "POST", Path: "/jobs/*/savepoints", StatusCode: http.StatusConflict})
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	filename, cleanup := createTestJarFile(t, "WordCount-2.jar")
+	defer cleanup()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	_, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:   "WordCount",
+		LocalFilename: filename,
+		SavepointDir:  "/data/flink/savepoints",
+	})
+
+	assert.Equal(t, flink.ErrConflict, flink.Category(err))
+	previousJob, _ := cluster.Job(previousJobID)
+	assert.Equal(t, flinktest.StatusRunning, previousJob.Status)
+	assert.Len(t, cluster.Jars(), 0)
+}
+
+func TestUpdateJobShouldKeepTheJobRunningWhenTheClusterDoesNotSupportTheOptions(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	previousJobID := cluster.StartJob("WordCount-1", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	_, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:        "WordCount",
+		LocalFilename:      "testdata/sample.jar",
+		SavepointDir:       "/data/flink/savepoints",
+		FlinkConfiguration: map[string]string{"pipeline.name": "word-count"},
+	})
+
+	assert.EqualError(t, err, "the Flink configuration requires Flink 1.17 or newer, but the cluster runs Flink 1.9.0")
+	previousJob, _ := cluster.Job(previousJobID)
+	assert.Equal(t, flinktest.StatusRunning, previousJob.Status)
+	assert.Len(t, cluster.Savepoints(), 0)
+}
+
+func TestUpdateJobShouldKeepTheJobRunningWhenTheChecksumDoesNotMatch(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	previousJobID := cluster.StartJob("WordCount-1", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	filename, cleanup := createTestJarFile(t, "WordCount-2.jar")
+	defer cleanup()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	_, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:   "WordCount",
+		LocalFilename: filename,
+		SHA256:        strings.Repeat("0", 64),
+		SavepointDir:  "/data/flink/savepoints",
+	})
+
+	assert.Contains(t, err.Error(), "the SHA-256 checksum of "+filename)
+	previousJob, _ := cluster.Job(previousJobID)
+	assert.Equal(t, flinktest.StatusRunning, previousJob.Status)
+	assert.Len(t, cluster.Savepoints(), 0)
+	assert.Len(t, cluster.Jars(), 0)
+}
+
+func TestUpdateJobShouldKeepTheJobRunningWhenTheSavepointFails(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	cluster.FailSavepoints("java.io.IOException: No space left on device")
+	previousJobID := cluster.StartJob("WordCount-1", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	filename, cleanup := createTestJarFile(t, "WordCount-2.jar")
+	defer cleanup()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	_, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:   "WordCount",
+		LocalFilename: filename,
+		SavepointDir:  "/data/flink/savepoints",
+	})
+
+	assert.Contains(t, err.Error(), "failed due to: java.util.concurrent.CompletionException")
+	previousJob, _ := cluster.Job(previousJobID)
+	assert.Equal(t, flinktest.StatusRunning, previousJob.Status)
+}
+
+func TestUpdateJobShouldKeepTheJobRunningWhenTheEntryClassDoesNotExist(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	previousJobID := cluster.StartJob("WordCount-1", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	filename, cleanup := createTestJarFile(t, "WordCount-2.jar")
+	defer cleanup()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	_, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:   "WordCount",
+		LocalFilename: filename,
+		EntryClass:    "com.example.Wordcount",
+		SavepointDir:  "/data/flink/savepoints",
+	})
+
+	assert.EqualError(t, err, "the entry class com.example.Wordcount doesn't exist in the JAR file")
+	assert.Len(t, cluster.Requests(), 0)
+	previousJob, _ := cluster.Job(previousJobID)
+	assert.Equal(t, flinktest.StatusRunning, previousJob.Status)
+}
+
+func TestUpdateJobShouldKeepTheJobRunningWhenTheJarFileIsCorrupt(t *testing.T) {
+	cluster := flinktest.NewCluster()
+	previousJobID := cluster.StartJob("WordCount-1", 1)
+	server := httptest.NewServer(cluster)
+	defer server.Close()
+	filename, cleanup := writeTestJarFile(t, "WordCount-2.jar", []byte("corrupt"))
+	defer cleanup()
+
+	operator := newFakeClusterOperator(server, afero.NewMemMapFs())
+	_, err := operator.Update(context.Background(), UpdateJob{
+		JobNameBase:   "WordCount",
+		LocalFilename: filename,
+		SavepointDir:  "/data/flink/savepoints",
+	})
+
+	assert.EqualError(t, err, filename+" isn't a JAR file: zip: not a valid zip file")
+	assert.Len(t, cluster.Requests(), 0)
+	previousJob, _ := cluster.Job(previousJobID)
+	assert.Equal(t, flinktest.StatusRunning, previousJob.Status)
+}
@@ -0,0 +1,80 @@
+package secret
+
+import (
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces the secrets in the output
+const Redacted = "[redacted]"
+
+// A Redactor replaces the resolved secrets in the output of the deployer
+type Redactor struct {
+	mutex   sync.RWMutex
+	secrets []string
+}
+
+// Add adds the secret, and its quoted form as it appears in error
+// messages and JSON request bodies, to the secrets to redact
+func (r *Redactor) Add(secret string) {
+	if len(secret) == 0 {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	quoted := strconv.Quote(secret)
+	for _, value := range []string{secret, quoted[1 : len(quoted)-1]} {
+		if !r.contains(value) {
+			r.secrets = append(r.secrets, value)
+		}
+	}
+	// Longer secrets first, so secrets containing others are redacted completely
+	sort.Slice(r.secrets, func(i, j int) bool {
+		return len(r.secrets[i]) > len(r.secrets[j])
+	})
+}
+
+func (r *Redactor) contains(secret string) bool {
+	for _, s := range r.secrets {
+		if s == secret {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact replaces the secrets in the text
+func (r *Redactor) Redact(text string) string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, secret := range r.secrets {
+		text = strings.Replace(text, secret, Redacted, -1)
+	}
+	return text
+}
+
+// Writer returns a writer which redacts the secrets before writing to
+// the writer. Secrets are only redacted within a single write, as the
+// log package and the CLI write complete messages.
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	return redactingWriter{redactor: r, writer: w}
+}
+
+type redactingWriter struct {
+	redactor *Redactor
+	writer   io.Writer
+}
+
+func (w redactingWriter) Write(p []byte) (int, error) {
+	_, err := io.WriteString(w.writer, w.redactor.Redact(string(p)))
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,44 @@
+package secret
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * Redact
+ */
+func TestRedactShouldReplaceTheSecretsAndTheirQuotedForm(t *testing.T) {
+	redactor := &Redactor{}
+	redactor.Add(`pa"ss`)
+	redactor.Add("")
+
+	redacted := redactor.Redact(`the argument "pa\"ss" contains pa"ss`)
+
+	assert.Equal(t, `the argument "[redacted]" contains [redacted]`, redacted)
+}
+
+func TestRedactShouldPreferLongerSecrets(t *testing.T) {
+	redactor := &Redactor{}
+	redactor.Add("secret")
+	redactor.Add("secret-password")
+
+	assert.Equal(t, "[redacted] and [redacted]", redactor.Redact("secret-password and secret"))
+}
+
+/*
+ * Writer
+ */
+func TestWriterShouldRedactTheLogOutput(t *testing.T) {
+	redactor := &Redactor{}
+	redactor.Add("s3cr3t")
+	output := bytes.Buffer{}
+	logger := log.New(redactor.Writer(&output), "", 0)
+
+	logger.Printf("running with --password %v", "s3cr3t")
+
+	assert.Equal(t, "running with --password [redacted]\n", output.String())
+}
@@ -0,0 +1,109 @@
+// Package secret resolves the secrets referenced by program arguments, from
+// Vault or from files, and redacts them from the output of the deployer.
+package secret
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// The prefixes of the secret references
+const (
+	VaultPrefix = "vault:"
+	FilePrefix  = "file:"
+)
+
+// referencePattern matches the secret references in program arguments, like
+// ${vault:secret/data/kafka#password}, and the escaped $${, which is replaced
+// by a literal ${
+var referencePattern = regexp.MustCompile(`\$\$\{|\$\{((?:` + VaultPrefix + `|` + FilePrefix + `)[^}]*)\}`)
+
+// IsReference returns whether the value references a secret, either
+// a field of a Vault secret, e.g. vault:secret/data/kafka#password,
+// or a file, e.g. file:/run/secrets/kafka-password
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, VaultPrefix) || strings.HasPrefix(value, FilePrefix)
+}
+
+// A Resolver resolves secret references and adds the
+// resolved secrets to the Redactor, when there is one
+type Resolver struct {
+	Vault    Vault
+	Redactor *Redactor
+}
+
+// Resolve returns the secret the reference points to
+func (r Resolver) Resolve(ctx context.Context, reference string) (string, error) {
+	var value string
+	var err error
+	switch {
+	case strings.HasPrefix(reference, VaultPrefix):
+		value, err = r.resolveVault(ctx, strings.TrimPrefix(reference, VaultPrefix))
+	case strings.HasPrefix(reference, FilePrefix):
+		value, err = resolveFile(strings.TrimPrefix(reference, FilePrefix))
+	default:
+		return "", fmt.Errorf("%v isn't a secret reference, expected vault:<path>#<field> or file:<path>", reference)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if r.Redactor != nil {
+		r.Redactor.Add(value)
+	}
+	return value, nil
+}
+
+// Substitute replaces the secret references in the program arguments, like
+// ${vault:secret/data/kafka#password} or ${file:/run/secrets/kafka-password},
+// by the secrets and the escaped $${ by a literal ${
+func (r Resolver) Substitute(ctx context.Context, args []string) ([]string, error) {
+	if len(args) == 0 {
+		return args, nil
+	}
+
+	substituted := make([]string, len(args))
+	for i, arg := range args {
+		var err error
+		substituted[i] = referencePattern.ReplaceAllStringFunc(arg, func(match string) string {
+			if match == "$${" {
+				return "${"
+			}
+			value, resolveErr := r.Resolve(ctx, match[2:len(match)-1])
+			if resolveErr != nil && err == nil {
+				err = flink.Errorf("unable to resolve the secret of the program argument %q: %v", arg, resolveErr)
+			}
+			return value
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return substituted, nil
+}
+
+func (r Resolver) resolveVault(ctx context.Context, reference string) (string, error) {
+	parts := strings.SplitN(reference, "#", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return "", fmt.Errorf("the Vault secret reference %v must specify the path and the field, e.g. vault:secret/data/kafka#password", VaultPrefix+reference)
+	}
+	return r.Vault.Read(ctx, parts[0], parts[1])
+}
+
+// resolveFile reads the secret from the file, without the trailing
+// line break editors and `kubectl create secret --from-file` keep
+func resolveFile(path string) (string, error) {
+	if len(path) == 0 {
+		return "", fmt.Errorf("the file secret reference %v must specify the path, e.g. file:/run/secrets/kafka-password", FilePrefix)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the secret file: %v", err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
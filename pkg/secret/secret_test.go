@@ -0,0 +1,81 @@
+package secret
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * IsReference
+ */
+func TestIsReferenceShouldRecognizeVaultAndFileReferences(t *testing.T) {
+	assert.True(t, IsReference("vault:secret/data/kafka#password"))
+	assert.True(t, IsReference("file:/run/secrets/kafka-password"))
+	assert.False(t, IsReference("BROKERS"))
+}
+
+/*
+ * Resolve
+ */
+func TestResolveShouldReadAVaultSecretAndRedactIt(t *testing.T) {
+	server := newTestVault(t)
+	defer server.Close()
+	redactor := &Redactor{}
+	resolver := Resolver{Vault: Vault{Address: server.URL, Token: testVaultToken}, Redactor: redactor}
+
+	value, err := resolver.Resolve(context.Background(), "vault:secret/data/kafka#password")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "s3cr3t", value)
+	assert.Equal(t, "password=[redacted]", redactor.Redact("password=s3cr3t"))
+}
+
+func TestResolveShouldReadAFileSecretWithoutTheTrailingLineBreak(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secret")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "kafka-password")
+	ioutil.WriteFile(path, []byte("s3cr3t\n"), 0600)
+
+	value, err := Resolver{}.Resolve(context.Background(), "file:"+path)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "s3cr3t", value)
+}
+
+func TestResolveShouldRequireTheFieldOfAVaultSecret(t *testing.T) {
+	_, err := Resolver{}.Resolve(context.Background(), "vault:secret/data/kafka")
+
+	assert.EqualError(t, err, "the Vault secret reference vault:secret/data/kafka must specify the path and the field, e.g. vault:secret/data/kafka#password")
+}
+
+func TestResolveShouldReturnAnErrorForAMissingFile(t *testing.T) {
+	_, err := Resolver{}.Resolve(context.Background(), "file:/run/secrets/missing")
+
+	assert.EqualError(t, err, "unable to read the secret file: open /run/secrets/missing: no such file or directory")
+}
+
+/*
+ * Substitute
+ */
+func TestSubstituteShouldReplaceTheSecretReferencesAndKeepOtherVariables(t *testing.T) {
+	server := newTestVault(t)
+	defer server.Close()
+	resolver := Resolver{Vault: Vault{Address: server.URL, Token: testVaultToken}}
+
+	args, err := resolver.Substitute(context.Background(), []string{"--password", "${vault:secret/data/kafka#password}", "--topic", "${TOPIC}-$${raw}"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"--password", "s3cr3t", "--topic", "${TOPIC}-${raw}"}, args)
+}
+
+func TestSubstituteShouldReturnAnErrorForAnUnresolvableSecret(t *testing.T) {
+	_, err := Resolver{}.Substitute(context.Background(), []string{"--password", "${file:/run/secrets/missing}"})
+
+	assert.EqualError(t, err, `unable to resolve the secret of the program argument "${file:/run/secrets/missing}": unable to read the secret file: open /run/secrets/missing: no such file or directory`)
+}
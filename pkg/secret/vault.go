@@ -0,0 +1,105 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+)
+
+// DefaultTimeout is the timeout of the requests to Vault
+// when the Vault has no HTTP client of its own
+const DefaultTimeout = 30 * time.Second
+
+// Vault reads secrets from the KV secrets engine of HashiCorp Vault,
+// version 1 or 2, authenticated with a token
+type Vault struct {
+	Address   string
+	Token     string
+	Namespace string
+	Client    *http.Client
+}
+
+type vaultResponse struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+func (v Vault) client() *http.Client {
+	if v.Client == nil {
+		return &http.Client{Timeout: DefaultTimeout}
+	}
+	return v.Client
+}
+
+// Read returns the field of the secret at the path, e.g. the field
+// password of secret/data/kafka. The path includes the data segment
+// of version 2 of the KV secrets engine.
+func (v Vault) Read(ctx context.Context, path string, field string) (string, error) {
+	if len(v.Address) == 0 {
+		return "", errors.New("`VAULT_ADDR` environment variable not found, which Vault secret references require")
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%v/v1/%v", strings.TrimRight(v.Address, "/"), strings.TrimLeft(path, "/")), nil)
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("X-Vault-Token", v.Token)
+	if len(v.Namespace) > 0 {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	res, err := v.client().Do(req)
+	if err != nil {
+		return "", flink.CategorizedErrorf(flink.ErrClusterUnreachable, "reading the Vault secret %v failed: %v", path, err)
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	// The bodies of error responses don't contain secrets, only error messages
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", flink.CategorizedErrorf(flink.ErrNotFound, "the Vault secret %v doesn't exist", path)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "", flink.CategorizedErrorf(flink.ErrUnauthorized, "reading the Vault secret %v is denied: %v", path, strings.TrimSpace(string(body)))
+	default:
+		return "", fmt.Errorf("reading the Vault secret %v failed with status %v: %v", path, res.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	response := vaultResponse{}
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse the Vault secret %v", path)
+	}
+
+	data := response.Data
+	// Version 2 of the KV secrets engine nests the fields next to the metadata
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+
+	value, ok := data[field]
+	if !ok {
+		return "", flink.CategorizedErrorf(flink.ErrNotFound, "the Vault secret %v has no field %v", path, field)
+	}
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case float64, bool:
+		return fmt.Sprint(value), nil
+	}
+	return "", fmt.Errorf("the field %v of the Vault secret %v isn't a string, number or boolean", field, path)
+}
@@ -0,0 +1,105 @@
+package secret
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ing-bank/flink-deployer/pkg/flink"
+	"github.com/stretchr/testify/assert"
+)
+
+const testVaultToken = "root"
+
+// newTestVault starts a stand-in for a Vault dev server, which mounts
+// version 2 of the KV secrets engine at secret and version 1 at kv
+func newTestVault(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != testVaultToken {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/data/kafka":
+			w.Write([]byte(`{"request_id":"1","lease_duration":0,"data":{"data":{"username":"flink","password":"s3cr3t","port":9093},"metadata":{"version":2}}}`))
+		case "/v1/kv/postgres":
+			w.Write([]byte(`{"request_id":"2","lease_duration":2764800,"data":{"password":"pg-pass"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+}
+
+/*
+ * Read
+ */
+func TestReadShouldReturnTheFieldOfAKVVersion2Secret(t *testing.T) {
+	server := newTestVault(t)
+	defer server.Close()
+	vault := Vault{Address: server.URL, Token: testVaultToken}
+
+	password, err := vault.Read(context.Background(), "secret/data/kafka", "password")
+	port, _ := vault.Read(context.Background(), "secret/data/kafka", "port")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "s3cr3t", password)
+	assert.Equal(t, "9093", port)
+}
+
+func TestReadShouldReturnTheFieldOfAKVVersion1Secret(t *testing.T) {
+	server := newTestVault(t)
+	defer server.Close()
+	vault := Vault{Address: server.URL + "/", Token: testVaultToken}
+
+	password, err := vault.Read(context.Background(), "kv/postgres", "password")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "pg-pass", password)
+}
+
+func TestReadShouldReturnAnErrorForAMissingField(t *testing.T) {
+	server := newTestVault(t)
+	defer server.Close()
+	vault := Vault{Address: server.URL, Token: testVaultToken}
+
+	_, err := vault.Read(context.Background(), "secret/data/kafka", "sasl")
+
+	assert.EqualError(t, err, "the Vault secret secret/data/kafka has no field sasl")
+	assert.Equal(t, flink.ErrNotFound, flink.Category(err))
+}
+
+func TestReadShouldReturnAnErrorForAMissingSecret(t *testing.T) {
+	server := newTestVault(t)
+	defer server.Close()
+	vault := Vault{Address: server.URL, Token: testVaultToken}
+
+	_, err := vault.Read(context.Background(), "secret/data/missing", "password")
+
+	assert.EqualError(t, err, "the Vault secret secret/data/missing doesn't exist")
+}
+
+func TestReadShouldReturnAnUnauthorizedErrorForAnInvalidToken(t *testing.T) {
+	server := newTestVault(t)
+	defer server.Close()
+	vault := Vault{Address: server.URL, Token: "expired"}
+
+	_, err := vault.Read(context.Background(), "secret/data/kafka", "password")
+
+	assert.True(t, strings.HasPrefix(err.Error(), "reading the Vault secret secret/data/kafka is denied"), err.Error())
+	assert.Equal(t, flink.ErrUnauthorized, flink.Category(err))
+}
+
+func TestReadShouldReturnAnErrorWithoutAddress(t *testing.T) {
+	_, err := Vault{}.Read(context.Background(), "secret/data/kafka", "password")
+
+	assert.EqualError(t, err, "`VAULT_ADDR` environment variable not found, which Vault secret references require")
+}
+
+func TestVaultShouldTimeOutWithoutAClientOfItsOwn(t *testing.T) {
+	assert.Equal(t, DefaultTimeout, Vault{}.client().Timeout)
+}